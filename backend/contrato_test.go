@@ -0,0 +1,573 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/handler"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/service"
+	"smart-retention/internal/sugestao"
+	"smart-retention/internal/ws"
+	"smart-retention/openapi"
+)
+
+const (
+	clienteID = "4b1c7a52-0f5e-4d1a-9a43-2f0d3c8e1a01"
+	itemID    = "9d2e6f10-3b7a-4c55-8e21-6a4f0b9c2d02"
+	compraID  = "c0a8e3f4-5d6b-4e7f-8a9b-0c1d2e3f4a03"
+	contatoID = "e4f5a6b7-c8d9-4e0f-9a1b-2c3d4e5f6a07"
+)
+
+// Repositórios em memória com um cliente, uma compra e um registro fixo de
+// cada cadastro do cliente; os métodos não usados pelas rotas testadas
+// ficam com a interface embutida, nil.
+type (
+	clientesMemoria struct {
+		repository.ClienteRepository
+		clientes []model.Cliente
+	}
+
+	comprasMemoria struct {
+		repository.CompraRepository
+		compras []model.Compra
+	}
+
+	contatosFixos struct{ repository.ContatoRepository }
+
+	interacoesFixas struct{ repository.InteracaoRepository }
+
+	tarefasMemoria struct {
+		repository.TarefaRepository
+		tarefas []model.Tarefa
+	}
+
+	pedidosFixos struct {
+		repository.PedidoRecorrenteRepository
+		pedido model.PedidoRecorrente
+	}
+
+	fechamentosFixos struct {
+		repository.FechamentoRepository
+	}
+
+	efetividadeFixa struct{ repository.AlertaRepository }
+
+	regrasTarefaVazias struct {
+		repository.RegraTarefaRepository
+	}
+
+	feriadosVazios struct{ repository.FeriadoRepository }
+
+	regrasVazias struct{ repository.RegraRepository }
+
+	dashboardFixo struct{ repository.DashboardRepository }
+
+	geradorFixo struct{ alertas []alerta.Alerta }
+
+	sugestoesFixas struct{}
+)
+
+func (r *clientesMemoria) Listar(context.Context) ([]model.Cliente, error) {
+	return r.clientes, nil
+}
+
+func (r *clientesMemoria) BuscarPorID(_ context.Context, id string) (*model.Cliente, error) {
+	for i := range r.clientes {
+		if r.clientes[i].ID == id {
+			return &r.clientes[i], nil
+		}
+	}
+	return nil, repository.ErrClienteNaoEncontrado
+}
+
+func (r *clientesMemoria) Criar(_ context.Context, cliente *model.Cliente) error {
+	cliente.ID = "7e3f1a2b-8c9d-4e0f-a1b2-c3d4e5f6a704"
+	for i := range cliente.Itens {
+		if cliente.Itens[i].ID == "" {
+			cliente.Itens[i].ID = "5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c05"
+		}
+	}
+	r.clientes = append(r.clientes, *cliente)
+	return nil
+}
+
+func (r *comprasMemoria) Criar(_ context.Context, compra *model.Compra) error {
+	compra.ID = "1f2e3d4c-5b6a-4978-8695-a4b3c2d1e006"
+	r.compras = append(r.compras, *compra)
+	return nil
+}
+
+func (r *comprasMemoria) BuscarPorID(_ context.Context, id string) (*model.Compra, error) {
+	for i := range r.compras {
+		if r.compras[i].ID == id {
+			return &r.compras[i], nil
+		}
+	}
+	return nil, repository.ErrCompraNaoEncontrada
+}
+
+func (r *comprasMemoria) Listar(context.Context) ([]model.Compra, error) {
+	return r.compras, nil
+}
+
+func (r *comprasMemoria) ListarPorCliente(_ context.Context, clienteID string) ([]model.Compra, error) {
+	var compras []model.Compra
+	for _, c := range r.compras {
+		if c.ClienteID == clienteID {
+			compras = append(compras, c)
+		}
+	}
+	return compras, nil
+}
+
+func (contatosFixos) ListarPorCliente(_ context.Context, clienteID string) ([]model.Contato, error) {
+	return []model.Contato{{
+		ID:        contatoID,
+		ClienteID: clienteID,
+		Nome:      "Marta",
+		Cargo:     "Compradora",
+		Telefone:  "11 98888-0000",
+		Email:     "marta@mercadocentral.com.br",
+		Principal: true,
+	}}, nil
+}
+
+func (interacoesFixas) ListarPorCliente(_ context.Context, clienteID string) ([]model.Interacao, error) {
+	contato := contatoID
+	retorno := time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)
+	return []model.Interacao{{
+		ID:        "8c9d0e1f-2a3b-4c4d-9e5f-6a7b8c9d0e08",
+		ClienteID: clienteID,
+		ContatoID: &contato,
+		Contato:   &model.Contato{ID: contatoID, ClienteID: clienteID, Nome: "Marta", Principal: true},
+		Tipo:      "ligacao",
+		Descricao: "Pediu a tabela nova",
+		Autor:     "Ana",
+		Data:      time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC),
+		RetornoEm: &retorno,
+		CriadaEm:  time.Date(2026, 10, 15, 14, 35, 0, 0, time.UTC),
+	}}, nil
+}
+
+func (interacoesFixas) AlertasPausadosAte(context.Context, string, time.Time) (*time.Time, error) {
+	return nil, nil
+}
+
+func (r *tarefasMemoria) Listar(context.Context, repository.FiltroTarefas) ([]model.Tarefa, error) {
+	return r.tarefas, nil
+}
+
+func (r *tarefasMemoria) AlertaEmAberto(context.Context, string, string, string) (*model.AlertaHistorico, error) {
+	return nil, nil
+}
+
+func (r *tarefasMemoria) Criar(_ context.Context, tarefa *model.Tarefa) error {
+	tarefa.ID = "3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f09"
+	r.tarefas = append(r.tarefas, *tarefa)
+	return nil
+}
+
+func (r *tarefasMemoria) BuscarPorID(_ context.Context, id string) (*model.Tarefa, error) {
+	for i := range r.tarefas {
+		if r.tarefas[i].ID == id {
+			return &r.tarefas[i], nil
+		}
+	}
+	return nil, repository.ErrTarefaNaoEncontrada
+}
+
+func (r pedidosFixos) BuscarPorCliente(_ context.Context, clienteID string) (*model.PedidoRecorrente, error) {
+	if clienteID != r.pedido.ClienteID {
+		return nil, nil
+	}
+	return &r.pedido, nil
+}
+
+func (fechamentosFixos) ListarPorCliente(_ context.Context, clienteID string) ([]model.Fechamento, error) {
+	return []model.Fechamento{{
+		ID:        "6f7a8b9c-0d1e-4f2a-9b3c-4d5e6f7a8b0a",
+		ClienteID: clienteID,
+		Inicio:    time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC),
+		Fim:       time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC),
+		Motivo:    "Férias coletivas",
+		CriadoEm:  time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+func (efetividadeFixa) Efetividade(_ context.Context, grupo repository.GrupoEfetividade, _, _ time.Time) ([]repository.EfetividadeAlertas, error) {
+	mediana := 4.5
+	nome := alerta.TipoItemFaltando
+	if grupo == repository.PorRepresentante {
+		nome = "Ana"
+	}
+	return []repository.EfetividadeAlertas{
+		{Grupo: nome, Total: 4, Recuperados: 2, MedianaDiasRecuperacao: &mediana, ReceitaRecuperada: 119.6},
+	}, nil
+}
+
+func (regrasTarefaVazias) Listar(context.Context) ([]model.RegraTarefa, error) {
+	return nil, nil
+}
+
+func (feriadosVazios) Listar(context.Context, time.Time, time.Time) ([]model.Feriado, error) {
+	return nil, nil
+}
+
+func (regrasVazias) Listar(context.Context) ([]model.RegraAlerta, error) {
+	return nil, nil
+}
+
+func (dashboardFixo) Resumo(context.Context) (*repository.Dashboard, error) {
+	return &repository.Dashboard{
+		TotalClientes:      1,
+		TotalCompras:       1,
+		ComprasPorMes:      []repository.QuantidadePorPeriodo{{Mes: "2026-10", Quantidade: 1}},
+		ItensMaisComprados: []repository.QuantidadePorItem{{Nome: "Arroz", Quantidade: 1}},
+		ClientesMaisAtivos: []repository.QuantidadePorCliente{{Nome: "Mercado Central", Quantidade: 1}},
+	}, nil
+}
+
+func (g geradorFixo) Gerar(context.Context, ...string) ([]alerta.Alerta, error) {
+	return g.alertas, nil
+}
+
+func (geradorFixo) Simular(context.Context, *alerta.Expressao) ([]*alerta.Fatos, error) {
+	return []*alerta.Fatos{{ClienteID: clienteID, NomeCliente: "Mercado Central"}}, nil
+}
+
+// ProximaJanela devolve a próxima segunda-feira, o dia de compra do cliente.
+func (geradorFixo) ProximaJanela(context.Context, string) (*alerta.Janela, error) {
+	dia := time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)
+	return &alerta.Janela{Previsto: dia, Dia: dia, Desde: dia.AddDate(0, 0, -3), Ultimo: dia, Fecha: dia.Add(10 * time.Hour)}, nil
+}
+
+func (geradorFixo) Location() *time.Location {
+	return time.UTC
+}
+
+func (sugestoesFixas) Sugerir(string, int) []sugestao.Sugestao {
+	return []sugestao.Sugestao{{ItemID: "0e1f2a3b-4c5d-4e6f-8a7b-9c0d1e2f3a0b", Nome: "Feijão", Pontuacao: 0.816, Motivo: "Comprado por 2 dos 3 clientes que compram Arroz."}}
+}
+
+func (sugestoesFixas) AtualizadoEm() time.Time {
+	return time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+}
+
+// servidorContrato sobe as rotas de /api/v1 e os apelidos obsoletos em /api,
+// com os middlewares de main, sobre os repositórios em memória.
+func servidorContrato(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	item := model.Item{ID: itemID, Nome: "Arroz"}
+	cliente := model.Cliente{
+		ID:            clienteID,
+		CNPJ:          "12345678000199",
+		Nome:          "Mercado Central",
+		Telefone:      "11 99999-0000",
+		Endereco:      "Rua A, 1",
+		Representante: "Ana",
+		Itens:         []model.Item{item},
+		DiasCompra:    []model.DiaCompraCliente{{DiaSemana: 1, Frequencia: model.FrequenciaSemanal, HoraFim: "10:00"}},
+	}
+	repos := repository.Repositorios{
+		Clientes: &clientesMemoria{clientes: []model.Cliente{cliente}},
+		Compras: &comprasMemoria{compras: []model.Compra{{
+			ID:         compraID,
+			ClienteID:  clienteID,
+			Cliente:    cliente,
+			DataCompra: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+			Itens:      []model.CompraItem{{ID: "2b3c4d5e-6f70-4182-93a4-b5c6d7e8f907", ItemID: itemID, Item: item, Quantidade: 2, Preco: 59.8}},
+		}}},
+		Contatos:     contatosFixos{},
+		Interacoes:   interacoesFixas{},
+		Tarefas:      &tarefasMemoria{},
+		RegrasTarefa: regrasTarefaVazias{},
+		Pedidos: pedidosFixos{pedido: model.PedidoRecorrente{
+			ClienteID:    clienteID,
+			AtualizadoEm: time.Date(2026, 10, 12, 18, 0, 0, 0, time.UTC),
+			Itens:        []model.ItemPedidoRecorrente{{ClienteID: clienteID, ItemID: itemID, Item: item, Quantidade: 2, Preco: 59.8}},
+		}},
+		Feriados:    feriadosVazios{},
+		Fechamentos: fechamentosFixos{},
+		Regras:      regrasVazias{},
+		Alertas:     efetividadeFixa{},
+		Dashboard:   dashboardFixo{},
+	}
+	gerador := geradorFixo{alertas: []alerta.Alerta{{
+		ClienteID:      clienteID,
+		NomeCliente:    "Mercado Central",
+		Tipo:           alerta.TipoItemFaltando,
+		Motivo:         "Cliente deixou de comprar itens recorrentes.",
+		Severidade:     alerta.SeveridadeMedia,
+		Prioridade:     57.5,
+		DiasAtraso:     5,
+		ValorCliente:   59.8,
+		Representante:  "Ana",
+		ItensFaltantes: []string{"Arroz"},
+		ItensDetalhados: []alerta.ItemDetalhado{
+			{Nome: "Arroz", UltimaCompra: time.Date(2026, 8, 20, 0, 0, 0, 0, time.UTC)},
+		},
+	}}}
+
+	hub := ws.NewHub()
+	h := handler.NewHandler(service.New(repos, hub, gerador, sugestoesFixas{}))
+
+	r := gin.New()
+	r.Use(handler.Rastrear(), handler.Erros(), handler.Recuperar())
+	r.NoRoute(handler.RotaNaoEncontrada)
+	registrarRotas(r.Group("/api/v1"), h, &handler.WebSocketHandler{Hub: hub})
+	registrarRotas(r.Group("/api", handler.Obsoleta("/api", "/api/v1", apiSemVersaoObsoletaEm, apiSemVersaoRemovidaEm)), h, &handler.WebSocketHandler{Hub: hub})
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func carregarEspecificacao(t *testing.T) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData(openapi.Especificacao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("especificação inválida: %v", err)
+	}
+	return doc
+}
+
+// As respostas dos handlers, inclusive os erros, seguem a especificação
+// servida em /api/v1/openapi.json.
+func TestRespostasSeguemEspecificacao(t *testing.T) {
+	doc := carregarEspecificacao(t)
+	rotas, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := servidorContrato(t)
+
+	casos := []struct {
+		metodo, caminho, corpo string
+		status                 int
+	}{
+		{"GET", "/api/v1/", "", http.StatusOK},
+		{"GET", "/api/v1/openapi.json", "", http.StatusOK},
+		{"GET", "/api/v1/clientes", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/" + clienteID, "", http.StatusOK},
+		{"GET", "/api/v1/clientes/0f0e0d0c-0b0a-4908-8706-050403020100", "", http.StatusNotFound},
+		{"GET", "/api/v1/clientes/" + clienteID + "/historico", "", http.StatusOK},
+		{"POST", "/api/v1/clientes", `{
+			"cnpj": "98765432000188", "nome": "Padaria Sol", "telefone": "11 3333-0000",
+			"endereco": "Rua B, 2", "itens": [{"id": "` + itemID + `"}, {"nome": "Café"}],
+			"dias_compra": [{"dia_semana": 3, "frequencia": "mensal", "semana_do_mes": -1, "tolerancia_dias": 1}]
+		}`, http.StatusCreated},
+		{"POST", "/api/v1/clientes", `{"nome": "Sem CNPJ"}`, http.StatusUnprocessableEntity},
+		{"GET", "/api/v1/compras", "", http.StatusOK},
+		{"POST", "/api/v1/compras", `{
+			"cliente_id": "` + clienteID + `", "data": "2026-10-19",
+			"itens": [{"item_id": "` + itemID + `", "quantidade": 1, "preco": 29.9}]
+		}`, http.StatusCreated},
+		{"GET", "/api/v1/alertas", "", http.StatusOK},
+		{"GET", "/api/v1/alertas/hoje", "", http.StatusOK},
+		{"GET", "/api/v1/alertas/incidentes", "", http.StatusOK},
+		{"GET", "/api/v1/dashboard", "", http.StatusOK},
+		{"GET", "/api/v1/tarefas", "", http.StatusOK},
+		{"GET", "/api/v1/tarefas/regras", "", http.StatusOK},
+		{"GET", "/api/v1/feriados", "", http.StatusOK},
+		{"GET", "/api/v1/regras", "", http.StatusOK},
+		{"POST", "/api/v1/regras/simular", `{"expressao": "dias_sem_comprar > 30"}`, http.StatusOK},
+		{"POST", "/api/v1/regras/simular", `{"expressao": "dias_sem_comprar >"}`, http.StatusUnprocessableEntity},
+		{"POST", "/api/v1/tarefas", `{
+			"cliente_id": "` + clienteID + `", "tipo_alerta": "item_faltando",
+			"responsavel": "Ana", "vencimento": "2026-10-23"
+		}`, http.StatusCreated},
+		{"POST", "/api/v1/tarefas", `{"cliente_id": "` + clienteID + `", "vencimento": "2026-10-23"}`, http.StatusUnprocessableEntity},
+		{"GET", "/api/v1/analytics/alertas?desde=2026-07-01&ate=2026-10-19", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/" + clienteID + "/contatos", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/" + clienteID + "/interacoes", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/" + clienteID + "/fechamentos", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/" + clienteID + "/pedido-recorrente", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/" + clienteID + "/proximo-pedido", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/" + clienteID + "/sugestoes", "", http.StatusOK},
+		{"GET", "/api/v1/clientes/0f0e0d0c-0b0a-4908-8706-050403020100/sugestoes", "", http.StatusNotFound},
+		{"POST", "/api/v1/clientes/" + clienteID + "/compras/repetir", `{"origem": "pedido_recorrente", "data": "2026-10-19"}`, http.StatusCreated},
+		{"POST", "/api/v1/clientes/" + clienteID + "/compras/repetir", `{"origem": "ultima_compra"}`, http.StatusCreated},
+	}
+	for _, c := range casos {
+		t.Run(c.metodo+" "+c.caminho, func(t *testing.T) {
+			req, err := http.NewRequest(c.metodo, srv.URL+c.caminho, strings.NewReader(c.corpo))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.corpo != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rota, parametros, err := rotas.FindRoute(req)
+			if err != nil {
+				t.Fatalf("rota fora da especificação: %v", err)
+			}
+
+			entrada := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: parametros,
+				Route:      rota,
+				Options:    &openapi3filter.Options{IncludeResponseStatus: true, MultiError: true},
+			}
+			// O caso de erro de validação manda de propósito um corpo fora
+			// da especificação
+			if c.status != http.StatusUnprocessableEntity {
+				if err := openapi3filter.ValidateRequest(context.Background(), entrada); err != nil {
+					t.Fatalf("requisição fora da especificação: %v", err)
+				}
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			corpo, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != c.status {
+				t.Fatalf("status = %d, esperado %d: %s", resp.StatusCode, c.status, corpo)
+			}
+
+			if err := openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: entrada,
+				Status:                 resp.StatusCode,
+				Header:                 resp.Header,
+				Body:                   io.NopCloser(bytes.NewReader(corpo)),
+				Options:                entrada.Options,
+			}); err != nil {
+				t.Errorf("resposta fora da especificação: %v\n%s", err, corpo)
+			}
+		})
+	}
+}
+
+// As rotas sem versão mantêm os formatos de antes da v1 até serem removidas:
+// os modelos antigos de clientes e compras e os erros em {"erro": ...}.
+func TestApiSemVersaoMantemFormatosAntigos(t *testing.T) {
+	srv := servidorContrato(t)
+
+	casos := []struct {
+		metodo, caminho, corpo string
+		status                 int
+		esperado               string
+	}{
+		{"GET", "/api/clientes/" + clienteID, "", http.StatusOK, `{
+			"id": "` + clienteID + `", "cnpj": "12345678000199", "nome": "Mercado Central",
+			"telefone": "11 99999-0000", "email": "", "endereco": "Rua A, 1", "representante": "Ana",
+			"itens": [{"id": "` + itemID + `", "nome": "Arroz"}], "dias_compra": [{"dia_semana": 1}]
+		}`},
+		{"GET", "/api/clientes/" + clienteID + "/historico", "", http.StatusOK, `{
+			"cliente": {"nome": "Mercado Central", "cnpj": "12345678000199", "telefone": "11 99999-0000",
+				"endereco": "Rua A, 1", "representante": "Ana"},
+			"historico": [{"data": "2026-10-12T00:00:00Z", "itens": [{"nome": "Arroz", "preco": 59.8}]}]
+		}`},
+		{"GET", "/api/compras", "", http.StatusOK, `[{
+			"id": "` + compraID + `", "cliente_id": "` + clienteID + `", "nome_cliente": "Mercado Central",
+			"data": "2026-10-12T00:00:00Z", "itens": [{"nome": "Arroz", "preco": 59.8}]
+		}]`},
+		{"POST", "/api/compras", `{
+			"cliente_id": "` + clienteID + `", "data": "2026-10-19",
+			"itens": [{"item_id": "` + itemID + `", "preco": 29.9}]
+		}`, http.StatusCreated, `{
+			"id": "1f2e3d4c-5b6a-4978-8695-a4b3c2d1e006", "cliente_id": "` + clienteID + `",
+			"data": "2026-10-19T00:00:00Z", "itens": [{"id": "", "item_id": "` + itemID + `", "preco": 29.9}]
+		}`},
+		{"GET", "/api/clientes/0f0e0d0c-0b0a-4908-8706-050403020100", "", http.StatusNotFound,
+			`{"erro": "Cliente não encontrado"}`},
+		{"POST", "/api/compras", `{"cliente_id": "` + clienteID + `", "data": "19/10/2026"}`, http.StatusUnprocessableEntity,
+			`{"erro": "Data inválida (data: use o formato AAAA-MM-DD)"}`},
+	}
+	for _, c := range casos {
+		t.Run(c.metodo+" "+c.caminho, func(t *testing.T) {
+			req, err := http.NewRequest(c.metodo, srv.URL+c.caminho, strings.NewReader(c.corpo))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.corpo != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			corpo, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != c.status {
+				t.Fatalf("status = %d, esperado %d: %s", resp.StatusCode, c.status, corpo)
+			}
+			if tipo := resp.Header.Get("Content-Type"); !strings.HasPrefix(tipo, "application/json") {
+				t.Errorf("Content-Type = %q, esperado application/json", tipo)
+			}
+			if resp.Header.Get("Sunset") == "" {
+				t.Error("resposta sem o cabeçalho Sunset")
+			}
+
+			var obtido, esperado any
+			if err := json.Unmarshal(corpo, &obtido); err != nil {
+				t.Fatalf("corpo não é JSON: %v\n%s", err, corpo)
+			}
+			if err := json.Unmarshal([]byte(c.esperado), &esperado); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(obtido, esperado) {
+				t.Errorf("corpo = %s\nesperado %s", corpo, c.esperado)
+			}
+		})
+	}
+}
+
+// Toda rota registrada em /api/v1 está na especificação, e vice-versa.
+func TestRotasDocumentadas(t *testing.T) {
+	doc := carregarEspecificacao(t)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	registrarRotas(r.Group("/api/v1"), &handler.Handler{}, &handler.WebSocketHandler{})
+
+	parametro := regexp.MustCompile(`:(\w+)`)
+	var registradas []string
+	for _, rota := range r.Routes() {
+		caminho := parametro.ReplaceAllString(rota.Path, "{$1}")
+		registradas = append(registradas, rota.Method+" "+caminho)
+		if item := doc.Paths.Value(caminho); item == nil || item.GetOperation(rota.Method) == nil {
+			t.Errorf("%s %s não está na especificação", rota.Method, caminho)
+		}
+	}
+
+	for caminho, item := range doc.Paths.Map() {
+		if !strings.HasPrefix(caminho, "/api/v1/") {
+			continue
+		}
+		for metodo := range item.Operations() {
+			if !slices.Contains(registradas, metodo+" "+caminho) {
+				t.Errorf("%s %s está na especificação mas não é registrada", metodo, caminho)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package alerta
+
+import (
+	"time"
+
+	"smart-retention/internal/calendario"
+)
+
+// maxDiasAdiados limita quanto um dia de compra fechado pode ser adiado.
+const maxDiasAdiados = 31
+
+type (
+	// Calendario diz em que dias cada cliente está fechado: nos feriados,
+	// nacionais ou cadastrados, que valem para todos, nos pontos
+	// facultativos, só para os clientes que fecham neles, e nos fechamentos
+	// avisados pelo próprio cliente. Os dias são datas à meia-noite UTC.
+	Calendario struct {
+		feriados          map[time.Time]bool
+		facultativos      map[time.Time]bool
+		anos              map[int]bool    // anos com os feriados nacionais já incluídos
+		fechaFacultativos map[string]bool // clientes que fecham nos pontos facultativos
+		fechamentos       map[string][]periodo
+	}
+
+	periodo struct {
+		inicio, fim time.Time
+	}
+)
+
+// CarregarCalendario lê os feriados cadastrados e os fechamentos de todos os
+// clientes. Os feriados nacionais são calculados conforme os anos consultados.
+func CarregarCalendario(ctx Contexto) (*Calendario, error) {
+	feriados, err := ctx.Repositorio.Feriados(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	fechamentos, err := ctx.Repositorio.Fechamentos(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	optantes, err := ctx.Repositorio.FechamFacultativos(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Calendario{
+		feriados:          make(map[time.Time]bool, len(feriados)),
+		facultativos:      make(map[time.Time]bool),
+		anos:              make(map[int]bool),
+		fechaFacultativos: make(map[string]bool, len(optantes)),
+		fechamentos:       make(map[string][]periodo),
+	}
+	for _, id := range optantes {
+		c.fechaFacultativos[id] = true
+	}
+	for _, f := range feriados {
+		c.feriados[Dia(f.UTC())] = true
+	}
+	for _, f := range fechamentos {
+		c.fechamentos[f.ClienteID] = append(c.fechamentos[f.ClienteID], periodo{
+			inicio: Dia(f.Inicio.UTC()),
+			fim:    Dia(f.Fim.UTC()),
+		})
+	}
+	return c, nil
+}
+
+// Feriado informa se o dia é feriado para todos os clientes. Pontos
+// facultativos não contam; ver Facultativo.
+func (c *Calendario) Feriado(dia time.Time) bool {
+	c.incluirNacionais(dia.Year())
+	return c.feriados[dia]
+}
+
+// Facultativo informa se o dia é ponto facultativo nacional.
+func (c *Calendario) Facultativo(dia time.Time) bool {
+	c.incluirNacionais(dia.Year())
+	return c.facultativos[dia]
+}
+
+func (c *Calendario) incluirNacionais(ano int) {
+	if c.anos[ano] {
+		return
+	}
+	for _, f := range calendario.Nacionais(ano) {
+		if f.Facultativo {
+			c.facultativos[f.Data] = true
+		} else {
+			c.feriados[f.Data] = true
+		}
+	}
+	c.anos[ano] = true
+}
+
+// FechadoPeloCliente informa se o dia cai em um fechamento do cliente.
+func (c *Calendario) FechadoPeloCliente(clienteID string, dia time.Time) bool {
+	for _, p := range c.fechamentos[clienteID] {
+		if !dia.Before(p.inicio) && !dia.After(p.fim) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fechado informa se o cliente não abre no dia: por feriado, por ponto
+// facultativo, se ele fecha nesses dias, ou por fechamento avisado.
+func (c *Calendario) Fechado(clienteID string, dia time.Time) bool {
+	return c.Feriado(dia) ||
+		(c.fechaFacultativos[clienteID] && c.Facultativo(dia)) ||
+		c.FechadoPeloCliente(clienteID, dia)
+}
+
+// DiasFechados conta os dias de (de, ate] em que o cliente esteve fechado.
+func (c *Calendario) DiasFechados(clienteID string, de, ate time.Time) int {
+	n := 0
+	for d := Dia(de.UTC()).AddDate(0, 0, 1); !d.After(ate); d = d.AddDate(0, 0, 1) {
+		if c.Fechado(clienteID, d) {
+			n++
+		}
+	}
+	return n
+}
+
+// ProximoAberto devolve o primeiro dia, a partir de dia, em que o cliente
+// não está fechado, procurando no máximo maxDiasAdiados dias adiante.
+func (c *Calendario) ProximoAberto(clienteID string, dia time.Time) time.Time {
+	for n := 0; n < maxDiasAdiados && c.Fechado(clienteID, dia); n++ {
+		dia = dia.AddDate(0, 0, 1)
+	}
+	return dia
+}
@@ -0,0 +1,60 @@
+package alerta
+
+import (
+	"testing"
+	"time"
+)
+
+func novoCalendario() *Calendario {
+	return &Calendario{
+		feriados:          make(map[time.Time]bool),
+		facultativos:      make(map[time.Time]bool),
+		anos:              make(map[int]bool),
+		fechaFacultativos: make(map[string]bool),
+		fechamentos:       make(map[string][]periodo),
+	}
+}
+
+func data(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestCalendarioFacultativoSoFechaQuemOptou(t *testing.T) {
+	cal := novoCalendario()
+	cal.fechaFacultativos["fecha"] = true
+	carnaval := data("2026-02-17")
+	natal := data("2026-12-25")
+
+	casos := []struct {
+		cliente string
+		dia     time.Time
+		fechado bool
+	}{
+		{"abre", carnaval, false},
+		{"fecha", carnaval, true},
+		{"abre", natal, true},
+		{"fecha", natal, true},
+	}
+	for _, c := range casos {
+		if got := cal.Fechado(c.cliente, c.dia); got != c.fechado {
+			t.Errorf("Fechado(%s, %s) = %v, esperava %v", c.cliente, c.dia.Format("02/01"), got, c.fechado)
+		}
+	}
+	if cal.Feriado(carnaval) {
+		t.Error("carnaval não é feriado para todos")
+	}
+}
+
+func TestCalendarioFeriadoCadastradoEmFacultativoValeParaTodos(t *testing.T) {
+	cal := novoCalendario()
+	corpus := data("2026-06-04")
+	cal.feriados[corpus] = true // cadastrado, como feriado municipal
+
+	if !cal.Fechado("qualquer", corpus) {
+		t.Error("o feriado cadastrado deveria fechar todos os clientes")
+	}
+}
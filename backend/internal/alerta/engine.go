@@ -0,0 +1,198 @@
+package alerta
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"smart-retention/internal/infra/rastreio"
+	"smart-retention/internal/sugestao"
+)
+
+const (
+	TipoDiaPrevisto      = "dia_previsto"
+	TipoInatividade      = "inatividade"
+	TipoItemFaltando     = "item_faltando"
+	TipoCompraIncompleta = "compra_incompleta"
+)
+
+type (
+	Alerta struct {
+		ClienteID       string          `json:"cliente_id"`
+		NomeCliente     string          `json:"nome_cliente"`
+		Tipo            string          `json:"tipo"`
+		Motivo          string          `json:"motivo"`
+		RegraID         string          `json:"regra_id,omitempty"`
+		Severidade      Severidade      `json:"severidade"`
+		Prioridade      float64         `json:"prioridade"`
+		DiasAtraso      int             `json:"dias_atraso,omitempty"`
+		ValorCliente    float64         `json:"valor_cliente,omitempty"` // gasto nos últimos 90 dias
+		Representante   string          `json:"representante,omitempty"`
+		ItensFaltantes  []string        `json:"itens_faltantes,omitempty"`
+		ItensDetalhados []ItemDetalhado `json:"itens_detalhados,omitempty"`
+		// Itens a oferecer no contato com o cliente, nos alertas de item
+		// faltando
+		Sugestoes []sugestao.Sugestao `json:"sugestoes,omitempty"`
+	}
+
+	ItemDetalhado struct {
+		Nome         string    `json:"nome"`
+		UltimaCompra time.Time `json:"ultima_compra"`
+	}
+
+	// Contexto reúne o que uma regra precisa para ser avaliada. As consultas
+	// ao Repositorio usam Ctx.
+	Contexto struct {
+		Ctx         context.Context
+		Repositorio Repositorio
+		Agora       time.Time // já convertido para o fuso do motor
+	}
+
+	// Sugeridor indica itens que o cliente não compra; ver sugestao.Modelo.
+	Sugeridor interface {
+		Sugerir(clienteID string, limite int) []sugestao.Sugestao
+	}
+
+	// Rule é uma condição de alerta. Cada regra gera alertas de um único tipo.
+	Rule interface {
+		Tipo() string
+		Avaliar(ctx Contexto) ([]Alerta, error)
+	}
+
+	// Observador é avisado ao fim de cada geração, com os tipos pedidos
+	// (vazio para todos), a duração e o resultado.
+	Observador func(tipos []string, duracao time.Duration, alertas []Alerta, err error)
+
+	Engine struct {
+		repositorio Repositorio
+		location    *time.Location
+		relogio     func() time.Time
+		rules       []Rule
+		observador  Observador
+	}
+)
+
+func NewEngine(repositorio Repositorio, location *time.Location) *Engine {
+	return &Engine{
+		repositorio: repositorio,
+		location:    location,
+		relogio:     time.Now,
+	}
+}
+
+// Opcoes ajusta os limites das regras padrão. Sem Sugestoes, os alertas de
+// item faltando não trazem sugestões.
+type Opcoes struct {
+	DiasInatividade  int
+	DiasItemFaltando int
+	Sugestoes        Sugeridor
+}
+
+// NewDefaultEngine cria o motor com todas as regras padrão registradas.
+func NewDefaultEngine(repositorio Repositorio, location *time.Location, opcoes Opcoes) *Engine {
+	e := NewEngine(repositorio, location)
+	e.Register(DiaPrevisto{})
+	e.Register(CompraIncompleta{})
+	e.Register(Inatividade{Dias: opcoes.DiasInatividade})
+	e.Register(ItemFaltando{Dias: opcoes.DiasItemFaltando, Sugestoes: opcoes.Sugestoes})
+	e.Register(RegrasPersonalizadas{})
+	return e
+}
+
+func (e *Engine) Register(r Rule) {
+	e.rules = append(e.rules, r)
+}
+
+// Observar define quem é avisado de cada geração, para métricas.
+func (e *Engine) Observar(o Observador) {
+	e.observador = o
+}
+
+func (e *Engine) Location() *time.Location {
+	return e.location
+}
+
+// Gerar avalia as regras registradas. Se tipos for informado, apenas as regras
+// desses tipos são avaliadas. As consultas usam ctx, e cada regra ganha um
+// span dentro do span da geração.
+func (e *Engine) Gerar(ctx context.Context, tipos ...string) ([]Alerta, error) {
+	ctx, span := rastreio.Iniciar(ctx, "alerta.gerar",
+		attribute.String("alerta.tipos", strings.Join(tipos, ",")))
+	defer span.End()
+
+	inicio := time.Now()
+	alertas, err := e.gerar(ctx, tipos)
+	rastreio.Erro(span, err)
+	span.SetAttributes(attribute.Int("alerta.quantidade", len(alertas)))
+	if e.observador != nil {
+		e.observador(tipos, time.Since(inicio), alertas, err)
+	}
+	return alertas, err
+}
+
+func (e *Engine) gerar(ctx context.Context, tipos []string) ([]Alerta, error) {
+	contexto := e.contexto(ctx)
+
+	var alertas []Alerta
+	for _, r := range e.rules {
+		if len(tipos) > 0 && !slices.Contains(tipos, r.Tipo()) {
+			continue
+		}
+
+		res, err := e.avaliar(ctx, contexto, r)
+		if err != nil {
+			return nil, err
+		}
+		alertas = append(alertas, res...)
+	}
+
+	// Clientes que pediram uma pausa (cliente fechado, em reforma) não são
+	// alertados, qualquer que seja a regra.
+	alertas, err := removerPausados(contexto, alertas)
+	if err != nil {
+		return nil, err
+	}
+
+	return priorizar(contexto, deduplicar(alertas))
+}
+
+// avaliar roda a regra com as consultas ligadas ao seu próprio span.
+func (e *Engine) avaliar(ctx context.Context, contexto Contexto, r Rule) ([]Alerta, error) {
+	ctx, span := rastreio.Iniciar(ctx, "alerta.regra "+r.Tipo())
+	defer span.End()
+
+	contexto.Ctx = ctx
+	alertas, err := r.Avaliar(contexto)
+	rastreio.Erro(span, err)
+	span.SetAttributes(attribute.Int("alerta.quantidade", len(alertas)))
+	return alertas, err
+}
+
+// Chave identifica o alerta entre gerações: cliente, tipo e regra.
+func (a Alerta) Chave() string {
+	return a.ClienteID + "|" + a.Tipo + "|" + a.RegraID
+}
+
+func (e *Engine) contexto(ctx context.Context) Contexto {
+	return Contexto{
+		Ctx:         ctx,
+		Repositorio: e.repositorio,
+		Agora:       e.relogio().In(e.location),
+	}
+}
+
+// Hoje devolve o intervalo [início, fim) do dia corrente no formato em que as
+// compras são gravadas: apenas a data, à meia-noite UTC.
+func (c Contexto) Hoje() (time.Time, time.Time) {
+	inicio := Dia(c.Agora)
+	return inicio, inicio.AddDate(0, 0, 1)
+}
+
+// Dia devolve a data de t, no seu próprio fuso, à meia-noite UTC, como são
+// gravadas as datas de compra.
+func Dia(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
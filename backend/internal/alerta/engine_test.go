@@ -0,0 +1,407 @@
+package alerta
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"smart-retention/internal/model"
+)
+
+// repositorioMemoria é um Repositorio em memória: as consultas derivadas
+// (últimas compras, itens sem compra, valor) são calculadas das compras.
+type repositorioMemoria struct {
+	feriados       []time.Time
+	fechamentos    []Fechamento
+	facultativos   []string
+	dias           []DiaCompra
+	clientes       []ClienteCompra // sem UltimaCompra, calculada das compras
+	compras        []Compra
+	itens          map[string][]string // itens recorrentes por cliente
+	pausados       []string
+	representantes map[string]string
+	regras         []model.RegraAlerta
+	erroCompras    error // devolvido por ComprasDesde
+}
+
+func (r *repositorioMemoria) Feriados(context.Context) ([]time.Time, error) {
+	return r.feriados, nil
+}
+
+func (r *repositorioMemoria) Fechamentos(context.Context) ([]Fechamento, error) {
+	return r.fechamentos, nil
+}
+
+func (r *repositorioMemoria) FechamFacultativos(context.Context) ([]string, error) {
+	return r.facultativos, nil
+}
+
+func (r *repositorioMemoria) DiasCompra(context.Context) ([]DiaCompra, error) {
+	return r.dias, nil
+}
+
+func (r *repositorioMemoria) Clientes(context.Context) ([]ClienteCompra, error) {
+	clientes := slices.Clone(r.clientes)
+	for i := range clientes {
+		for _, co := range r.compras {
+			if co.ClienteID == clientes[i].ID && (clientes[i].UltimaCompra == nil || co.Data.After(*clientes[i].UltimaCompra)) {
+				clientes[i].UltimaCompra = &co.Data
+			}
+		}
+	}
+	slices.SortFunc(clientes, func(a, b ClienteCompra) int { return cmp.Compare(a.Nome, b.Nome) })
+	return clientes, nil
+}
+
+func (r *repositorioMemoria) UltimasCompras(_ context.Context, ids []string, ate time.Time) (map[string]time.Time, error) {
+	ultimas := make(map[string]time.Time)
+	for _, co := range r.compras {
+		if slices.Contains(ids, co.ClienteID) && co.Data.Before(ate) && co.Data.After(ultimas[co.ClienteID]) {
+			ultimas[co.ClienteID] = co.Data
+		}
+	}
+	return ultimas, nil
+}
+
+func (r *repositorioMemoria) ComprasDesde(_ context.Context, desde time.Time) ([]Compra, error) {
+	if r.erroCompras != nil {
+		return nil, r.erroCompras
+	}
+	var compras []Compra
+	for _, co := range r.compras {
+		if !co.Data.Before(desde) {
+			compras = append(compras, co)
+		}
+	}
+	return compras, nil
+}
+
+func (r *repositorioMemoria) ItensNaoComprados(_ context.Context, ids []string, inicio, fim time.Time) ([]ItemCliente, error) {
+	var itens []ItemCliente
+	for _, c := range r.porID() {
+		if !slices.Contains(ids, c.ID) {
+			continue
+		}
+		comprou, comprados := false, map[string]bool{}
+		for _, co := range r.compras {
+			if co.ClienteID == c.ID && !co.Data.Before(inicio) && co.Data.Before(fim) {
+				comprou = true
+				for _, item := range co.Itens {
+					comprados[item.Nome] = true
+				}
+			}
+		}
+		if !comprou {
+			continue
+		}
+		for _, nome := range r.itens[c.ID] {
+			if !comprados[nome] {
+				itens = append(itens, ItemCliente{ClienteID: c.ID, NomeCliente: c.Nome, NomeItem: nome})
+			}
+		}
+	}
+	return itens, nil
+}
+
+func (r *repositorioMemoria) ItensSemCompra(_ context.Context, antes time.Time) ([]ItemCliente, error) {
+	var itens []ItemCliente
+	for _, c := range r.porID() {
+		for _, nome := range r.itens[c.ID] {
+			var ultima *time.Time
+			for _, co := range r.compras {
+				if co.ClienteID == c.ID && slices.ContainsFunc(co.Itens, func(i ItemComprado) bool { return i.Nome == nome }) &&
+					(ultima == nil || co.Data.After(*ultima)) {
+					ultima = &co.Data
+				}
+			}
+			if ultima == nil || ultima.Before(antes) {
+				itens = append(itens, ItemCliente{ClienteID: c.ID, NomeCliente: c.Nome, NomeItem: nome, UltimaCompra: ultima})
+			}
+		}
+	}
+	return itens, nil
+}
+
+func (r *repositorioMemoria) Pausados(context.Context, time.Time) ([]string, error) {
+	return r.pausados, nil
+}
+
+func (r *repositorioMemoria) ValorPorCliente(_ context.Context, desde time.Time) (map[string]float64, error) {
+	valores := make(map[string]float64)
+	for _, co := range r.compras {
+		if co.Data.Before(desde) {
+			continue
+		}
+		for _, item := range co.Itens {
+			valores[co.ClienteID] += item.Preco
+		}
+	}
+	return valores, nil
+}
+
+func (r *repositorioMemoria) Representantes(context.Context) (map[string]string, error) {
+	return r.representantes, nil
+}
+
+func (r *repositorioMemoria) RegrasAtivas(context.Context) ([]model.RegraAlerta, error) {
+	return r.regras, nil
+}
+
+// porID devolve os clientes ordenados por id, como as consultas de itens.
+func (r *repositorioMemoria) porID() []ClienteCompra {
+	clientes := slices.Clone(r.clientes)
+	slices.SortFunc(clientes, func(a, b ClienteCompra) int { return cmp.Compare(a.ID, b.ID) })
+	return clientes
+}
+
+// motorEm cria um motor com as regras, parado em agora no fuso de São Paulo.
+func motorEm(t *testing.T, repositorio Repositorio, agora string, regras ...Rule) *Engine {
+	t.Helper()
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("sem a base de fusos:", err)
+	}
+	momento, err := time.ParseInLocation("2006-01-02 15:04", agora, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEngine(repositorio, loc)
+	e.relogio = func() time.Time { return momento }
+	for _, r := range regras {
+		e.Register(r)
+	}
+	return e
+}
+
+func compra(clienteID, dia string, itens ...ItemComprado) Compra {
+	return Compra{ID: clienteID + dia, ClienteID: clienteID, Data: data(dia), Itens: itens}
+}
+
+// chaves resume os alertas como cliente|tipo, na ordem em que vieram.
+func chaves(alertas []Alerta) []string {
+	var lista []string
+	for _, a := range alertas {
+		lista = append(lista, a.ClienteID+"|"+a.Tipo)
+	}
+	return lista
+}
+
+func TestDiaPrevistoAlertaQuemNaoComprouNaJanela(t *testing.T) {
+	quarta := func(id string) DiaCompra {
+		return DiaCompra{ClienteID: id, NomeCliente: id, DiaSemana: int(time.Wednesday), Frequencia: model.FrequenciaSemanal}
+	}
+	repositorio := &repositorioMemoria{
+		dias: []DiaCompra{quarta("atrasado"), quarta("comprou"), quarta("fechado")},
+		compras: []Compra{
+			compra("atrasado", "2026-10-07"),
+			compra("comprou", "2026-10-14"),
+			compra("fechado", "2026-10-07"),
+		},
+		// Fechado na quarta 14/10, o dia passa para a quinta 15/10 e a
+		// janela fecha no fim dela
+		fechamentos: []Fechamento{{ClienteID: "fechado", Inicio: data("2026-10-14"), Fim: data("2026-10-14")}},
+	}
+
+	alertas, err := motorEm(t, repositorio, "2026-10-19 10:00", DiaPrevisto{}).Gerar(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := chaves(alertas), []string{"atrasado|dia_previsto", "fechado|dia_previsto"}; !slices.Equal(got, want) {
+		t.Fatalf("alertas = %v, esperado %v", got, want)
+	}
+	for _, a := range alertas {
+		switch a.ClienteID {
+		case "atrasado":
+			if a.DiasAtraso != 4 {
+				t.Errorf("atrasado: dias de atraso = %d, esperado 4", a.DiasAtraso)
+			}
+		case "fechado":
+			if a.DiasAtraso != 3 {
+				t.Errorf("fechado: dias de atraso = %d, esperado 3", a.DiasAtraso)
+			}
+			if want := "O dia previsto (14/10) caiu em feriado ou fechamento e o cliente não comprou no dia 15/10."; a.Motivo != want {
+				t.Errorf("fechado: motivo = %q", a.Motivo)
+			}
+		}
+	}
+}
+
+func TestCompraIncompletaSoNaJanelaDoDiaDeCompra(t *testing.T) {
+	segunda := func(id string) DiaCompra {
+		return DiaCompra{ClienteID: id, NomeCliente: id, DiaSemana: int(time.Monday), Frequencia: model.FrequenciaSemanal}
+	}
+	arroz := ItemComprado{Nome: "Arroz", Preco: 30}
+	repositorio := &repositorioMemoria{
+		clientes: []ClienteCompra{{ID: "incompleto", Nome: "incompleto"}, {ID: "completo", Nome: "completo"}, {ID: "fora", Nome: "fora"}},
+		dias:     []DiaCompra{segunda("incompleto"), segunda("completo")},
+		compras: []Compra{
+			compra("incompleto", "2026-10-19", arroz),
+			compra("completo", "2026-10-19", arroz, ItemComprado{Nome: "Feijão", Preco: 12}),
+			compra("fora", "2026-10-19", arroz),
+		},
+		itens: map[string][]string{
+			"incompleto": {"Arroz", "Feijão", "Óleo"},
+			"completo":   {"Arroz", "Feijão"},
+			"fora":       {"Arroz", "Feijão"},
+		},
+	}
+
+	alertas, err := motorEm(t, repositorio, "2026-10-19 10:00", CompraIncompleta{}).Gerar(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := chaves(alertas), []string{"incompleto|compra_incompleta"}; !slices.Equal(got, want) {
+		t.Fatalf("alertas = %v, esperado %v", got, want)
+	}
+	if got, want := alertas[0].ItensFaltantes, []string{"Feijão", "Óleo"}; !slices.Equal(got, want) {
+		t.Errorf("itens faltantes = %v, esperado %v", got, want)
+	}
+}
+
+func TestInatividadeDescontaFechamentosEPausas(t *testing.T) {
+	repositorio := &repositorioMemoria{
+		clientes: []ClienteCompra{
+			{ID: "inativo", Nome: "A"},
+			{ID: "ativo", Nome: "B"},
+			{ID: "nunca", Nome: "C"},
+			{ID: "fechado", Nome: "D"},
+			{ID: "ferias", Nome: "E"},
+			{ID: "pausado", Nome: "F"},
+		},
+		compras: []Compra{
+			compra("inativo", "2026-09-01"),
+			compra("ativo", "2026-10-01"),
+			compra("fechado", "2026-09-01"),
+			compra("ferias", "2026-09-01"),
+			compra("pausado", "2026-09-01"),
+		},
+		fechamentos: []Fechamento{
+			// Fechado hoje: não é alertado
+			{ClienteID: "fechado", Inicio: data("2026-10-15"), Fim: data("2026-10-25")},
+			// Fechou 20 dias e já reabriu: os dias não contam
+			{ClienteID: "ferias", Inicio: data("2026-09-02"), Fim: data("2026-09-21")},
+		},
+		pausados: []string{"pausado"},
+	}
+
+	alertas, err := motorEm(t, repositorio, "2026-10-19 10:00", Inatividade{Dias: 30}).Gerar(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(alertas, func(a, b Alerta) int { return cmp.Compare(a.ClienteID, b.ClienteID) })
+	if got, want := chaves(alertas), []string{"inativo|inatividade", "nunca|inatividade"}; !slices.Equal(got, want) {
+		t.Fatalf("alertas = %v, esperado %v", got, want)
+	}
+	// De 01/09 a 19/10 são 48 dias, dois deles feriados (07/09 e 12/10)
+	if alertas[0].DiasAtraso != 16 {
+		t.Errorf("dias de atraso = %d, esperado 16", alertas[0].DiasAtraso)
+	}
+}
+
+func TestItemFaltandoDetalhaItensETrazSugestoes(t *testing.T) {
+	repositorio := &repositorioMemoria{
+		clientes: []ClienteCompra{{ID: "c1", Nome: "Mercado"}},
+		compras: []Compra{
+			compra("c1", "2026-08-20", ItemComprado{Nome: "Arroz", Preco: 30}),
+			compra("c1", "2026-10-10", ItemComprado{Nome: "Feijão", Preco: 12}),
+		},
+		itens: map[string][]string{"c1": {"Arroz", "Café", "Feijão"}},
+	}
+
+	alertas, err := motorEm(t, repositorio, "2026-10-19 10:00", ItemFaltando{Dias: 30}).Gerar(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := chaves(alertas), []string{"c1|item_faltando"}; !slices.Equal(got, want) {
+		t.Fatalf("alertas = %v, esperado %v", got, want)
+	}
+	a := alertas[0]
+	if got, want := a.ItensFaltantes, []string{"Arroz", "Café"}; !slices.Equal(got, want) {
+		t.Errorf("itens faltantes = %v, esperado %v", got, want)
+	}
+	if !a.ItensDetalhados[0].UltimaCompra.Equal(data("2026-08-20")) || !a.ItensDetalhados[1].UltimaCompra.IsZero() {
+		t.Errorf("itens detalhados = %+v", a.ItensDetalhados)
+	}
+	// Arroz: 60 dias desde 20/08, 30 além do limite
+	if a.DiasAtraso != 30 {
+		t.Errorf("dias de atraso = %d, esperado 30", a.DiasAtraso)
+	}
+}
+
+func TestRegrasPersonalizadas(t *testing.T) {
+	repositorio := &repositorioMemoria{
+		clientes: []ClienteCompra{{ID: "c1", Nome: "Mercado"}, {ID: "c2", Nome: "Padaria"}},
+		compras: []Compra{
+			compra("c1", "2026-10-10", ItemComprado{Nome: "Arroz", Preco: 300}),
+			compra("c2", "2026-10-12", ItemComprado{Nome: "Café", Preco: 20}),
+		},
+		regras: []model.RegraAlerta{
+			{ID: "r1", Nome: "Gasto baixo", Expressao: "gasto(30) < 100", Motivo: "Gastou pouco no mês.", Ativa: true},
+			{ID: "r2", Nome: "Inválida", Expressao: "gasto(30) <", Ativa: true},
+		},
+	}
+	motor := motorEm(t, repositorio, "2026-10-19 10:00", RegrasPersonalizadas{})
+
+	alertas, err := motor.Gerar(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A regra inválida é ignorada; a outra alerta só a padaria
+	if len(alertas) != 1 || alertas[0].ClienteID != "c2" || alertas[0].RegraID != "r1" || alertas[0].Motivo != "Gastou pouco no mês." {
+		t.Fatalf("alertas = %+v", alertas)
+	}
+
+	// Sem os fatos, a geração falha em vez de dar os alertas da regra como
+	// resolvidos
+	repositorio.erroCompras = errors.New("banco fora do ar")
+	if alertas, err := motor.Gerar(context.Background()); err == nil {
+		t.Errorf("geração sem os fatos = %+v, esperado erro", alertas)
+	}
+}
+
+func TestPriorizarSobeSeveridadePorAtrasoEValor(t *testing.T) {
+	repositorio := &repositorioMemoria{
+		compras: []Compra{
+			compra("grande", "2026-09-10", ItemComprado{Nome: "Arroz", Preco: 5000}),
+			compra("medio", "2026-09-10", ItemComprado{Nome: "Arroz", Preco: 50}),
+			compra("pequeno", "2026-09-10", ItemComprado{Nome: "Arroz", Preco: 500}),
+			// Fora dos 90 dias: não conta no valor
+			compra("pequeno", "2026-06-01", ItemComprado{Nome: "Arroz", Preco: 90000}),
+		},
+		representantes: map[string]string{"grande": "Ana"},
+	}
+	contexto := motorEm(t, repositorio, "2026-10-19 10:00").contexto(context.Background())
+
+	alertas, err := priorizar(contexto, []Alerta{
+		{ClienteID: "pequeno", Tipo: TipoCompraIncompleta},
+		{ClienteID: "medio", Tipo: TipoDiaPrevisto, DiasAtraso: 14},
+		{ClienteID: "grande", Tipo: TipoDiaPrevisto, DiasAtraso: 30},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	esperado := []struct {
+		cliente    string
+		severidade Severidade
+		valor      float64
+	}{
+		{"grande", SeveridadeCritica, 5000},
+		{"medio", SeveridadeAlta, 50},
+		// Entre os 25% de maior valor, sobe um nível
+		{"pequeno", SeveridadeMedia, 500},
+	}
+	for i, e := range esperado {
+		a := alertas[i]
+		if a.ClienteID != e.cliente || a.Severidade != e.severidade || a.ValorCliente != e.valor {
+			t.Errorf("alerta %d = %s %s %.0f, esperado %s %s %.0f",
+				i, a.ClienteID, a.Severidade, a.ValorCliente, e.cliente, e.severidade, e.valor)
+		}
+	}
+	if alertas[0].Representante != "Ana" {
+		t.Errorf("representante = %q, esperado Ana", alertas[0].Representante)
+	}
+}
@@ -0,0 +1,578 @@
+package alerta
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Linguagem de expressões das regras personalizadas.
+//
+// Gramática (do menor para o maior nível de precedência):
+//
+//	ou       = e { "||" e }
+//	e        = nao { "&&" nao }
+//	nao      = "!" nao | cmp
+//	cmp      = soma [ ("==" | "!=" | "<" | "<=" | ">" | ">=") soma ]
+//	soma     = produto { ("+" | "-") produto }
+//	produto  = unario { ("*" | "/") unario }
+//	unario   = "-" unario | primario
+//	primario = numero | texto | "true" | "false" | nome | nome "(" [ ou { "," ou } ] ")" | "(" ou ")"
+//
+// As expressões são tipadas na compilação e só enxergam os fatos do cliente
+// expostos em variaveis e funcoes; não há acesso ao banco nem efeitos colaterais.
+//
+// Exemplos:
+//
+//	gasto_mes < 500
+//	comprou("Arroz", 30) && !comprou("Feijão", 30)
+//	dias_sem_comprar > 10 && compras(90) >= 6
+
+const (
+	// janelaPadrao é o período, em dias, considerado por comprou() quando a
+	// janela não é informada.
+	janelaPadrao = 365
+
+	// janelaMinima cobre o mês corrente, usado por gasto_mes.
+	janelaMinima = 31
+
+	// janelaMaxima limita as janelas informadas: dez anos de histórico bastam
+	// e mantêm as datas calculadas dentro do que o Postgres aceita.
+	janelaMaxima = 3650
+)
+
+type tipo int
+
+const (
+	tipoNumero tipo = iota
+	tipoBool
+	tipoTexto
+)
+
+func (t tipo) String() string {
+	switch t {
+	case tipoNumero:
+		return "número"
+	case tipoBool:
+		return "booleano"
+	default:
+		return "texto"
+	}
+}
+
+type (
+	Expressao struct {
+		fonte  string
+		raiz   no
+		janela int
+	}
+
+	no interface {
+		tipo() tipo
+		avaliar(f *Fatos) any
+	}
+
+	variavel struct {
+		tipo  tipo
+		valor func(f *Fatos) any
+	}
+
+	funcao struct {
+		params    []tipo
+		opcionais int // quantos dos últimos parâmetros podem ser omitidos
+		retorno   tipo
+		chamar    func(f *Fatos, args []any) any
+	}
+)
+
+var variaveis = map[string]variavel{
+	"dias_sem_comprar": {tipoNumero, func(f *Fatos) any { return float64(f.DiasSemComprar()) }},
+	"nunca_comprou":    {tipoBool, func(f *Fatos) any { return f.UltimaCompra == nil }},
+	"dia_semana":       {tipoNumero, func(f *Fatos) any { return float64(f.Hoje.Weekday()) }},
+	"dia_previsto":     {tipoBool, func(f *Fatos) any { return f.DiaPrevisto }},
+	"gasto_mes":        {tipoNumero, func(f *Fatos) any { return f.GastoMes() }},
+}
+
+var funcoes = map[string]funcao{
+	"gasto": {
+		params:  []tipo{tipoNumero},
+		retorno: tipoNumero,
+		chamar:  func(f *Fatos, args []any) any { return f.Gasto(int(args[0].(float64))) },
+	},
+	"compras": {
+		params:  []tipo{tipoNumero},
+		retorno: tipoNumero,
+		chamar:  func(f *Fatos, args []any) any { return float64(f.Compras(int(args[0].(float64)))) },
+	},
+	"comprou": {
+		params:    []tipo{tipoTexto, tipoNumero},
+		opcionais: 1,
+		retorno:   tipoBool,
+		chamar: func(f *Fatos, args []any) any {
+			dias := janelaPadrao
+			if len(args) > 1 {
+				dias = int(args[1].(float64))
+			}
+			return f.Comprou(args[0].(string), dias)
+		},
+	},
+}
+
+// CompilarExpressao valida a expressão e a prepara para avaliação. A expressão
+// precisa resultar em um booleano.
+func CompilarExpressao(fonte string) (*Expressao, error) {
+	tokens, err := lexer(fonte)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, janela: janelaMinima}
+	raiz, err := p.ou()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.atual(); t.tipo != tokFim {
+		return nil, fmt.Errorf("token inesperado %q na posição %d", t.texto, t.pos)
+	}
+	if raiz.tipo() != tipoBool {
+		return nil, fmt.Errorf("a expressão deve resultar em booleano, mas resulta em %s", raiz.tipo())
+	}
+
+	return &Expressao{fonte: fonte, raiz: raiz, janela: p.janela}, nil
+}
+
+func (e *Expressao) String() string {
+	return e.fonte
+}
+
+// Janela devolve quantos dias de histórico de compras a expressão precisa.
+func (e *Expressao) Janela() int {
+	return e.janela
+}
+
+func (e *Expressao) Avaliar(f *Fatos) bool {
+	return e.raiz.avaliar(f).(bool)
+}
+
+// --- lexer
+
+type tokTipo int
+
+const (
+	tokFim tokTipo = iota
+	tokNumero
+	tokTexto
+	tokNome
+	tokOperador
+)
+
+type token struct {
+	tipo  tokTipo
+	texto string
+	pos   int
+}
+
+var operadores = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")", ","}
+
+func lexer(fonte string) ([]token, error) {
+	var tokens []token
+	runes := []rune(fonte)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r):
+			inicio := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumero, string(runes[inicio:i]), inicio})
+
+		case unicode.IsLetter(r) || r == '_':
+			inicio := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokNome, string(runes[inicio:i]), inicio})
+
+		case r == '"' || r == '\'':
+			inicio := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("texto não terminado na posição %d", inicio)
+			}
+			tokens = append(tokens, token{tokTexto, string(runes[inicio+1 : i]), inicio})
+			i++
+
+		default:
+			resto := string(runes[i:])
+			achou := false
+			for _, op := range operadores {
+				if strings.HasPrefix(resto, op) {
+					tokens = append(tokens, token{tokOperador, op, i})
+					i += len([]rune(op))
+					achou = true
+					break
+				}
+			}
+			if !achou {
+				return nil, fmt.Errorf("caractere inválido %q na posição %d", r, i)
+			}
+		}
+	}
+
+	return append(tokens, token{tokFim, "", len(runes)}), nil
+}
+
+// --- parser
+
+type parser struct {
+	tokens []token
+	pos    int
+	janela int
+}
+
+func (p *parser) atual() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) consumir(ops ...string) (string, bool) {
+	t := p.atual()
+	if t.tipo != tokOperador {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.texto == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) esperar(op string) error {
+	if _, ok := p.consumir(op); !ok {
+		t := p.atual()
+		return fmt.Errorf("esperado %q na posição %d", op, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) ou() (no, error) {
+	return p.logico(p.e, "||")
+}
+
+func (p *parser) e() (no, error) {
+	return p.logico(p.nao, "&&")
+}
+
+func (p *parser) logico(proximo func() (no, error), op string) (no, error) {
+	esq, err := proximo()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.consumir(op); !ok {
+			return esq, nil
+		}
+		dir, err := proximo()
+		if err != nil {
+			return nil, err
+		}
+		if esq.tipo() != tipoBool || dir.tipo() != tipoBool {
+			return nil, fmt.Errorf("%q exige operandos booleanos", op)
+		}
+		esq = &binario{op: op, esq: esq, dir: dir, t: tipoBool}
+	}
+}
+
+func (p *parser) nao() (no, error) {
+	if _, ok := p.consumir("!"); ok {
+		x, err := p.nao()
+		if err != nil {
+			return nil, err
+		}
+		if x.tipo() != tipoBool {
+			return nil, fmt.Errorf("\"!\" exige operando booleano")
+		}
+		return &unario{op: "!", x: x}, nil
+	}
+	return p.cmp()
+}
+
+func (p *parser) cmp() (no, error) {
+	esq, err := p.soma()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.consumir("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return esq, nil
+	}
+
+	dir, err := p.soma()
+	if err != nil {
+		return nil, err
+	}
+	if esq.tipo() != dir.tipo() {
+		return nil, fmt.Errorf("não é possível comparar %s com %s", esq.tipo(), dir.tipo())
+	}
+	if op != "==" && op != "!=" && esq.tipo() != tipoNumero {
+		return nil, fmt.Errorf("%q exige operandos numéricos", op)
+	}
+
+	return &binario{op: op, esq: esq, dir: dir, t: tipoBool}, nil
+}
+
+func (p *parser) soma() (no, error) {
+	return p.aritmetico(p.produto, "+", "-")
+}
+
+func (p *parser) produto() (no, error) {
+	return p.aritmetico(p.unario, "*", "/")
+}
+
+func (p *parser) aritmetico(proximo func() (no, error), ops ...string) (no, error) {
+	esq, err := proximo()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.consumir(ops...)
+		if !ok {
+			return esq, nil
+		}
+		dir, err := proximo()
+		if err != nil {
+			return nil, err
+		}
+		if esq.tipo() != tipoNumero || dir.tipo() != tipoNumero {
+			return nil, fmt.Errorf("%q exige operandos numéricos", op)
+		}
+		esq = &binario{op: op, esq: esq, dir: dir, t: tipoNumero}
+	}
+}
+
+func (p *parser) unario() (no, error) {
+	if _, ok := p.consumir("-"); ok {
+		x, err := p.unario()
+		if err != nil {
+			return nil, err
+		}
+		if x.tipo() != tipoNumero {
+			return nil, fmt.Errorf("\"-\" exige operando numérico")
+		}
+		return &unario{op: "-", x: x}, nil
+	}
+	return p.primario()
+}
+
+func (p *parser) primario() (no, error) {
+	t := p.atual()
+
+	switch t.tipo {
+	case tokNumero:
+		p.pos++
+		v, err := strconv.ParseFloat(t.texto, 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido %q na posição %d", t.texto, t.pos)
+		}
+		return &literal{v: v, t: tipoNumero}, nil
+
+	case tokTexto:
+		p.pos++
+		return &literal{v: t.texto, t: tipoTexto}, nil
+
+	case tokNome:
+		p.pos++
+		switch t.texto {
+		case "true":
+			return &literal{v: true, t: tipoBool}, nil
+		case "false":
+			return &literal{v: false, t: tipoBool}, nil
+		}
+		if _, ok := p.consumir("("); ok {
+			return p.chamada(t)
+		}
+		v, ok := variaveis[t.texto]
+		if !ok {
+			return nil, fmt.Errorf("variável desconhecida %q na posição %d", t.texto, t.pos)
+		}
+		return &refVariavel{v: v}, nil
+
+	case tokOperador:
+		if t.texto == "(" {
+			p.pos++
+			x, err := p.ou()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.esperar(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+	}
+
+	if t.tipo == tokFim {
+		return nil, fmt.Errorf("expressão incompleta")
+	}
+	return nil, fmt.Errorf("token inesperado %q na posição %d", t.texto, t.pos)
+}
+
+func (p *parser) chamada(nome token) (no, error) {
+	fn, ok := funcoes[nome.texto]
+	if !ok {
+		return nil, fmt.Errorf("função desconhecida %q na posição %d", nome.texto, nome.pos)
+	}
+
+	var args []no
+	if _, ok := p.consumir(")"); !ok {
+		for {
+			arg, err := p.ou()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.consumir(","); !ok {
+				break
+			}
+		}
+		if err := p.esperar(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(args) < len(fn.params)-fn.opcionais || len(args) > len(fn.params) {
+		return nil, fmt.Errorf("%s() recebe %d argumento(s), mas recebeu %d", nome.texto, len(fn.params), len(args))
+	}
+
+	for i, arg := range args {
+		if arg.tipo() != fn.params[i] {
+			return nil, fmt.Errorf("argumento %d de %s() deve ser %s", i+1, nome.texto, fn.params[i])
+		}
+
+		// Janelas de tempo precisam ser constantes para que o histórico
+		// necessário seja conhecido antes da avaliação.
+		if fn.params[i] == tipoNumero {
+			lit, ok := arg.(*literal)
+			if !ok {
+				return nil, fmt.Errorf("argumento %d de %s() deve ser um número fixo", i+1, nome.texto)
+			}
+			dias := lit.v.(float64)
+			if dias < 1 || dias > janelaMaxima {
+				return nil, fmt.Errorf("argumento %d de %s() deve estar entre 1 e %d dias", i+1, nome.texto, janelaMaxima)
+			}
+			p.janela = max(p.janela, int(dias))
+		}
+	}
+
+	if len(args) < len(fn.params) {
+		p.janela = max(p.janela, janelaPadrao)
+	}
+
+	return &chamada{fn: fn, args: args}, nil
+}
+
+// --- nós
+
+type (
+	literal struct {
+		v any
+		t tipo
+	}
+
+	refVariavel struct {
+		v variavel
+	}
+
+	chamada struct {
+		fn   funcao
+		args []no
+	}
+
+	unario struct {
+		op string
+		x  no
+	}
+
+	binario struct {
+		op       string
+		esq, dir no
+		t        tipo
+	}
+)
+
+func (n *literal) tipo() tipo               { return n.t }
+func (n *literal) avaliar(*Fatos) any       { return n.v }
+func (n *refVariavel) tipo() tipo           { return n.v.tipo }
+func (n *refVariavel) avaliar(f *Fatos) any { return n.v.valor(f) }
+func (n *chamada) tipo() tipo               { return n.fn.retorno }
+
+func (n *chamada) avaliar(f *Fatos) any {
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.avaliar(f)
+	}
+	return n.fn.chamar(f, args)
+}
+
+func (n *unario) tipo() tipo {
+	if n.op == "!" {
+		return tipoBool
+	}
+	return tipoNumero
+}
+
+func (n *unario) avaliar(f *Fatos) any {
+	if n.op == "!" {
+		return !n.x.avaliar(f).(bool)
+	}
+	return -n.x.avaliar(f).(float64)
+}
+
+func (n *binario) tipo() tipo { return n.t }
+
+func (n *binario) avaliar(f *Fatos) any {
+	switch n.op {
+	case "&&":
+		return n.esq.avaliar(f).(bool) && n.dir.avaliar(f).(bool)
+	case "||":
+		return n.esq.avaliar(f).(bool) || n.dir.avaliar(f).(bool)
+	case "==":
+		return n.esq.avaliar(f) == n.dir.avaliar(f)
+	case "!=":
+		return n.esq.avaliar(f) != n.dir.avaliar(f)
+	}
+
+	a, b := n.esq.avaliar(f).(float64), n.dir.avaliar(f).(float64)
+	switch n.op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	default:
+		if b == 0 {
+			return 0.0
+		}
+		return a / b
+	}
+}
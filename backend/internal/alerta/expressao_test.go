@@ -0,0 +1,160 @@
+package alerta
+
+import "testing"
+
+func TestCompilarExpressaoLimitaJanelas(t *testing.T) {
+	casos := []struct {
+		expressao string
+		valida    bool
+	}{
+		{"gasto(3650) > 0", true},
+		{"compras(1) >= 1", true},
+		{`comprou("Arroz")`, true},
+		{"gasto(100000000) > 0", false},
+		{"compras(0) > 1", false},
+		{`comprou("Arroz", 3651)`, false},
+	}
+
+	for _, c := range casos {
+		_, err := CompilarExpressao(c.expressao)
+		if (err == nil) != c.valida {
+			t.Errorf("CompilarExpressao(%q): erro = %v, esperava válida = %v", c.expressao, err, c.valida)
+		}
+	}
+}
+
+func TestCompilarExpressaoRejeita(t *testing.T) {
+	casos := []struct {
+		expressao string
+		erro      string
+	}{
+		// lexer
+		{"gasto(30) > 1 @ 2", `caractere inválido '@' na posição 14`},
+		{`comprou("Arroz)`, "texto não terminado na posição 8"},
+		// parser
+		{"gasto(30) >", "expressão incompleta"},
+		{"(dia_previsto", `esperado ")" na posição 13`},
+		{"dia_previsto nunca_comprou", `token inesperado "nunca_comprou" na posição 13`},
+		{"1.2.3 > 0", `número inválido "1.2.3" na posição 0`},
+		{"clientes > 1", `variável desconhecida "clientes" na posição 0`},
+		{"media(30) > 1", `função desconhecida "media" na posição 0`},
+		{"comprou()", "comprou() recebe 2 argumento(s), mas recebeu 0"},
+		{`gasto(30, 60) > 1`, "gasto() recebe 1 argumento(s), mas recebeu 2"},
+		// tipos
+		{"comprou(1)", "argumento 1 de comprou() deve ser texto"},
+		{`gasto("x") > 0`, "argumento 1 de gasto() deve ser número"},
+		{"compras(dias_sem_comprar) > 1", "argumento 1 de compras() deve ser um número fixo"},
+		{"gasto(30)", "a expressão deve resultar em booleano, mas resulta em número"},
+		{"dia_previsto == 1", "não é possível comparar booleano com número"},
+		{`"a" < "b"`, `"<" exige operandos numéricos`},
+		{"gasto(30) && dia_previsto", `"&&" exige operandos booleanos`},
+		{"dia_previsto || gasto(30)", `"||" exige operandos booleanos`},
+		{"!gasto(30)", `"!" exige operando booleano`},
+		{"-dia_previsto", `"-" exige operando numérico`},
+		{"dia_previsto + 1 > 0", `"+" exige operandos numéricos`},
+	}
+
+	for _, c := range casos {
+		_, err := CompilarExpressao(c.expressao)
+		if err == nil || err.Error() != c.erro {
+			t.Errorf("CompilarExpressao(%q): erro = %v, esperado %q", c.expressao, err, c.erro)
+		}
+	}
+}
+
+func TestExpressaoJanela(t *testing.T) {
+	casos := map[string]int{
+		"dias_sem_comprar > 10":                 janelaMinima,
+		"gasto(7) > 0":                          janelaMinima,
+		"compras(90) >= 6 && gasto(45) > 0":     90,
+		`comprou("Arroz")`:                      janelaPadrao,
+		`comprou("Arroz", 400) || gasto(1) > 0`: 400,
+	}
+	for fonte, janela := range casos {
+		expr, err := CompilarExpressao(fonte)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expr.Janela() != janela {
+			t.Errorf("janela de %q = %d, esperado %d", fonte, expr.Janela(), janela)
+		}
+	}
+}
+
+// fatosExemplo: hoje é segunda, 19/10/2026; o cliente comprou hoje, há 6,
+// 7, 18 e 19 dias.
+func fatosExemplo() *Fatos {
+	compra := func(dia string, total float64, itens ...string) fatoCompra {
+		return fatoCompra{Data: data(dia), Total: total, Itens: itens}
+	}
+	ultima := data("2026-10-19")
+	return &Fatos{
+		ClienteID:    "c1",
+		Hoje:         data("2026-10-19"),
+		DiaPrevisto:  true,
+		UltimaCompra: &ultima,
+		compras: []fatoCompra{
+			compra("2026-09-30", 160, "arroz"),
+			compra("2026-10-01", 80, "açúcar"),
+			compra("2026-10-12", 40, "café"),
+			compra("2026-10-13", 20, "feijão"),
+			compra("2026-10-19", 10, "arroz"),
+		},
+	}
+}
+
+func TestExpressaoAvaliar(t *testing.T) {
+	casos := []struct {
+		expressao string
+		esperado  bool
+	}{
+		// precedência: ! antes de &&, && antes de ||
+		{"true || false && false", true},
+		{"false && false || true", true},
+		{"(true || false) && false", false},
+		{"!false && false", false},
+		{"!(false && false)", true},
+		{"!!true", true},
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		{"10 - 4 - 3 == 3", true},
+		{"-2 * -3 == 6", true},
+		{"gasto(30) / 0 == 0", true},
+		{`"arroz" == "arroz" && "a" != "b"`, true},
+
+		// janelas de N dias incluem hoje e os N-1 dias anteriores
+		{"gasto(1) == 10", true},
+		{"gasto(7) == 30", true},
+		{"gasto(8) == 70", true},
+		{"compras(7) == 2", true},
+		{"compras(20) == 5", true},
+		{`comprou("Café", 7)`, false},
+		{`comprou("café", 8)`, true},
+		{`comprou("ARROZ", 1)`, true},
+		{`comprou("Açúcar")`, true},
+		{`comprou("Leite")`, false},
+		{"gasto_mes == 150", true},
+
+		{"dias_sem_comprar == 0 && !nunca_comprou", true},
+		{"dia_semana == 1", true},
+		{"dia_previsto", true},
+	}
+
+	f := fatosExemplo()
+	for _, c := range casos {
+		expr, err := CompilarExpressao(c.expressao)
+		if err != nil {
+			t.Errorf("CompilarExpressao(%q): %v", c.expressao, err)
+			continue
+		}
+		if got := expr.Avaliar(f); got != c.esperado {
+			t.Errorf("%s = %v, esperado %v", c.expressao, got, c.esperado)
+		}
+	}
+
+	f.UltimaCompra = nil
+	expr, _ := CompilarExpressao("nunca_comprou && dias_sem_comprar > 3650")
+	if !expr.Avaliar(f) {
+		t.Errorf("cliente que nunca comprou: %s = false", expr)
+	}
+}
@@ -0,0 +1,146 @@
+package alerta
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	// Fatos é o que uma expressão de regra personalizada enxerga de um cliente.
+	Fatos struct {
+		ClienteID    string
+		NomeCliente  string
+		Hoje         time.Time // data corrente, à meia-noite UTC como as compras
+		DiaPrevisto  bool      // hoje está na janela de um dia de compra
+		UltimaCompra *time.Time
+		compras      []fatoCompra // apenas as compras dentro da janela carregada
+	}
+
+	fatoCompra struct {
+		Data  time.Time
+		Total float64
+		Itens []string // nomes em minúsculas
+	}
+)
+
+// CarregarFatos monta os fatos de todos os clientes com as compras dos
+// últimos janela dias.
+func CarregarFatos(ctx Contexto, janela int) ([]*Fatos, error) {
+	hoje, _ := ctx.Hoje()
+
+	clientes, err := ctx.Repositorio.Clientes(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	calendario, err := CarregarCalendario(ctx)
+	if err != nil {
+		return nil, err
+	}
+	previstos, err := carregarPrevisoes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	diaPrevisto := make(map[string]bool)
+	for _, c := range previstos {
+		for _, p := range c.Previsoes {
+			if p.NaJanela(calendario, c.ID, hoje, ctx.Agora.Location()) {
+				diaPrevisto[c.ID] = true
+			}
+		}
+	}
+
+	compras, err := ctx.Repositorio.ComprasDesde(ctx.Ctx, inicioJanela(hoje, janela))
+	if err != nil {
+		return nil, err
+	}
+
+	fatos := make([]*Fatos, 0, len(clientes))
+	porCliente := make(map[string]*Fatos, len(clientes))
+	for _, c := range clientes {
+		f := &Fatos{
+			ClienteID:    c.ID,
+			NomeCliente:  c.Nome,
+			Hoje:         hoje,
+			DiaPrevisto:  diaPrevisto[c.ID],
+			UltimaCompra: c.UltimaCompra,
+		}
+		fatos = append(fatos, f)
+		porCliente[c.ID] = f
+	}
+
+	for _, co := range compras {
+		f, ok := porCliente[co.ClienteID]
+		if !ok {
+			continue
+		}
+		compra := fatoCompra{Data: co.Data}
+		for _, item := range co.Itens {
+			compra.Total += item.Preco
+			compra.Itens = append(compra.Itens, strings.ToLower(item.Nome))
+		}
+		f.compras = append(f.compras, compra)
+	}
+
+	return fatos, nil
+}
+
+// DiasSemComprar devolve há quantos dias o cliente não compra. Clientes que
+// nunca compraram são tratados como inativos há tempo indeterminado.
+func (f *Fatos) DiasSemComprar() int {
+	if f.UltimaCompra == nil {
+		return math.MaxInt32
+	}
+	return int(f.Hoje.Sub(f.UltimaCompra.UTC()).Hours() / 24)
+}
+
+// Gasto soma o valor das compras dos últimos dias dias, incluindo hoje.
+func (f *Fatos) Gasto(dias int) float64 {
+	var total float64
+	for _, c := range f.comprasDesde(inicioJanela(f.Hoje, dias)) {
+		total += c.Total
+	}
+	return total
+}
+
+// GastoMes soma o valor das compras do mês corrente.
+func (f *Fatos) GastoMes() float64 {
+	var total float64
+	for _, c := range f.comprasDesde(time.Date(f.Hoje.Year(), f.Hoje.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		total += c.Total
+	}
+	return total
+}
+
+// Compras conta as compras dos últimos dias dias, incluindo hoje.
+func (f *Fatos) Compras(dias int) int {
+	return len(f.comprasDesde(inicioJanela(f.Hoje, dias)))
+}
+
+// Comprou informa se o item, pelo nome, foi comprado nos últimos dias dias.
+func (f *Fatos) Comprou(item string, dias int) bool {
+	item = strings.ToLower(item)
+	for _, c := range f.comprasDesde(inicioJanela(f.Hoje, dias)) {
+		if slices.Contains(c.Itens, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// inicioJanela devolve o primeiro dia de uma janela de dias dias terminada
+// em hoje: a janela de 1 dia é só hoje.
+func inicioJanela(hoje time.Time, dias int) time.Time {
+	return hoje.AddDate(0, 0, -(dias - 1))
+}
+
+func (f *Fatos) comprasDesde(inicio time.Time) []fatoCompra {
+	for i, c := range f.compras {
+		if !c.Data.Before(inicio) {
+			return f.compras[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package alerta
+
+// clientesPausados devolve os clientes com alertas pausados hoje: os que
+// têm uma interação registrada com pausar_alertas_ate a partir de hoje.
+func clientesPausados(ctx Contexto) (map[string]bool, error) {
+	inicio, _ := ctx.Hoje()
+
+	ids, err := ctx.Repositorio.Pausados(ctx.Ctx, inicio)
+	if err != nil {
+		return nil, err
+	}
+
+	pausados := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		pausados[id] = true
+	}
+	return pausados, nil
+}
+
+// removerPausados tira os alertas dos clientes com alertas pausados.
+func removerPausados(ctx Contexto, alertas []Alerta) ([]Alerta, error) {
+	if len(alertas) == 0 {
+		return alertas, nil
+	}
+
+	pausados, err := clientesPausados(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pausados) == 0 {
+		return alertas, nil
+	}
+
+	ativos := alertas[:0]
+	for _, a := range alertas {
+		if !pausados[a.ClienteID] {
+			ativos = append(ativos, a)
+		}
+	}
+	return ativos, nil
+}
@@ -0,0 +1,98 @@
+package alerta
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"smart-retention/internal/model"
+)
+
+const TipoPersonalizada = "personalizada"
+
+// RegrasPersonalizadas avalia as regras ativas cadastradas pelos usuários.
+type RegrasPersonalizadas struct{}
+
+func (RegrasPersonalizadas) Tipo() string { return TipoPersonalizada }
+
+func (RegrasPersonalizadas) Avaliar(ctx Contexto) ([]Alerta, error) {
+	regras, err := ctx.Repositorio.RegrasAtivas(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(regras) == 0 {
+		return nil, nil
+	}
+
+	type compilada struct {
+		regra model.RegraAlerta
+		expr  *Expressao
+	}
+
+	var compiladas []compilada
+	for _, r := range regras {
+		expr, err := CompilarExpressao(r.Expressao)
+		if err != nil {
+			slog.WarnContext(ctx.Ctx, "ignorando regra com expressão inválida", "regra_id", r.ID, "regra", r.Nome, "erro", err)
+			continue
+		}
+		compiladas = append(compiladas, compilada{regra: r, expr: expr})
+	}
+
+	// Os fatos são carregados uma vez por janela. Sem eles a geração falha
+	// inteira: devolver só os alertas das outras regras faria o histórico e o
+	// hub darem como resolvidos os alertas das regras puladas.
+	porJanela := make(map[int][]*Fatos)
+	var alertas []Alerta
+	for _, c := range compiladas {
+		fatos, ok := porJanela[c.expr.Janela()]
+		if !ok {
+			var err error
+			if fatos, err = CarregarFatos(ctx, c.expr.Janela()); err != nil {
+				return nil, fmt.Errorf("erro ao carregar os fatos da regra %q: %w", c.regra.Nome, err)
+			}
+			porJanela[c.expr.Janela()] = fatos
+		}
+
+		motivo := c.regra.Motivo
+		if motivo == "" {
+			motivo = c.regra.Nome
+		}
+
+		for _, f := range fatos {
+			if c.expr.Avaliar(f) {
+				alertas = append(alertas, Alerta{
+					ClienteID:   f.ClienteID,
+					NomeCliente: f.NomeCliente,
+					Tipo:        TipoPersonalizada,
+					Motivo:      motivo,
+					RegraID:     c.regra.ID,
+				})
+			}
+		}
+	}
+
+	return alertas, nil
+}
+
+// Simular devolve os clientes que hoje satisfazem a expressão, sem gravar
+// nada. Como na geração, os clientes com alertas pausados ficam de fora.
+func (e *Engine) Simular(ctx context.Context, expr *Expressao) ([]*Fatos, error) {
+	contexto := e.contexto(ctx)
+	fatos, err := CarregarFatos(contexto, expr.Janela())
+	if err != nil {
+		return nil, err
+	}
+	pausados, err := clientesPausados(contexto)
+	if err != nil {
+		return nil, err
+	}
+
+	var aceitos []*Fatos
+	for _, f := range fatos {
+		if !pausados[f.ClienteID] && expr.Avaliar(f) {
+			aceitos = append(aceitos, f)
+		}
+	}
+	return aceitos, nil
+}
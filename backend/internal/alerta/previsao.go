@@ -0,0 +1,190 @@
+package alerta
+
+import (
+	"context"
+	"time"
+
+	"smart-retention/internal/model"
+)
+
+// maxDiasBusca limita quantos dias para trás uma ocorrência do dia de compra
+// é procurada: o suficiente para a compra mensal com adiamento e tolerância.
+const maxDiasBusca = 62
+
+type (
+	// Previsao é um dia de compra do cliente (ver model.DiaCompraCliente),
+	// pronto para ser avaliado.
+	Previsao struct {
+		DiaSemana      time.Weekday
+		Frequencia     string
+		Referencia     time.Time // quinzenal: um dia da semana de referência
+		SemanaDoMes    int       // mensal: 1 a 4, ou -1 para a última
+		HoraFim        int       // minutos desde a meia-noite; 0 é o fim do dia
+		ToleranciaDias int
+	}
+
+	// Janela é uma ocorrência do dia de compra.
+	Janela struct {
+		Previsto time.Time // o dia pela frequência
+		Dia      time.Time // o primeiro dia aberto a partir de Previsto
+		Desde    time.Time // compras a partir deste dia contam para a ocorrência
+		Ultimo   time.Time // o último dia tolerado
+		Fecha    time.Time // instante, no fuso do motor, em que a compra passa a estar atrasada
+	}
+
+	clientePrevisto struct {
+		ID        string
+		Nome      string
+		Previsoes []Previsao
+	}
+)
+
+// carregarPrevisoes lê os dias de compra de todos os clientes que têm algum.
+func carregarPrevisoes(ctx Contexto) ([]clientePrevisto, error) {
+	linhas, err := ctx.Repositorio.DiasCompra(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var clientes []clientePrevisto
+	for _, l := range linhas {
+		if n := len(clientes); n == 0 || clientes[n-1].ID != l.ClienteID {
+			clientes = append(clientes, clientePrevisto{ID: l.ClienteID, Nome: l.NomeCliente})
+		}
+		p := Previsao{
+			DiaSemana:      time.Weekday(l.DiaSemana),
+			Frequencia:     l.Frequencia,
+			SemanaDoMes:    l.SemanaDoMes,
+			ToleranciaDias: l.ToleranciaDias,
+		}
+		if l.Referencia != nil {
+			p.Referencia = Dia(l.Referencia.UTC())
+		}
+		if hora, err := time.Parse("15:04", l.HoraFim); err == nil {
+			p.HoraFim = hora.Hour()*60 + hora.Minute()
+		}
+		c := &clientes[len(clientes)-1]
+		c.Previsoes = append(c.Previsoes, p)
+	}
+	return clientes, nil
+}
+
+// ProximaJanela devolve, entre os dias de compra do cliente, a ocorrência
+// mais próxima cuja janela ainda não fechou, ou nil se o cliente não tem
+// dias de compra.
+func (e *Engine) ProximaJanela(ctx context.Context, clienteID string) (*Janela, error) {
+	contexto := e.contexto(ctx)
+	calendario, err := CarregarCalendario(contexto)
+	if err != nil {
+		return nil, err
+	}
+	clientes, err := carregarPrevisoes(contexto)
+	if err != nil {
+		return nil, err
+	}
+
+	var proxima *Janela
+	for _, c := range clientes {
+		if c.ID != clienteID {
+			continue
+		}
+		for _, p := range c.Previsoes {
+			if j, ok := p.Proxima(calendario, c.ID, contexto.Agora); ok && (proxima == nil || j.Fecha.Before(proxima.Fecha)) {
+				proxima = &j
+			}
+		}
+	}
+	return proxima, nil
+}
+
+// Proxima devolve a primeira ocorrência cuja janela ainda não fechou em
+// agora, inclusive a que está em curso.
+func (p Previsao) Proxima(cal *Calendario, clienteID string, agora time.Time) (Janela, bool) {
+	for d, n := Dia(agora).AddDate(0, 0, -maxDiasBusca/2), 0; n < 2*maxDiasBusca; d, n = d.AddDate(0, 0, 1), n+1 {
+		if !p.Ocorre(d) {
+			continue
+		}
+		if j := p.Janela(cal, clienteID, d, agora.Location()); agora.Before(j.Fecha) {
+			return j, true
+		}
+	}
+	return Janela{}, false
+}
+
+// Ocorre informa se, pela frequência, o dia é de compra.
+func (p Previsao) Ocorre(dia time.Time) bool {
+	if dia.Weekday() != p.DiaSemana {
+		return false
+	}
+	switch p.Frequencia {
+	case model.FrequenciaQuinzenal:
+		semanas := diasEntre(inicioSemana(p.Referencia), inicioSemana(dia)) / 7
+		return semanas%2 == 0
+	case model.FrequenciaMensal:
+		if p.SemanaDoMes == -1 {
+			return dia.AddDate(0, 0, 7).Month() != dia.Month()
+		}
+		return (dia.Day()-1)/7+1 == p.SemanaDoMes
+	}
+	return true
+}
+
+// Janela monta a ocorrência prevista para o dia. Feriados e fechamentos
+// adiam o dia e não contam na tolerância; a janela fecha em HoraFim do
+// último dia tolerado ou, sem horário, ao fim dele.
+func (p Previsao) Janela(cal *Calendario, clienteID string, previsto time.Time, loc *time.Location) Janela {
+	dia := cal.ProximoAberto(clienteID, previsto)
+	ultimo := dia
+	for n := 0; n < p.ToleranciaDias; n++ {
+		ultimo = cal.ProximoAberto(clienteID, ultimo.AddDate(0, 0, 1))
+	}
+
+	fecha := time.Date(ultimo.Year(), ultimo.Month(), ultimo.Day(), 0, p.HoraFim, 0, 0, loc)
+	if p.HoraFim == 0 {
+		fecha = fecha.AddDate(0, 0, 1)
+	}
+	return Janela{
+		Previsto: previsto,
+		Dia:      dia,
+		Desde:    previsto.AddDate(0, 0, -p.ToleranciaDias),
+		Ultimo:   ultimo,
+		Fecha:    fecha,
+	}
+}
+
+// UltimaFechada devolve a ocorrência mais recente cuja janela já fechou em
+// agora. As que ainda estão em aberto são puladas: com tolerância ou
+// adiamento, a janela de uma ocorrência pode fechar só depois de a
+// seguinte começar.
+func (p Previsao) UltimaFechada(cal *Calendario, clienteID string, agora time.Time) (Janela, bool) {
+	hoje := Dia(agora)
+	for d, n := hoje, 0; n < maxDiasBusca; d, n = d.AddDate(0, 0, -1), n+1 {
+		if !p.Ocorre(d) {
+			continue
+		}
+		if j := p.Janela(cal, clienteID, d, agora.Location()); !agora.Before(j.Fecha) {
+			return j, true
+		}
+	}
+	return Janela{}, false
+}
+
+// NaJanela informa se uma compra no dia conta para alguma ocorrência: se
+// o dia está entre Desde e o último dia tolerado.
+func (p Previsao) NaJanela(cal *Calendario, clienteID string, dia time.Time, loc *time.Location) bool {
+	for d, n := dia.AddDate(0, 0, p.ToleranciaDias), 0; n < maxDiasBusca; d, n = d.AddDate(0, 0, -1), n+1 {
+		if !p.Ocorre(d) {
+			continue
+		}
+		j := p.Janela(cal, clienteID, d, loc)
+		if !dia.Before(j.Desde) && !dia.After(j.Ultimo) {
+			return true
+		}
+	}
+	return false
+}
+
+// inicioSemana devolve o domingo da semana do dia.
+func inicioSemana(dia time.Time) time.Time {
+	return dia.AddDate(0, 0, -int(dia.Weekday()))
+}
@@ -0,0 +1,59 @@
+package alerta
+
+import (
+	"testing"
+	"time"
+
+	"smart-retention/internal/model"
+)
+
+func TestUltimaFechadaPulaOcorrenciaEmAberto(t *testing.T) {
+	casos := []struct {
+		nome     string
+		feriado  string
+		previsao Previsao
+		agora    time.Time
+		previsto time.Time
+	}{
+		{
+			nome:     "tolerância de uma semana",
+			previsao: Previsao{DiaSemana: time.Monday, Frequencia: model.FrequenciaSemanal, ToleranciaDias: 6},
+			agora:    data("2026-10-19").Add(10 * time.Hour),
+			previsto: data("2026-10-12"),
+		},
+		{
+			// Com a terça 13/10 fechada, a janela daquela ocorrência vai
+			// até a segunda 19/10 em vez do domingo
+			nome:     "adiamento por feriado",
+			feriado:  "2026-10-13",
+			previsao: Previsao{DiaSemana: time.Tuesday, Frequencia: model.FrequenciaSemanal, ToleranciaDias: 5},
+			agora:    data("2026-10-19").Add(10 * time.Hour),
+			previsto: data("2026-10-06"),
+		},
+		{
+			nome:     "sem tolerância",
+			previsao: Previsao{DiaSemana: time.Monday, Frequencia: model.FrequenciaSemanal},
+			agora:    data("2026-10-19").Add(10 * time.Hour),
+			previsto: data("2026-10-12"),
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cal := novoCalendario()
+			cal.anos[2026] = true
+			if c.feriado != "" {
+				cal.feriados[data(c.feriado)] = true
+			}
+			j, ok := c.previsao.UltimaFechada(cal, "cliente", c.agora)
+			if !ok {
+				t.Fatal("nenhuma ocorrência fechada")
+			}
+			if !j.Previsto.Equal(c.previsto) {
+				t.Errorf("previsto = %s, esperado %s", j.Previsto.Format(time.DateOnly), c.previsto.Format(time.DateOnly))
+			}
+			if c.agora.Before(j.Fecha) {
+				t.Errorf("janela ainda aberta: fecha em %s", j.Fecha)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package alerta
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+type Severidade string
+
+const (
+	SeveridadeBaixa   Severidade = "baixa"
+	SeveridadeMedia   Severidade = "media"
+	SeveridadeAlta    Severidade = "alta"
+	SeveridadeCritica Severidade = "critica"
+)
+
+// janelaValor é o período, em dias, usado para medir o valor do cliente.
+const janelaValor = 90
+
+var niveis = []Severidade{SeveridadeBaixa, SeveridadeMedia, SeveridadeAlta, SeveridadeCritica}
+
+var severidadePorTipo = map[string]Severidade{
+	TipoCompraIncompleta: SeveridadeBaixa,
+	TipoDiaPrevisto:      SeveridadeMedia,
+	TipoItemFaltando:     SeveridadeMedia,
+	TipoPersonalizada:    SeveridadeMedia,
+	TipoInatividade:      SeveridadeAlta,
+}
+
+// Incidente agrupa todos os alertas de um mesmo cliente.
+type Incidente struct {
+	ClienteID    string     `json:"cliente_id"`
+	NomeCliente  string     `json:"nome_cliente"`
+	Severidade   Severidade `json:"severidade"`
+	Prioridade   float64    `json:"prioridade"`
+	ValorCliente float64    `json:"valor_cliente"`
+	Alertas      []Alerta   `json:"alertas"`
+}
+
+func nivel(s Severidade) int {
+	return slices.Index(niveis, s)
+}
+
+// deduplicar remove alertas repetidos do mesmo tipo (e regra) para o cliente.
+func deduplicar(alertas []Alerta) []Alerta {
+	vistos := make(map[string]bool, len(alertas))
+	unicos := alertas[:0]
+	for _, a := range alertas {
+		chave := a.Chave()
+		if vistos[chave] {
+			continue
+		}
+		vistos[chave] = true
+		unicos = append(unicos, a)
+	}
+	return unicos
+}
+
+// priorizar calcula severidade e prioridade de cada alerta e os ordena do mais
+// para o menos prioritário.
+//
+// A severidade parte do tipo do alerta e sobe um nível com 14 dias de atraso,
+// dois com 30, e mais um se o cliente estiver entre os 25% de maior valor.
+// A prioridade combina severidade, atraso (limitado a 60 dias) e o valor
+// gasto pelo cliente nos últimos 90 dias em escala logarítmica.
+func priorizar(ctx Contexto, alertas []Alerta) ([]Alerta, error) {
+	if len(alertas) == 0 {
+		return alertas, nil
+	}
+
+	inicio, _ := ctx.Hoje()
+	valores, err := ctx.Repositorio.ValorPorCliente(ctx.Ctx, inicio.AddDate(0, 0, -janelaValor))
+	if err != nil {
+		return nil, err
+	}
+	corte := percentil(valores, 0.75)
+
+	representantes, err := ctx.Repositorio.Representantes(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range alertas {
+		a := &alertas[i]
+		a.ValorCliente = valores[a.ClienteID]
+		a.Representante = representantes[a.ClienteID]
+
+		n := nivel(severidadePorTipo[a.Tipo])
+		if n < 0 {
+			n = nivel(SeveridadeMedia)
+		}
+		switch {
+		case a.DiasAtraso >= 30:
+			n += 2
+		case a.DiasAtraso >= 14:
+			n++
+		}
+		if a.ValorCliente > 0 && a.ValorCliente >= corte {
+			n++
+		}
+		n = min(n, len(niveis)-1)
+
+		a.Severidade = niveis[n]
+		a.Prioridade = arredondar(float64(n+1)*25 +
+			float64(min(max(a.DiasAtraso, 0), 60))*0.5 +
+			10*math.Log10(1+a.ValorCliente))
+	}
+
+	slices.SortStableFunc(alertas, func(a, b Alerta) int {
+		return cmp.Compare(b.Prioridade, a.Prioridade)
+	})
+
+	return alertas, nil
+}
+
+// Agrupar junta os alertas por cliente em incidentes, ordenados por
+// prioridade. Cada alerta adicional reforça a prioridade do incidente.
+func Agrupar(alertas []Alerta) []Incidente {
+	var incidentes []Incidente
+	indice := map[string]int{}
+
+	for _, a := range alertas {
+		i, ok := indice[a.ClienteID]
+		if !ok {
+			indice[a.ClienteID] = len(incidentes)
+			incidentes = append(incidentes, Incidente{
+				ClienteID:    a.ClienteID,
+				NomeCliente:  a.NomeCliente,
+				ValorCliente: a.ValorCliente,
+			})
+			i = len(incidentes) - 1
+		}
+
+		inc := &incidentes[i]
+		inc.Alertas = append(inc.Alertas, a)
+		if nivel(a.Severidade) > nivel(inc.Severidade) {
+			inc.Severidade = a.Severidade
+		}
+		inc.Prioridade = max(inc.Prioridade, a.Prioridade)
+	}
+
+	for i := range incidentes {
+		incidentes[i].Prioridade = arredondar(incidentes[i].Prioridade + 5*float64(len(incidentes[i].Alertas)-1))
+	}
+
+	slices.SortStableFunc(incidentes, func(a, b Incidente) int {
+		return cmp.Compare(b.Prioridade, a.Prioridade)
+	})
+
+	return incidentes
+}
+
+func percentil(valores map[string]float64, p float64) float64 {
+	if len(valores) == 0 {
+		return math.Inf(1)
+	}
+	lista := make([]float64, 0, len(valores))
+	for _, v := range valores {
+		lista = append(lista, v)
+	}
+	slices.Sort(lista)
+	return lista[int(p*float64(len(lista)-1))]
+}
+
+func arredondar(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,252 @@
+package alerta
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// DiaPrevisto alerta quando a janela de um dia de compra do cliente
+	// fechou sem compra: passado o horário esperado do último dia de
+	// tolerância (ver Previsao.Janela). O alerta segue até a compra ou até
+	// fechar a janela da ocorrência seguinte daquele dia de compra.
+	DiaPrevisto struct{}
+
+	// CompraIncompleta alerta quando o cliente comprou hoje, dentro da janela
+	// de um dia de compra, mas deixou de fora algum dos seus itens
+	// recorrentes.
+	CompraIncompleta struct{}
+
+	// Inatividade alerta quando o cliente não compra há mais de Dias dias,
+	// sem contar feriados e fechamentos. Durante um fechamento o cliente não
+	// é alertado.
+	Inatividade struct {
+		Dias int
+	}
+
+	// ItemFaltando alerta quando algum item recorrente do cliente não é
+	// comprado há mais de Dias dias. Com Sugestoes, o alerta traz também
+	// itens que clientes parecidos compram, para oferecer no contato.
+	ItemFaltando struct {
+		Dias      int
+		Sugestoes Sugeridor
+	}
+)
+
+func (DiaPrevisto) Tipo() string { return TipoDiaPrevisto }
+
+func (DiaPrevisto) Avaliar(ctx Contexto) ([]Alerta, error) {
+	hoje, fim := ctx.Hoje()
+	calendario, err := CarregarCalendario(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientes, err := carregarPrevisoes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// De cada cliente, a ocorrência mais recente cuja janela já fechou
+	var previstos []clientePrevisto
+	janelas := make(map[string]Janela)
+	for _, c := range clientes {
+		if calendario.Fechado(c.ID, hoje) {
+			continue
+		}
+		for _, p := range c.Previsoes {
+			j, ok := p.UltimaFechada(calendario, c.ID, ctx.Agora)
+			if !ok {
+				continue
+			}
+			if atual, visto := janelas[c.ID]; !visto {
+				previstos = append(previstos, c)
+			} else if !j.Previsto.After(atual.Previsto) {
+				continue
+			}
+			janelas[c.ID] = j
+		}
+	}
+	if len(previstos) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(previstos))
+	for i, c := range previstos {
+		ids[i] = c.ID
+	}
+	ultimas, err := ctx.Repositorio.UltimasCompras(ctx.Ctx, ids, fim)
+	if err != nil {
+		return nil, err
+	}
+	for id, ultima := range ultimas {
+		if !ultima.Before(janelas[id].Desde) {
+			delete(janelas, id)
+		}
+	}
+
+	var alertas []Alerta
+	for _, cliente := range previstos {
+		j, pendente := janelas[cliente.ID]
+		if !pendente {
+			continue
+		}
+		motivo := fmt.Sprintf("O cliente não comprou no dia previsto (%s).", j.Dia.Format("02/01"))
+		if !j.Dia.Equal(j.Previsto) {
+			motivo = fmt.Sprintf("O dia previsto (%s) caiu em feriado ou fechamento e o cliente não comprou no dia %s.",
+				j.Previsto.Format("02/01"), j.Dia.Format("02/01"))
+		}
+		alertas = append(alertas, Alerta{
+			ClienteID:   cliente.ID,
+			NomeCliente: cliente.Nome,
+			Tipo:        TipoDiaPrevisto,
+			Motivo:      motivo,
+			DiasAtraso:  int(ctx.Agora.Sub(j.Fecha).Hours() / 24),
+		})
+	}
+
+	return alertas, nil
+}
+
+func (CompraIncompleta) Tipo() string { return TipoCompraIncompleta }
+
+func (CompraIncompleta) Avaliar(ctx Contexto) ([]Alerta, error) {
+	inicio, fim := ctx.Hoje()
+	calendario, err := CarregarCalendario(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientes, err := carregarPrevisoes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Só a compra feita na janela de um dia de compra precisa estar completa
+	var ids []string
+	for _, c := range clientes {
+		for _, p := range c.Previsoes {
+			if p.NaJanela(calendario, c.ID, inicio, ctx.Agora.Location()) {
+				ids = append(ids, c.ID)
+				break
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	itens, err := ctx.Repositorio.ItensNaoComprados(ctx.Ctx, ids, inicio, fim)
+	if err != nil {
+		return nil, err
+	}
+
+	return agruparItens(itens, TipoCompraIncompleta, "Itens não comprados na compra de hoje", false), nil
+}
+
+func (Inatividade) Tipo() string { return TipoInatividade }
+
+func (r Inatividade) Avaliar(ctx Contexto) ([]Alerta, error) {
+	inicio, _ := ctx.Hoje()
+
+	clientes, err := ctx.Repositorio.Clientes(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	calendario, err := CarregarCalendario(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limite := inicio.AddDate(0, 0, -r.Dias)
+	var alertas []Alerta
+	for _, cliente := range clientes {
+		if cliente.UltimaCompra != nil && !cliente.UltimaCompra.Before(limite) {
+			continue
+		}
+		// Cliente que avisou estar fechado não está inativo
+		if calendario.FechadoPeloCliente(cliente.ID, inicio) {
+			continue
+		}
+		a := Alerta{
+			ClienteID:   cliente.ID,
+			NomeCliente: cliente.Nome,
+			Tipo:        TipoInatividade,
+			Motivo:      fmt.Sprintf("Cliente não compra há mais de %d dias.", r.Dias),
+		}
+		if cliente.UltimaCompra != nil {
+			// Feriados e fechamentos não contam como dias sem comprar
+			ultima := *cliente.UltimaCompra
+			a.DiasAtraso = diasEntre(ultima, inicio) - calendario.DiasFechados(cliente.ID, ultima, inicio) - r.Dias
+			if a.DiasAtraso <= 0 {
+				continue
+			}
+		}
+		alertas = append(alertas, a)
+	}
+
+	return alertas, nil
+}
+
+// sugestoesPorAlerta limita as sugestões levadas em cada alerta de item
+// faltando.
+const sugestoesPorAlerta = 3
+
+func (ItemFaltando) Tipo() string { return TipoItemFaltando }
+
+func (r ItemFaltando) Avaliar(ctx Contexto) ([]Alerta, error) {
+	itens, err := ctx.Repositorio.ItensSemCompra(ctx.Ctx, ctx.Agora.AddDate(0, 0, -r.Dias))
+	if err != nil {
+		return nil, err
+	}
+
+	alertas := agruparItens(itens, TipoItemFaltando, "Cliente deixou de comprar itens recorrentes.", true)
+
+	// O atraso do alerta é o do item há mais tempo sem ser comprado
+	for i := range alertas {
+		for _, item := range alertas[i].ItensDetalhados {
+			if !item.UltimaCompra.IsZero() {
+				alertas[i].DiasAtraso = max(alertas[i].DiasAtraso, diasEntre(item.UltimaCompra, ctx.Agora)-r.Dias)
+			}
+		}
+	}
+
+	if r.Sugestoes != nil {
+		for i := range alertas {
+			alertas[i].Sugestoes = r.Sugestoes.Sugerir(alertas[i].ClienteID, sugestoesPorAlerta)
+		}
+	}
+
+	return alertas, nil
+}
+
+// diasEntre conta os dias inteiros entre duas datas.
+func diasEntre(de, ate time.Time) int {
+	return int(ate.Sub(de).Hours() / 24)
+}
+
+// agruparItens junta em um único alerta por cliente as linhas de itens
+// ordenadas por cliente.
+func agruparItens(itens []ItemCliente, tipo, motivo string, detalhar bool) []Alerta {
+	var alertas []Alerta
+	for _, item := range itens {
+		n := len(alertas)
+		if n == 0 || alertas[n-1].ClienteID != item.ClienteID {
+			alertas = append(alertas, Alerta{
+				ClienteID:   item.ClienteID,
+				NomeCliente: item.NomeCliente,
+				Tipo:        tipo,
+				Motivo:      motivo,
+			})
+			n++
+		}
+
+		alertas[n-1].ItensFaltantes = append(alertas[n-1].ItensFaltantes, item.NomeItem)
+		if detalhar {
+			detalhe := ItemDetalhado{Nome: item.NomeItem}
+			if item.UltimaCompra != nil {
+				detalhe.UltimaCompra = *item.UltimaCompra
+			}
+			alertas[n-1].ItensDetalhados = append(alertas[n-1].ItensDetalhados, detalhe)
+		}
+	}
+	return alertas
+}
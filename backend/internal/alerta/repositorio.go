@@ -0,0 +1,101 @@
+package alerta
+
+import (
+	"context"
+	"time"
+
+	"smart-retention/internal/model"
+)
+
+type (
+	// Repositorio é o acesso aos dados de que o motor precisa, implementado
+	// sobre o banco no pacote repository. As datas de compra, de feriado e de
+	// fechamento são datas à meia-noite UTC, como gravadas.
+	Repositorio interface {
+		// Feriados devolve as datas dos feriados cadastrados; os nacionais
+		// são calculados pelo Calendario.
+		Feriados(ctx context.Context) ([]time.Time, error)
+		Fechamentos(ctx context.Context) ([]Fechamento, error)
+		// FechamFacultativos devolve os clientes que fecham nos pontos
+		// facultativos.
+		FechamFacultativos(ctx context.Context) ([]string, error)
+		// DiasCompra devolve os dias de compra de todos os clientes,
+		// ordenados por cliente e dia da semana.
+		DiasCompra(ctx context.Context) ([]DiaCompra, error)
+		// Clientes devolve todos os clientes, pelo nome, com a data da
+		// última compra.
+		Clientes(ctx context.Context) ([]ClienteCompra, error)
+		// UltimasCompras devolve, dos clientes em ids que compraram antes
+		// de ate, a data da última dessas compras.
+		UltimasCompras(ctx context.Context, ids []string, ate time.Time) (map[string]time.Time, error)
+		// ComprasDesde devolve as compras a partir de desde, com os itens,
+		// ordenadas por cliente e data.
+		ComprasDesde(ctx context.Context, desde time.Time) ([]Compra, error)
+		// ItensNaoComprados devolve os itens recorrentes que ficaram de fora
+		// das compras de [inicio, fim) dos clientes em ids que compraram no
+		// período, ordenados por cliente e item.
+		ItensNaoComprados(ctx context.Context, ids []string, inicio, fim time.Time) ([]ItemCliente, error)
+		// ItensSemCompra devolve os itens recorrentes de todos os clientes
+		// nunca comprados ou comprados pela última vez antes de antes,
+		// ordenados por cliente e item.
+		ItensSemCompra(ctx context.Context, antes time.Time) ([]ItemCliente, error)
+		// Pausados devolve os clientes com os alertas pausados no dia.
+		Pausados(ctx context.Context, dia time.Time) ([]string, error)
+		// ValorPorCliente soma, por cliente, o valor das compras a partir de
+		// desde.
+		ValorPorCliente(ctx context.Context, desde time.Time) (map[string]float64, error)
+		// Representantes devolve o representante dos clientes que têm um.
+		Representantes(ctx context.Context) (map[string]string, error)
+		// RegrasAtivas devolve as regras personalizadas ativas, pelo nome.
+		RegrasAtivas(ctx context.Context) ([]model.RegraAlerta, error)
+	}
+
+	// Fechamento é um período, com os dois dias inclusive, em que o cliente
+	// avisou que estaria fechado.
+	Fechamento struct {
+		ClienteID string
+		Inicio    time.Time
+		Fim       time.Time
+	}
+
+	// DiaCompra é um dia de compra do cliente como gravado (ver
+	// model.DiaCompraCliente).
+	DiaCompra struct {
+		ClienteID      string
+		NomeCliente    string
+		DiaSemana      int
+		Frequencia     string
+		Referencia     *time.Time
+		SemanaDoMes    int
+		HoraFim        string
+		ToleranciaDias int
+	}
+
+	ClienteCompra struct {
+		ID           string
+		Nome         string
+		UltimaCompra *time.Time
+	}
+
+	// Compra é uma compra com o nome e o valor de cada item.
+	Compra struct {
+		ID        string
+		ClienteID string
+		Data      time.Time
+		Itens     []ItemComprado
+	}
+
+	ItemComprado struct {
+		Nome  string
+		Preco float64
+	}
+
+	// ItemCliente é um item recorrente do cliente, com a sua última compra
+	// quando ela é pedida.
+	ItemCliente struct {
+		ClienteID    string
+		NomeCliente  string
+		NomeItem     string
+		UltimaCompra *time.Time
+	}
+)
@@ -0,0 +1,67 @@
+// Package calendario calcula os feriados nacionais brasileiros, inclusive
+// os móveis, que dependem da data da Páscoa.
+package calendario
+
+import (
+	"sort"
+	"time"
+)
+
+// Feriado é uma data, à meia-noite UTC como as datas de compra. Facultativo
+// marca os pontos facultativos nacionais (carnaval, Corpus Christi), em que o
+// comércio costuma fechar, mas não é obrigado.
+type Feriado struct {
+	Data        time.Time
+	Nome        string
+	Facultativo bool
+}
+
+// Nacionais devolve os feriados nacionais do ano, em ordem de data.
+func Nacionais(ano int) []Feriado {
+	fixo := func(mes time.Month, dia int, nome string) Feriado {
+		return Feriado{Data: time.Date(ano, mes, dia, 0, 0, 0, 0, time.UTC), Nome: nome}
+	}
+	pascoa := Pascoa(ano)
+	movel := func(dias int, nome string, facultativo bool) Feriado {
+		return Feriado{Data: pascoa.AddDate(0, 0, dias), Nome: nome, Facultativo: facultativo}
+	}
+
+	feriados := []Feriado{
+		fixo(time.January, 1, "Confraternização Universal"),
+		movel(-48, "Carnaval", true),
+		movel(-47, "Carnaval", true),
+		movel(-2, "Sexta-feira Santa", false),
+		fixo(time.April, 21, "Tiradentes"),
+		fixo(time.May, 1, "Dia do Trabalho"),
+		movel(60, "Corpus Christi", true),
+		fixo(time.September, 7, "Independência do Brasil"),
+		fixo(time.October, 12, "Nossa Senhora Aparecida"),
+		fixo(time.November, 2, "Finados"),
+		fixo(time.November, 15, "Proclamação da República"),
+		fixo(time.December, 25, "Natal"),
+	}
+	// Feriado nacional desde a Lei 14.759/2023
+	if ano >= 2024 {
+		feriados = append(feriados, fixo(time.November, 20, "Dia Nacional de Zumbi e da Consciência Negra"))
+	}
+
+	sort.Slice(feriados, func(i, j int) bool { return feriados[i].Data.Before(feriados[j].Data) })
+	return feriados
+}
+
+// Pascoa devolve o domingo de Páscoa do ano no calendário gregoriano, pelo
+// algoritmo de Meeus/Jones/Butcher.
+func Pascoa(ano int) time.Time {
+	a := ano % 19
+	b, c := ano/100, ano%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	mes := (h + l - 7*m + 114) / 31
+	dia := (h+l-7*m+114)%31 + 1
+	return time.Date(ano, time.Month(mes), dia, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,89 @@
+package calendario
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func data(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestPascoa(t *testing.T) {
+	for ano, esperado := range map[int]string{
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25", // a mais tardia do século
+	} {
+		if obtido := Pascoa(ano); !obtido.Equal(data(esperado)) {
+			t.Errorf("Páscoa de %d = %s, esperado %s", ano, obtido.Format(time.DateOnly), esperado)
+		}
+	}
+}
+
+func TestNacionaisMoveis(t *testing.T) {
+	casos := []struct {
+		ano                          int
+		carnaval, sextaSanta, corpus string
+	}{
+		// Carnaval na segunda e na terça, 48 e 47 dias antes da Páscoa;
+		// Sexta-feira Santa 2 dias antes; Corpus Christi 60 dias depois
+		{2024, "2024-02-12", "2024-03-29", "2024-05-30"},
+		{2025, "2025-03-03", "2025-04-18", "2025-06-19"},
+		{2026, "2026-02-16", "2026-04-03", "2026-06-04"},
+		{2038, "2038-03-08", "2038-04-23", "2038-06-24"},
+	}
+	for _, c := range casos {
+		esperados := []Feriado{
+			{Data: data(c.carnaval), Nome: "Carnaval", Facultativo: true},
+			{Data: data(c.carnaval).AddDate(0, 0, 1), Nome: "Carnaval", Facultativo: true},
+			{Data: data(c.sextaSanta), Nome: "Sexta-feira Santa"},
+			{Data: data(c.corpus), Nome: "Corpus Christi", Facultativo: true},
+		}
+		feriados := Nacionais(c.ano)
+		for _, e := range esperados {
+			if !slices.ContainsFunc(feriados, func(f Feriado) bool { return f == e }) {
+				t.Errorf("%d: falta %s em %s", c.ano, e.Nome, e.Data.Format(time.DateOnly))
+			}
+		}
+	}
+}
+
+func TestNacionaisConscienciaNegra(t *testing.T) {
+	casos := []struct {
+		ano      int
+		feriados int
+		presente bool
+	}{
+		{2023, 12, false},
+		{2024, 13, true},
+		{2026, 13, true},
+	}
+	for _, c := range casos {
+		feriados := Nacionais(c.ano)
+		if len(feriados) != c.feriados {
+			t.Errorf("%d: %d feriados, esperado %d", c.ano, len(feriados), c.feriados)
+		}
+		dia := time.Date(c.ano, time.November, 20, 0, 0, 0, 0, time.UTC)
+		presente := slices.ContainsFunc(feriados, func(f Feriado) bool { return f.Data.Equal(dia) })
+		if presente != c.presente {
+			t.Errorf("%d: 20 de novembro presente = %v, esperado %v", c.ano, presente, c.presente)
+		}
+	}
+}
+
+func TestNacionaisEmOrdemDeData(t *testing.T) {
+	feriados := Nacionais(2026)
+	if !slices.IsSortedFunc(feriados, func(a, b Feriado) int { return a.Data.Compare(b.Data) }) {
+		t.Errorf("feriados fora de ordem: %+v", feriados)
+	}
+	if feriados[0].Nome != "Confraternização Universal" || feriados[len(feriados)-1].Nome != "Natal" {
+		t.Errorf("primeiro %q e último %q", feriados[0].Nome, feriados[len(feriados)-1].Nome)
+	}
+}
@@ -0,0 +1,258 @@
+// Package config carrega a configuração da aplicação. Os valores vêm, do
+// menos para o mais prioritário, dos padrões abaixo, de um arquivo .env, das
+// variáveis de ambiente e das flags de linha de comando.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/robfig/cron/v3"
+)
+
+type (
+	Config struct {
+		Ambiente     string
+		Endereco     string        // host:porta do servidor HTTP
+		Encerramento time.Duration // prazo para o graceful shutdown
+		CORSOrigens  []string
+		Local        *time.Location // fuso usado para decidir o "hoje" dos alertas
+		Banco        Banco
+		Alertas      Alertas
+		Logs         Logs
+		Rastreio     Rastreio
+	}
+
+	Logs struct {
+		Nivel slog.Level
+	}
+
+	// Rastreio configura a exportação de spans; sem Endpoint eles não são
+	// exportados.
+	Rastreio struct {
+		Endpoint string // URL base do coletor OTLP/HTTP, ex.: http://localhost:4318
+		Servico  string
+	}
+
+	Banco struct {
+		Host    string
+		Porta   int
+		Usuario string
+		Senha   string
+		Nome    string
+		SSLMode string
+	}
+
+	Alertas struct {
+		Intervalo        time.Duration // entre as publicações de alertas no hub
+		DiasInatividade  int
+		DiasItemFaltando int
+		CronDiario       string // verificação diária dos alertas do dia
+		CronLimpeza      string // limpeza da fila de eventos do hub
+		CronTarefas      string // lembrete das tarefas vencidas
+		CronSugestoes    string // recálculo do modelo de sugestões de itens
+	}
+
+	// opcao descreve uma configuração: a variável de ambiente, que também é
+	// a chave no arquivo, a flag correspondente e o valor padrão.
+	opcao struct {
+		env    string
+		flag   string
+		padrao string
+		ajuda  string
+	}
+)
+
+var opcoes = []opcao{
+	{env: "APP_ENV", flag: "env", padrao: "development", ajuda: "ambiente (development, production)"},
+	{env: "HTTP_ADDR", flag: "addr", padrao: "0.0.0.0:8080", ajuda: "endereço do servidor HTTP"},
+	{env: "SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", padrao: "25s", ajuda: "prazo para encerrar conexões e jobs ao receber SIGTERM"},
+	{env: "CORS_ORIGINS", flag: "cors-origins", padrao: "*", ajuda: "origens permitidas, separadas por vírgula"},
+	{env: "APP_TIMEZONE", flag: "timezone", padrao: "America/Sao_Paulo", ajuda: "fuso horário dos alertas e das agendas CRON_*"},
+	{env: "LOG_LEVEL", flag: "log-level", padrao: "info", ajuda: "nível mínimo de log (debug, info, warn, error)"},
+	{env: "OTEL_EXPORTER_OTLP_ENDPOINT", flag: "otlp-endpoint", ajuda: "coletor OTLP/HTTP para os traces (vazio desativa)"},
+	{env: "OTEL_SERVICE_NAME", flag: "service-name", padrao: "smart-retention", ajuda: "nome do serviço nos traces"},
+	{env: "DB_HOST", flag: "db-host", ajuda: "host do Postgres"},
+	{env: "DB_PORT", flag: "db-port", padrao: "5432", ajuda: "porta do Postgres"},
+	{env: "DB_USER", flag: "db-user", ajuda: "usuário do Postgres"},
+	{env: "DB_PASSWORD", flag: "db-password", ajuda: "senha do Postgres"},
+	{env: "DB_NAME", flag: "db-name", ajuda: "nome do banco"},
+	{env: "DB_SSLMODE", flag: "db-sslmode", padrao: "disable", ajuda: "sslmode da conexão"},
+	{env: "ALERTAS_INTERVALO", flag: "alertas-intervalo", padrao: "10s", ajuda: "intervalo entre as publicações de alertas"},
+	{env: "ALERTAS_DIAS_INATIVIDADE", flag: "alertas-dias-inatividade", padrao: "7", ajuda: "dias sem compra para o alerta de inatividade"},
+	{env: "ALERTAS_DIAS_ITEM_FALTANDO", flag: "alertas-dias-item-faltando", padrao: "14", ajuda: "dias sem um item recorrente para o alerta de item faltando"},
+	{env: "CRON_ALERTA_DIARIO", flag: "cron-alerta-diario", padrao: "0 8 * * *", ajuda: "agenda da verificação diária de alertas"},
+	{env: "CRON_LIMPEZA_EVENTOS", flag: "cron-limpeza-eventos", padrao: "0 * * * *", ajuda: "agenda da limpeza da fila de eventos"},
+	{env: "CRON_LEMBRETE_TAREFAS", flag: "cron-lembrete-tarefas", padrao: "0 9 * * *", ajuda: "agenda do lembrete das tarefas vencidas"},
+	{env: "CRON_SUGESTOES", flag: "cron-sugestoes", padrao: "0 3 * * *", ajuda: "agenda do recálculo das sugestões de itens"},
+}
+
+// Carregar monta a configuração a partir de args (sem o nome do programa) e
+// do ambiente. O arquivo lido é o indicado por -config ou CONFIG_FILE; sem
+// nenhum dos dois, .env.development é lido se existir e o ambiente for de
+// desenvolvimento. Devolve também os argumentos que sobraram após as flags.
+func Carregar(args []string) (*Config, []string, error) {
+	fs := flag.NewFlagSet("smart-retention", flag.ContinueOnError)
+	arquivo := fs.String("config", os.Getenv("CONFIG_FILE"), "arquivo .env com a configuração")
+	for _, o := range opcoes {
+		fs.String(o.flag, "", o.ajuda)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	valores := make(map[string]string, len(opcoes))
+	for _, o := range opcoes {
+		valores[o.env] = o.padrao
+	}
+
+	if *arquivo == "" {
+		if amb := os.Getenv("APP_ENV"); amb == "" || amb == "development" {
+			if _, err := os.Stat(".env.development"); err == nil {
+				*arquivo = ".env.development"
+			}
+		}
+	}
+	if *arquivo != "" {
+		lidos, err := godotenv.Read(*arquivo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("lendo %s: %w", *arquivo, err)
+		}
+		for chave, v := range lidos {
+			if _, ok := valores[chave]; ok {
+				valores[chave] = v
+			}
+		}
+	}
+
+	for _, o := range opcoes {
+		if v, ok := os.LookupEnv(o.env); ok {
+			valores[o.env] = v
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		for _, o := range opcoes {
+			if o.flag == f.Name {
+				valores[o.env] = f.Value.String()
+			}
+		}
+	})
+
+	cfg, err := montar(valores)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// montar converte e valida os valores, reunindo todos os erros encontrados.
+func montar(v map[string]string) (*Config, error) {
+	var erros []error
+	inteiro := func(chave string, minimo int) int {
+		n, err := strconv.Atoi(strings.TrimSpace(v[chave]))
+		if err != nil || n < minimo {
+			erros = append(erros, fmt.Errorf("%s deve ser um inteiro maior ou igual a %d", chave, minimo))
+		}
+		return n
+	}
+	obrigatorio := func(chave string) string {
+		if strings.TrimSpace(v[chave]) == "" {
+			erros = append(erros, fmt.Errorf("%s é obrigatório", chave))
+		}
+		return v[chave]
+	}
+	agenda := func(chave string) string {
+		if _, err := cron.ParseStandard(v[chave]); err != nil {
+			erros = append(erros, fmt.Errorf("%s inválido: %w", chave, err))
+		}
+		return v[chave]
+	}
+
+	cfg := &Config{
+		Ambiente:    v["APP_ENV"],
+		Endereco:    obrigatorio("HTTP_ADDR"),
+		CORSOrigens: lista(v["CORS_ORIGINS"]),
+		Banco: Banco{
+			Host:    obrigatorio("DB_HOST"),
+			Porta:   inteiro("DB_PORT", 1),
+			Usuario: obrigatorio("DB_USER"),
+			Senha:   obrigatorio("DB_PASSWORD"),
+			Nome:    obrigatorio("DB_NAME"),
+			SSLMode: obrigatorio("DB_SSLMODE"),
+		},
+		Alertas: Alertas{
+			DiasInatividade:  inteiro("ALERTAS_DIAS_INATIVIDADE", 1),
+			DiasItemFaltando: inteiro("ALERTAS_DIAS_ITEM_FALTANDO", 1),
+			CronDiario:       agenda("CRON_ALERTA_DIARIO"),
+			CronLimpeza:      agenda("CRON_LIMPEZA_EVENTOS"),
+			CronTarefas:      agenda("CRON_LEMBRETE_TAREFAS"),
+			CronSugestoes:    agenda("CRON_SUGESTOES"),
+		},
+		Rastreio: Rastreio{
+			Endpoint: strings.TrimSpace(v["OTEL_EXPORTER_OTLP_ENDPOINT"]),
+			Servico:  obrigatorio("OTEL_SERVICE_NAME"),
+		},
+	}
+
+	if err := cfg.Logs.Nivel.UnmarshalText([]byte(v["LOG_LEVEL"])); err != nil {
+		erros = append(erros, fmt.Errorf("LOG_LEVEL inválido: %q", v["LOG_LEVEL"]))
+	}
+
+	if len(cfg.CORSOrigens) == 0 {
+		erros = append(erros, errors.New("CORS_ORIGINS precisa de ao menos uma origem"))
+	}
+
+	local, err := time.LoadLocation(v["APP_TIMEZONE"])
+	if err != nil {
+		erros = append(erros, fmt.Errorf("APP_TIMEZONE inválido: %w", err))
+	}
+	cfg.Local = local
+
+	intervalo, err := time.ParseDuration(v["ALERTAS_INTERVALO"])
+	if err != nil || intervalo <= 0 {
+		erros = append(erros, errors.New("ALERTAS_INTERVALO deve ser uma duração positiva (ex.: 10s)"))
+	}
+	cfg.Alertas.Intervalo = intervalo
+
+	encerramento, err := time.ParseDuration(v["SHUTDOWN_TIMEOUT"])
+	if err != nil || encerramento <= 0 {
+		erros = append(erros, errors.New("SHUTDOWN_TIMEOUT deve ser uma duração positiva (ex.: 25s)"))
+	}
+	cfg.Encerramento = encerramento
+
+	if err := errors.Join(erros...); err != nil {
+		return nil, fmt.Errorf("configuração inválida:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// DSN monta a string de conexão do Postgres.
+func (b Banco) DSN() string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		b.Host,
+		b.Usuario,
+		b.Senha,
+		b.Nome,
+		b.Porta,
+		b.SSLMode,
+	)
+}
+
+func lista(v string) []string {
+	var itens []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			itens = append(itens, s)
+		}
+	}
+	return itens
+}
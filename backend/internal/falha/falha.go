@@ -0,0 +1,107 @@
+// Package falha define os erros da aplicação: um código estável, a mensagem
+// que pode ser mostrada ao usuário, os campos envolvidos e o status HTTP
+// correspondente. Repositórios e serviços devolvem *Erro; o handler os
+// transforma em respostas application/problem+json (RFC 7807).
+package falha
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Codigo identifica o tipo do erro para quem consome a API.
+type Codigo string
+
+const (
+	CodigoRequisicaoInvalida Codigo = "requisicao_invalida" // corpo ou parâmetros malformados
+	CodigoValidacao          Codigo = "validacao"           // dados bem formados, mas inválidos
+	CodigoNaoEncontrado      Codigo = "nao_encontrado"
+	CodigoConflito           Codigo = "conflito"            // viola uma restrição de unicidade
+	CodigoReferenciaInvalida Codigo = "referencia_invalida" // aponta para um registro inexistente
+	CodigoEmUso              Codigo = "em_uso"              // registro ainda referenciado por outros
+	CodigoInterno            Codigo = "erro_interno"
+)
+
+var status = map[Codigo]int{
+	CodigoRequisicaoInvalida: http.StatusBadRequest,
+	CodigoValidacao:          http.StatusUnprocessableEntity,
+	CodigoNaoEncontrado:      http.StatusNotFound,
+	CodigoConflito:           http.StatusConflict,
+	CodigoReferenciaInvalida: http.StatusUnprocessableEntity,
+	CodigoEmUso:              http.StatusConflict,
+	CodigoInterno:            http.StatusInternalServerError,
+}
+
+type (
+	Erro struct {
+		Codigo   Codigo
+		Mensagem string
+		Campos   []Campo
+		Causa    error // só para logs; nunca vai para a resposta
+	}
+
+	// Campo detalha o problema de um campo da requisição.
+	Campo struct {
+		Nome     string `json:"campo"`
+		Mensagem string `json:"mensagem"`
+	}
+)
+
+func Novo(codigo Codigo, mensagem string, campos ...Campo) *Erro {
+	return &Erro{Codigo: codigo, Mensagem: mensagem, Campos: campos}
+}
+
+func RequisicaoInvalida(mensagem string, campos ...Campo) *Erro {
+	return Novo(CodigoRequisicaoInvalida, mensagem, campos...)
+}
+
+func Validacao(mensagem string, campos ...Campo) *Erro {
+	return Novo(CodigoValidacao, mensagem, campos...)
+}
+
+func NaoEncontrado(mensagem string) *Erro {
+	return Novo(CodigoNaoEncontrado, mensagem)
+}
+
+func Conflito(mensagem string, campos ...Campo) *Erro {
+	return Novo(CodigoConflito, mensagem, campos...)
+}
+
+// Interno embrulha um erro inesperado. A mensagem é genérica para não vazar
+// detalhes do banco ou do código.
+func Interno(causa error) *Erro {
+	return &Erro{Codigo: CodigoInterno, Mensagem: "Erro interno do servidor", Causa: causa}
+}
+
+func (e *Erro) Error() string {
+	if e.Causa != nil {
+		return e.Mensagem + ": " + e.Causa.Error()
+	}
+	return e.Mensagem
+}
+
+func (e *Erro) Unwrap() error {
+	return e.Causa
+}
+
+// Status é o status HTTP do erro.
+func (e *Erro) Status() int {
+	if s, ok := status[e.Codigo]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// De devolve o *Erro contido em err, ou um erro interno que o embrulha.
+func De(err error) *Erro {
+	var e *Erro
+	if errors.As(err, &e) {
+		return e
+	}
+	return Interno(err)
+}
+
+// CodigoDe devolve o código de err; erros que não são *Erro são internos.
+func CodigoDe(err error) Codigo {
+	return De(err).Codigo
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/repository"
+)
+
+type AnalyticsAlertasResponse struct {
+	Desde            time.Time                       `json:"desde"`
+	Ate              time.Time                       `json:"ate"`
+	PorTipo          []repository.EfetividadeAlertas `json:"por_tipo"`
+	PorRepresentante []repository.EfetividadeAlertas `json:"por_representante"`
+}
+
+// AnalyticsAlertas mostra, por tipo de alerta e por representante, quantos
+// clientes voltaram a comprar depois de alertados. Aceita ?desde= e ?ate= no
+// formato AAAA-MM-DD; por padrão considera os últimos 90 dias.
+func (h *Handler) AnalyticsAlertas(c *gin.Context) {
+	ate := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	desde := ate.AddDate(0, 0, -90)
+
+	if v := c.Query("desde"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.Error(falha.RequisicaoInvalida("Parâmetro desde inválido",
+				falha.Campo{Nome: "desde", Mensagem: "use o formato AAAA-MM-DD"}))
+			return
+		}
+		desde = d
+	}
+	if v := c.Query("ate"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.Error(falha.RequisicaoInvalida("Parâmetro ate inválido",
+				falha.Campo{Nome: "ate", Mensagem: "use o formato AAAA-MM-DD"}))
+			return
+		}
+		ate = d.AddDate(0, 0, 1)
+	}
+
+	efetividade, err := h.alertas.Efetividade(c.Request.Context(), desde, ate)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AnalyticsAlertasResponse{
+		Desde:            desde,
+		Ate:              ate.AddDate(0, 0, -1),
+		PorTipo:          efetividade.PorTipo,
+		PorRepresentante: efetividade.PorRepresentante,
+	})
+}
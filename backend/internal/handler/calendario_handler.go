@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+)
+
+type (
+	// FeriadoInput cadastra um feriado que vale para todos os clientes; a
+	// data é AAAA-MM-DD.
+	FeriadoInput struct {
+		Data string `json:"data" binding:"required"`
+		Nome string `json:"nome" binding:"required"`
+	}
+
+	// FeriadoResponse descreve um feriado do calendário. Os nacionais são
+	// calculados e não têm ID.
+	FeriadoResponse struct {
+		ID          string    `json:"id,omitempty"`
+		Data        time.Time `json:"data"`
+		Nome        string    `json:"nome"`
+		Nacional    bool      `json:"nacional"`
+		Facultativo bool      `json:"facultativo"`
+	}
+
+	// FechamentoInput informa um período, AAAA-MM-DD a AAAA-MM-DD inclusive,
+	// em que o cliente estará fechado.
+	FechamentoInput struct {
+		Inicio string `json:"inicio" binding:"required"`
+		Fim    string `json:"fim" binding:"required"`
+		Motivo string `json:"motivo"`
+	}
+
+	FechamentoResponse struct {
+		ID       string    `json:"id"`
+		Inicio   time.Time `json:"inicio"`
+		Fim      time.Time `json:"fim"`
+		Motivo   string    `json:"motivo"`
+		CriadoEm time.Time `json:"criado_em"`
+	}
+)
+
+// ListarFeriados aceita ?ano=; por padrão, o ano corrente.
+func (h *Handler) ListarFeriados(c *gin.Context) {
+	var ano int
+	if v := c.Query("ano"); v != "" {
+		var err error
+		if ano, err = strconv.Atoi(v); err != nil || ano < 1583 { // calendário gregoriano
+			c.Error(falha.RequisicaoInvalida("Parâmetro ano inválido",
+				falha.Campo{Nome: "ano", Mensagem: "use um ano como 2026"}))
+			return
+		}
+	}
+
+	feriados, err := h.calendario.ListarFeriados(c.Request.Context(), ano)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]FeriadoResponse, 0, len(feriados))
+	for _, f := range feriados {
+		response = append(response, FeriadoResponse{
+			ID:          f.ID,
+			Data:        f.Data,
+			Nome:        f.Nome,
+			Nacional:    f.Nacional,
+			Facultativo: f.Facultativo,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) CriarFeriado(c *gin.Context) {
+	var input FeriadoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+	data, err := lerData("data", input.Data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	feriado, err := h.calendario.CriarFeriado(c.Request.Context(), model.Feriado{Data: data, Nome: input.Nome})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, FeriadoResponse{ID: feriado.ID, Data: feriado.Data, Nome: feriado.Nome})
+}
+
+func (h *Handler) DeletarFeriado(c *gin.Context) {
+	if err := h.calendario.DeletarFeriado(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListarFechamentos(c *gin.Context) {
+	fechamentos, err := h.calendario.ListarFechamentos(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]FechamentoResponse, 0, len(fechamentos))
+	for i := range fechamentos {
+		response = append(response, novoFechamentoResponse(&fechamentos[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) CriarFechamento(c *gin.Context) {
+	var input FechamentoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+	inicio, err := lerData("inicio", input.Inicio)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	fim, err := lerData("fim", input.Fim)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	fechamento, err := h.calendario.CriarFechamento(c.Request.Context(), c.Param("id"), model.Fechamento{
+		Inicio: inicio,
+		Fim:    fim,
+		Motivo: input.Motivo,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, novoFechamentoResponse(fechamento))
+}
+
+func (h *Handler) DeletarFechamento(c *gin.Context) {
+	if err := h.calendario.DeletarFechamento(c.Request.Context(), c.Param("id"), c.Param("fechamento_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func novoFechamentoResponse(f *model.Fechamento) FechamentoResponse {
+	return FechamentoResponse{
+		ID:       f.ID,
+		Inicio:   f.Inicio.UTC(),
+		Fim:      f.Fim.UTC(),
+		Motivo:   f.Motivo,
+		CriadoEm: f.CriadoEm,
+	}
+}
@@ -1,241 +1,346 @@
 package handler
 
 import (
-	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"fmt"
 	"net/http"
-	"smart-retention/internal/model"
-	"smart-retention/internal/ws"
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/model"
+	"smart-retention/internal/service"
 )
 
 type (
 	Handler struct {
-		db  *gorm.DB
-		hub *ws.Hub
+		clientes       *service.ClienteService
+		compras        *service.CompraService
+		relacionamento *service.RelacionamentoService
+		tarefas        *service.TarefaService
+		calendario     *service.CalendarioService
+		pedidos        *service.PedidoService
+		sugestoes      *service.SugestaoService
+		alertas        *service.AlertaService
+		regras         *service.RegraService
+		dashboard      *service.DashboardService
 	}
 
+	// ClienteInput é o corpo de criação e de atualização; a atualização
+	// substitui todos os dados, inclusive itens e dias de compra.
 	ClienteInput struct {
-		CNPJ       string                   `json:"cnpj" binding:"required"`
-		Nome       string                   `json:"nome" binding:"required"`
-		Telefone   string                   `json:"telefone" binding:"required"`
-		Email      string                   `json:"email"`
-		Endereco   string                   `json:"endereco" binding:"required"`
-		Itens      []model.Item             `json:"itens"`
-		DiasCompra []model.DiaCompraCliente `json:"dias_compra"`
+		CNPJ          string `json:"cnpj" binding:"required"`
+		Nome          string `json:"nome" binding:"required"`
+		Telefone      string `json:"telefone" binding:"required"`
+		Email         string `json:"email"`
+		Endereco      string `json:"endereco" binding:"required"`
+		Representante string `json:"representante"`
+		// FechaFacultativos faz os pontos facultativos nacionais contarem
+		// como dias fechados do cliente.
+		FechaFacultativos bool        `json:"fecha_facultativos"`
+		Itens             []ItemInput `json:"itens" binding:"dive"`
+		DiasCompra        []DiaCompra `json:"dias_compra" binding:"dive"`
+	}
+
+	// ItemInput referencia um item existente pelo id ou cadastra um novo
+	// pelo nome.
+	ItemInput struct {
+		ID   string `json:"id"`
+		Nome string `json:"nome" binding:"required_without=ID"`
+	}
+
+	// DiaCompra é usado na entrada e na saída. 0 é domingo. Sem frequência,
+	// a compra é semanal; a quinzenal pede a referência (AAAA-MM-DD, um dia
+	// de uma semana de compra) e a mensal, a semana do mês (-1 é a última).
+	// Horários são HH:MM.
+	DiaCompra struct {
+		DiaSemana      int    `json:"dia_semana" binding:"min=0,max=6"`
+		Frequencia     string `json:"frequencia" binding:"omitempty,oneof=semanal quinzenal mensal"`
+		Referencia     string `json:"referencia,omitempty"`
+		SemanaDoMes    int    `json:"semana_do_mes,omitempty" binding:"omitempty,oneof=-1 1 2 3 4"`
+		HoraFim        string `json:"hora_fim,omitempty" binding:"omitempty,datetime=15:04"`
+		ToleranciaDias int    `json:"tolerancia_dias" binding:"min=0,max=6"`
+	}
+
+	ClienteResponse struct {
+		ID                string         `json:"id"`
+		CNPJ              string         `json:"cnpj"`
+		Nome              string         `json:"nome"`
+		Telefone          string         `json:"telefone"`
+		Email             string         `json:"email"`
+		Endereco          string         `json:"endereco"`
+		Representante     string         `json:"representante"`
+		FechaFacultativos bool           `json:"fecha_facultativos"`
+		Itens             []ItemResponse `json:"itens"`
+		DiasCompra        []DiaCompra    `json:"dias_compra"`
+	}
+
+	ItemResponse struct {
+		ID   string `json:"id"`
+		Nome string `json:"nome"`
+	}
+
+	// HistoricoResponse traz as compras em historico e, em linha_do_tempo,
+	// as compras intercaladas com as interações. Ambas vão da mais recente
+	// para a mais antiga.
+	HistoricoResponse struct {
+		Cliente      ClienteResumoResponse     `json:"cliente"`
+		Contatos     []ContatoResponse         `json:"contatos"`
+		Historico    []CompraHistoricoResponse `json:"historico"`
+		LinhaDoTempo []EventoLinhaDoTempo      `json:"linha_do_tempo"`
+	}
+
+	ClienteResumoResponse struct {
+		Nome               string     `json:"nome"`
+		CNPJ               string     `json:"cnpj"`
+		Telefone           string     `json:"telefone"`
+		Endereco           string     `json:"endereco"`
+		Representante      string     `json:"representante"`
+		AlertasPausadosAte *time.Time `json:"alertas_pausados_ate"`
+	}
+
+	// EventoLinhaDoTempo é uma compra ou uma interação; só o campo do tipo
+	// correspondente vem preenchido.
+	EventoLinhaDoTempo struct {
+		Tipo      string                   `json:"tipo"` // "compra" ou "interacao"
+		Data      time.Time                `json:"data"`
+		Compra    *CompraHistoricoResponse `json:"compra,omitempty"`
+		Interacao *InteracaoResponse       `json:"interacao,omitempty"`
+	}
+
+	CompraHistoricoResponse struct {
+		Data  time.Time            `json:"data"`
+		Itens []CompraItemResponse `json:"itens"`
 	}
 )
 
-func NewHandler(db *gorm.DB, hub *ws.Hub) *Handler {
+func NewHandler(s service.Servicos) *Handler {
+	configurarValidador()
 	return &Handler{
-		db:  db,
-		hub: hub,
+		clientes:       s.Clientes,
+		compras:        s.Compras,
+		relacionamento: s.Relacionamento,
+		tarefas:        s.Tarefas,
+		calendario:     s.Calendario,
+		pedidos:        s.Pedidos,
+		sugestoes:      s.Sugestoes,
+		alertas:        s.Alertas,
+		regras:         s.Regras,
+		dashboard:      s.Dashboard,
 	}
 }
 
 func (h *Handler) ListarClientes(c *gin.Context) {
-	var clientes []model.Cliente
+	clientes, err := h.clientes.Listar(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	if err := h.db.Preload("Itens").Preload("DiasCompra").Find(&clientes).Error; err != nil {
-		c.JSON(500, gin.H{"error": "Erro ao listar clientes"})
+	if legado(c) {
+		response := make([]clienteLegado, 0, len(clientes))
+		for i := range clientes {
+			response = append(response, novoClienteLegado(&clientes[i]))
+		}
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
-	c.JSON(http.StatusOK, clientes)
+	response := make([]ClienteResponse, 0, len(clientes))
+	for i := range clientes {
+		response = append(response, novoClienteResponse(&clientes[i]))
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *Handler) CriarCliente(c *gin.Context) {
 	var input ClienteInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		c.Error(entradaInvalida(err))
 		return
 	}
-
-	cliente := model.Cliente{
-		CNPJ:     input.CNPJ,
-		Nome:     input.Nome,
-		Telefone: input.Telefone,
-		Email:    input.Email,
-		Endereco: input.Endereco,
-		Itens:    input.Itens,
-	}
-
-	// Cria cliente com itens
-	if err := h.db.Create(&cliente).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
+	dados, err := input.cliente()
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Remove dias de compra anteriores (por segurança, mesmo em criação inicial)
-	if err := h.db.Where("cliente_id = ?", cliente.ID).Delete(&model.DiaCompraCliente{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
+	cliente, err := h.clientes.Criar(c.Request.Context(), dados)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Insere dias de compra com cliente_id correto
-	for i := range input.DiasCompra {
-		input.DiasCompra[i].ClienteID = cliente.ID
-	}
-	if len(input.DiasCompra) > 0 {
-		if err := h.db.Create(&input.DiasCompra).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
-			return
-		}
-	}
-
-	// Retorna cliente com preload
-	if err := h.db.Preload("Itens").Preload("DiasCompra").First(&cliente, "id = ?", cliente.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
+	if legado(c) {
+		c.JSON(http.StatusCreated, novoClienteLegado(cliente))
 		return
 	}
-
-	c.JSON(http.StatusCreated, cliente)
+	c.JSON(http.StatusCreated, novoClienteResponse(cliente))
 }
 
 func (h *Handler) HistoricoCliente(c *gin.Context) {
-	clienteID := c.Param("id")
-
-	var cliente model.Cliente
-	if err := h.db.First(&cliente, "id = ?", clienteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+	historico, err := h.clientes.Historico(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	var compras []model.Compra
-	h.db.Preload("Itens.Item").Where("cliente_id = ?", clienteID).Order("data_compra DESC").Find(&compras)
-
-	type ItemCompra struct {
-		Nome  string  `json:"nome"`
-		Preco float64 `json:"preco"`
-	}
-	type CompraDTO struct {
-		Data  time.Time    `json:"data"`
-		Itens []ItemCompra `json:"itens"`
+	if legado(c) {
+		c.JSON(http.StatusOK, novoHistoricoLegado(historico))
+		return
 	}
 
-	var historico []CompraDTO
-	for _, compra := range compras {
-		var itens []ItemCompra
-		for _, ci := range compra.Itens {
-			itens = append(itens, ItemCompra{
-				Nome:  ci.Item.Nome,
-				Preco: ci.Preco,
-			})
-		}
-		historico = append(historico, CompraDTO{
+	compras := make([]CompraHistoricoResponse, 0, len(historico.Compras))
+	for _, compra := range historico.Compras {
+		compras = append(compras, CompraHistoricoResponse{
 			Data:  compra.DataCompra,
-			Itens: itens,
+			Itens: novosItensCompraResponse(compra.Itens),
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"cliente": gin.H{
-			"nome":     cliente.Nome,
-			"cnpj":     cliente.CNPJ,
-			"telefone": cliente.Telefone,
-			"endereco": cliente.Endereco,
+	cliente := historico.Cliente
+	c.JSON(http.StatusOK, HistoricoResponse{
+		Cliente: ClienteResumoResponse{
+			Nome:               cliente.Nome,
+			CNPJ:               cliente.CNPJ,
+			Telefone:           cliente.Telefone,
+			Endereco:           cliente.Endereco,
+			Representante:      cliente.Representante,
+			AlertasPausadosAte: historico.AlertasPausadosAte,
 		},
-		"historico": historico,
+		Contatos:     novosContatosResponse(historico.Contatos),
+		Historico:    compras,
+		LinhaDoTempo: intercalar(compras, historico.Interacoes),
 	})
 }
 
-func (h *Handler) AtualizarCliente(c *gin.Context) {
-	clienteID := c.Param("id")
+// intercalar junta compras e interações, ambas já da mais recente para a
+// mais antiga, mantendo essa ordem. No mesmo instante a compra vem antes.
+func intercalar(compras []CompraHistoricoResponse, interacoes []model.Interacao) []EventoLinhaDoTempo {
+	eventos := make([]EventoLinhaDoTempo, 0, len(compras)+len(interacoes))
+	for len(compras) > 0 || len(interacoes) > 0 {
+		if len(interacoes) == 0 || (len(compras) > 0 && !compras[0].Data.Before(interacoes[0].Data)) {
+			eventos = append(eventos, EventoLinhaDoTempo{Tipo: "compra", Data: compras[0].Data, Compra: &compras[0]})
+			compras = compras[1:]
+			continue
+		}
+		interacao := novaInteracaoResponse(&interacoes[0])
+		eventos = append(eventos, EventoLinhaDoTempo{Tipo: "interacao", Data: interacao.Data, Interacao: &interacao})
+		interacoes = interacoes[1:]
+	}
+	return eventos
+}
 
-	var input model.Cliente
+func (h *Handler) AtualizarCliente(c *gin.Context) {
+	var input ClienteInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		c.Error(entradaInvalida(err))
 		return
 	}
-
-	// Busca cliente original
-	var cliente model.Cliente
-	if err := h.db.Preload("Itens").Preload("DiasCompra").First(&cliente, "id = ?", clienteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+	dados, err := input.cliente()
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Atualiza campos simples
-	cliente.Nome = input.Nome
-	cliente.CNPJ = input.CNPJ
-	cliente.Telefone = input.Telefone
-	cliente.Email = input.Email
-	cliente.Endereco = input.Endereco
-
-	// Atualiza campos simples no banco
-	if err := h.db.Model(&cliente).Select("Nome", "CNPJ", "Telefone", "Email", "Endereco").Updates(cliente).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar cliente"})
+	cliente, err := h.clientes.Atualizar(c.Request.Context(), c.Param("id"), dados)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// 🔁 Atualiza relação muitos-para-muitos: cliente_itens
-	if err := h.db.Model(&cliente).Association("Itens").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao limpar itens do cliente"})
+	if legado(c) {
+		c.JSON(http.StatusOK, gin.H{"mensagem": "Cliente atualizado com sucesso"})
 		return
 	}
-	if len(input.Itens) > 0 {
-		if err := h.db.Model(&cliente).Association("Itens").Replace(input.Itens); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar itens do cliente"})
-			return
-		}
-	}
+	c.JSON(http.StatusOK, novoClienteResponse(cliente))
+}
 
-	// 🔁 Atualiza dias de compra (relacionamento composto)
-	if err := h.db.Where("cliente_id = ?", clienteID).Delete(&model.DiaCompraCliente{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao apagar dias de compra antigos"})
+func (h *Handler) DeletarCliente(c *gin.Context) {
+	if err := h.clientes.Deletar(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
 		return
 	}
-	if len(input.DiasCompra) > 0 {
-		for i := range input.DiasCompra {
-			input.DiasCompra[i].ClienteID = clienteID
-		}
-		if err := h.db.Create(&input.DiasCompra).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao salvar novos dias de compra"})
-			return
-		}
-	}
 
-	c.JSON(http.StatusOK, gin.H{"mensagem": "Cliente atualizado com sucesso"})
+	c.Status(http.StatusNoContent)
 }
 
-func (h *Handler) DeletarCliente(c *gin.Context) {
-	clienteID := c.Param("id")
-
-	var cliente model.Cliente
-	if err := h.db.Preload("Itens").Preload("DiasCompra").First(&cliente, "id = ?", clienteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+func (h *Handler) BuscarClientePeloID(c *gin.Context) {
+	cliente, err := h.clientes.BuscarPorID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Remove associação many2many explicitamente (cliente_itens)
-	if err := h.db.Model(&cliente).Association("Itens").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao limpar itens associados"})
+	if legado(c) {
+		c.JSON(http.StatusOK, novoClienteLegado(cliente))
 		return
 	}
+	c.JSON(http.StatusOK, novoClienteResponse(cliente))
+}
 
-	// DiasCompra tem OnDelete:CASCADE, mas pode ser limpo explicitamente se quiser mais controle
-	if err := h.db.Where("cliente_id = ?", cliente.ID).Delete(&model.DiaCompraCliente{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover dias de compra"})
-		return
+func (in ClienteInput) cliente() (model.Cliente, error) {
+	itens := make([]model.Item, 0, len(in.Itens))
+	for _, item := range in.Itens {
+		itens = append(itens, model.Item{ID: item.ID, Nome: item.Nome})
 	}
-
-	// Remove o cliente
-	if err := h.db.Delete(&cliente).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao excluir cliente"})
-		return
+	dias := make([]model.DiaCompraCliente, 0, len(in.DiasCompra))
+	for i, dia := range in.DiasCompra {
+		referencia, err := lerDataOpcional(fmt.Sprintf("dias_compra[%d].referencia", i), dia.Referencia)
+		if err != nil {
+			return model.Cliente{}, err
+		}
+		dias = append(dias, model.DiaCompraCliente{
+			DiaSemana:      dia.DiaSemana,
+			Frequencia:     dia.Frequencia,
+			Referencia:     referencia,
+			SemanaDoMes:    dia.SemanaDoMes,
+			HoraFim:        dia.HoraFim,
+			ToleranciaDias: dia.ToleranciaDias,
+		})
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	return model.Cliente{
+		CNPJ:              in.CNPJ,
+		Nome:              in.Nome,
+		Telefone:          in.Telefone,
+		Email:             in.Email,
+		Endereco:          in.Endereco,
+		Representante:     in.Representante,
+		FechaFacultativos: in.FechaFacultativos,
+		Itens:             itens,
+		DiasCompra:        dias,
+	}, nil
 }
 
-func (h *Handler) BuscarClientePeloID(c *gin.Context) {
-	clienteID := c.Param("id")
-
-	var cliente model.Cliente
-	if err := h.db.Preload("Itens").Preload("DiasCompra").First(&cliente, "id = ?", clienteID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
-		return
+func novoClienteResponse(c *model.Cliente) ClienteResponse {
+	itens := make([]ItemResponse, 0, len(c.Itens))
+	for _, item := range c.Itens {
+		itens = append(itens, ItemResponse{ID: item.ID, Nome: item.Nome})
+	}
+	dias := make([]DiaCompra, 0, len(c.DiasCompra))
+	for _, dia := range c.DiasCompra {
+		d := DiaCompra{
+			DiaSemana:      dia.DiaSemana,
+			Frequencia:     dia.Frequencia,
+			SemanaDoMes:    dia.SemanaDoMes,
+			HoraFim:        dia.HoraFim,
+			ToleranciaDias: dia.ToleranciaDias,
+		}
+		if dia.Referencia != nil {
+			d.Referencia = dia.Referencia.UTC().Format("2006-01-02")
+		}
+		dias = append(dias, d)
 	}
 
-	c.JSON(http.StatusOK, cliente)
+	return ClienteResponse{
+		ID:                c.ID,
+		CNPJ:              c.CNPJ,
+		Nome:              c.Nome,
+		Telefone:          c.Telefone,
+		Email:             c.Email,
+		Endereco:          c.Endereco,
+		Representante:     c.Representante,
+		FechaFacultativos: c.FechaFacultativos,
+		Itens:             itens,
+		DiasCompra:        dias,
+	}
 }
@@ -5,30 +5,39 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"smart-retention/internal/falha"
 	"smart-retention/internal/model"
 )
 
 type (
 	CompraInput struct {
-		ClienteID string            `json:"cliente_id"`
-		Data      string            `json:"data"`
-		Itens     []CompraItemInput `json:"itens"`
+		ClienteID string            `json:"cliente_id" binding:"required"`
+		Data      string            `json:"data" binding:"required"`
+		Itens     []CompraItemInput `json:"itens" binding:"dive"`
 	}
 
+	// CompraItemInput é uma linha da compra: Preco é o valor da linha e,
+	// sem quantidade, a linha tem uma unidade.
 	CompraItemInput struct {
-		ItemID string  `json:"item_id"`
-		Preco  float64 `json:"preco"`
+		ItemID     string  `json:"item_id" binding:"required"`
+		Quantidade float64 `json:"quantidade" binding:"omitempty,gt=0"`
+		Preco      float64 `json:"preco" binding:"min=0"`
 	}
 
 	CompraResponse struct {
-		ClienteID   string         `json:"cliente_id"`
-		NomeCliente string         `json:"nome_cliente"`
-		Data        time.Time      `json:"data"`
-		Itens       []ItemResponse `json:"itens"`
+		ID          string               `json:"id"`
+		ClienteID   string               `json:"cliente_id"`
+		NomeCliente string               `json:"nome_cliente"`
+		Data        time.Time            `json:"data"`
+		Total       float64              `json:"total"`
+		Itens       []CompraItemResponse `json:"itens"`
 	}
 
-	ItemResponse struct {
-		Nome string `json:"nome"`
+	CompraItemResponse struct {
+		ItemID     string  `json:"item_id"`
+		Nome       string  `json:"nome"`
+		Quantidade float64 `json:"quantidade"`
+		Preco      float64 `json:"preco"`
 	}
 )
 
@@ -36,67 +45,95 @@ func (h *Handler) CriarCompra(c *gin.Context) {
 	var input CompraInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		c.Error(entradaInvalida(err))
 		return
 	}
 
-	data, err := time.Parse("2006-01-02", input.Data)
+	data, err := lerData("data", input.Data)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"erro": "data inválida"})
-
+		c.Error(err)
 		return
 	}
 
-	compra := model.Compra{
-		ClienteID:  input.ClienteID,
-		DataCompra: data,
+	itens := make([]model.CompraItem, 0, len(input.Itens))
+	for _, item := range input.Itens {
+		quantidade := item.Quantidade
+		if quantidade == 0 {
+			quantidade = 1
+		}
+		itens = append(itens, model.CompraItem{ItemID: item.ItemID, Quantidade: quantidade, Preco: item.Preco})
 	}
 
-	tx := h.db.Begin()
-	if err := tx.Create(&compra).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
+	compra, err := h.compras.Criar(c.Request.Context(), input.ClienteID, data, itens)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	for _, item := range input.Itens {
-		compraItem := model.CompraItem{
-			CompraID: compra.ID,
-			ItemID:   item.ItemID,
-			Preco:    item.Preco,
-		}
-		if err := tx.Create(&compraItem).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
-			return
-		}
+	if legado(c) {
+		c.JSON(http.StatusCreated, novaCompraCriadaLegada(compra))
+		return
 	}
-
-	tx.Commit()
-	c.JSON(http.StatusCreated, compra)
+	c.JSON(http.StatusCreated, novaCompraResponse(compra))
 }
 
 func (h *Handler) ListarCompras(c *gin.Context) {
-	var compras []model.Compra
+	compras, err := h.compras.Listar(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	if err := h.db.Preload("Cliente").Preload("Itens.Item").Find(&compras).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
+	if legado(c) {
+		c.JSON(http.StatusOK, novasComprasLegadas(compras))
 		return
 	}
 
-	var response []CompraResponse
-	for _, compra := range compras {
-		var itens []ItemResponse
-		for _, ci := range compra.Itens {
-			itens = append(itens, ItemResponse{Nome: ci.Item.Nome})
-		}
-		response = append(response, CompraResponse{
-			ClienteID:   compra.ClienteID,
-			NomeCliente: compra.Cliente.Nome,
-			Data:        compra.DataCompra,
-			Itens:       itens,
-		})
+	response := make([]CompraResponse, 0, len(compras))
+	for i := range compras {
+		response = append(response, novaCompraResponse(&compras[i]))
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// novaCompraResponse espera a compra com o cliente e os itens carregados.
+func novaCompraResponse(compra *model.Compra) CompraResponse {
+	var total float64
+	for _, item := range compra.Itens {
+		total += item.Preco
+	}
+
+	return CompraResponse{
+		ID:          compra.ID,
+		ClienteID:   compra.ClienteID,
+		NomeCliente: compra.Cliente.Nome,
+		Data:        compra.DataCompra,
+		Total:       total,
+		Itens:       novosItensCompraResponse(compra.Itens),
+	}
+}
+
+func novosItensCompraResponse(itens []model.CompraItem) []CompraItemResponse {
+	response := make([]CompraItemResponse, 0, len(itens))
+	for _, ci := range itens {
+		response = append(response, CompraItemResponse{
+			ItemID:     ci.ItemID,
+			Nome:       ci.Item.Nome,
+			Quantidade: ci.Quantidade,
+			Preco:      ci.Preco,
+		})
+	}
+	return response
+}
+
+// lerData interpreta uma data AAAA-MM-DD, que fica à meia-noite UTC como as
+// datas de compra.
+func lerData(campo, valor string) (time.Time, error) {
+	data, err := time.Parse("2006-01-02", valor)
+	if err != nil {
+		return time.Time{}, falha.Validacao("Data inválida",
+			falha.Campo{Nome: campo, Mensagem: "use o formato AAAA-MM-DD"})
+	}
+	return data, nil
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-retention/openapi"
+)
+
+// Especificacao serve a especificação OpenAPI da API.
+func Especificacao(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Especificacao)
+}
+
+// SwaggerUI serve a documentação navegável da especificação. Os arquivos do
+// Swagger UI vêm de um CDN.
+func SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.SwaggerUI)
+}
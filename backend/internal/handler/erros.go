@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/trace"
+
+	"smart-retention/internal/falha"
+	"smart-retention/internal/infra/logs"
+	"smart-retention/internal/infra/rastreio"
+)
+
+// Problema é o corpo application/problem+json (RFC 7807) das respostas de
+// erro. Codigo e Campos são extensões; request_id liga a resposta aos logs.
+type Problema struct {
+	Tipo      string        `json:"type"`
+	Titulo    string        `json:"title"`
+	Status    int           `json:"status"`
+	Detalhe   string        `json:"detail"`
+	Instancia string        `json:"instance"`
+	Codigo    falha.Codigo  `json:"codigo"`
+	Campos    []falha.Campo `json:"campos,omitempty"`
+	RequestID string        `json:"request_id,omitempty"`
+}
+
+var titulos = map[falha.Codigo]string{
+	falha.CodigoRequisicaoInvalida: "Requisição inválida",
+	falha.CodigoValidacao:          "Dados inválidos",
+	falha.CodigoNaoEncontrado:      "Recurso não encontrado",
+	falha.CodigoConflito:           "Conflito com um registro existente",
+	falha.CodigoReferenciaInvalida: "Referência inválida",
+	falha.CodigoEmUso:              "Recurso em uso",
+	falha.CodigoInterno:            "Erro interno",
+}
+
+// Erros renderiza o último erro registrado com c.Error como problem+json.
+// Os handlers só registram o erro e retornam; erros que não são *falha.Erro
+// viram 500 com mensagem genérica, e a causa vai apenas para o log e o span.
+// Nas rotas obsoletas o corpo continua no formato antigo, {"erro": ...}.
+func Erros() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		err := falha.De(c.Errors.Last().Err)
+
+		ctx := c.Request.Context()
+		if err.Codigo == falha.CodigoInterno {
+			slog.ErrorContext(ctx, "erro interno", "erro", err.Causa)
+			rastreio.Erro(trace.SpanFromContext(ctx), err.Causa)
+		}
+		if c.Writer.Written() {
+			return
+		}
+
+		if legado(c) {
+			c.JSON(err.Status(), gin.H{"erro": mensagemLegada(err)})
+			return
+		}
+
+		c.Header("Content-Type", "application/problem+json")
+		c.JSON(err.Status(), Problema{
+			Tipo:      "urn:smart-retention:erro:" + string(err.Codigo),
+			Titulo:    titulos[err.Codigo],
+			Status:    err.Status(),
+			Detalhe:   err.Mensagem,
+			Instancia: c.Request.URL.Path,
+			Codigo:    err.Codigo,
+			Campos:    err.Campos,
+			RequestID: logs.RequestID(ctx),
+		})
+	}
+}
+
+// Recuperar transforma um panic em erro interno, renderizado por Erros.
+func Recuperar() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recuperado any) {
+		c.Error(falha.Interno(fmt.Errorf("panic: %v\n%s", recuperado, debug.Stack())))
+		c.Abort()
+	})
+}
+
+// RotaNaoEncontrada responde às rotas inexistentes no mesmo formato de erro.
+func RotaNaoEncontrada(c *gin.Context) {
+	c.Error(falha.NaoEncontrado("Rota não encontrada"))
+}
+
+// entradaInvalida converte os erros de ShouldBindJSON: falhas do validador
+// viram erro de validação com os campos pelo nome em JSON, e JSON malformado
+// vira requisição inválida.
+func entradaInvalida(err error) error {
+	var validacao validator.ValidationErrors
+	if errors.As(err, &validacao) {
+		campos := make([]falha.Campo, 0, len(validacao))
+		for _, fe := range validacao {
+			campos = append(campos, falha.Campo{Nome: nomeCampo(fe), Mensagem: mensagemValidacao(fe)})
+		}
+		return falha.Validacao("Dados inválidos", campos...)
+	}
+
+	var tipo *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tipo):
+		return falha.RequisicaoInvalida("Corpo da requisição inválido",
+			falha.Campo{Nome: tipo.Field, Mensagem: "esperado " + tipo.Type.String()})
+	case errors.Is(err, io.EOF):
+		return falha.RequisicaoInvalida("Corpo da requisição vazio")
+	}
+	return falha.RequisicaoInvalida("Corpo da requisição inválido: " + err.Error())
+}
+
+// nomeCampo tira o nome da struct de entrada do caminho do campo, que já
+// usa os nomes em JSON (ver configurarValidador).
+func nomeCampo(fe validator.FieldError) string {
+	_, campo, _ := strings.Cut(fe.Namespace(), ".")
+	return campo
+}
+
+func mensagemValidacao(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "obrigatório"
+	case "required_without":
+		return "obrigatório quando " + strings.ToLower(fe.Param()) + " não é informado"
+	case "email":
+		return "e-mail inválido"
+	case "oneof":
+		return "deve ser um de: " + fe.Param()
+	case "min":
+		return "mínimo " + fe.Param()
+	case "max":
+		return "máximo " + fe.Param()
+	case "datetime":
+		if fe.Param() == "15:04" {
+			return "use o formato HH:MM"
+		}
+		return "use o formato " + fe.Param()
+	}
+	return "inválido (" + fe.Tag() + ")"
+}
+
+var configurarValidadorUmaVez sync.Once
+
+// configurarValidador faz o validador do gin nomear os campos pela tag json,
+// para que os erros apontem o campo como o cliente da API o conhece.
+func configurarValidador() {
+	configurarValidadorUmaVez.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			nome, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if nome == "-" {
+				return ""
+			}
+			if nome == "" {
+				return f.Name
+			}
+			return nome
+		})
+	})
+}
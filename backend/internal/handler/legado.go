@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/service"
+)
+
+// Formatos das rotas /api sem versão, como eram antes da v1. Obsoleta marca
+// a requisição e os handlers respondem com estes tipos em vez dos DTOs da
+// v1, até as rotas antigas serem removidas. As rotas que não existiam antes
+// da v1, ou cujo formato não mudou, respondem igual nas duas.
+const chaveLegado = "api_legada"
+
+type (
+	clienteLegado struct {
+		ID            string            `json:"id"`
+		CNPJ          string            `json:"cnpj"`
+		Nome          string            `json:"nome"`
+		Telefone      string            `json:"telefone"`
+		Email         string            `json:"email"`
+		Endereco      string            `json:"endereco"`
+		Representante string            `json:"representante"`
+		Itens         []ItemResponse    `json:"itens"`
+		DiasCompra    []diaCompraLegado `json:"dias_compra"`
+	}
+
+	diaCompraLegado struct {
+		DiaSemana int `json:"dia_semana"`
+	}
+
+	historicoLegado struct {
+		Cliente   clienteResumoLegado     `json:"cliente"`
+		Historico []compraHistoricoLegada `json:"historico"`
+	}
+
+	clienteResumoLegado struct {
+		Nome          string `json:"nome"`
+		CNPJ          string `json:"cnpj"`
+		Telefone      string `json:"telefone"`
+		Endereco      string `json:"endereco"`
+		Representante string `json:"representante"`
+	}
+
+	compraHistoricoLegada struct {
+		Data  time.Time          `json:"data"`
+		Itens []itemCompraLegado `json:"itens"`
+	}
+
+	itemCompraLegado struct {
+		Nome  string  `json:"nome"`
+		Preco float64 `json:"preco"`
+	}
+
+	// compraLegada é a listagem de GET /api/compras.
+	compraLegada struct {
+		ID          string             `json:"id"`
+		ClienteID   string             `json:"cliente_id"`
+		NomeCliente string             `json:"nome_cliente"`
+		Data        time.Time          `json:"data"`
+		Itens       []itemCompraLegado `json:"itens"`
+	}
+
+	// compraCriadaLegada é o modelo Compra devolvido por POST /api/compras.
+	compraCriadaLegada struct {
+		ID        string                   `json:"id"`
+		ClienteID string                   `json:"cliente_id"`
+		Data      time.Time                `json:"data"`
+		Itens     []compraItemCriadoLegado `json:"itens"`
+	}
+
+	compraItemCriadoLegado struct {
+		ID     string  `json:"id"`
+		ItemID string  `json:"item_id"`
+		Preco  float64 `json:"preco"`
+	}
+)
+
+// legado informa se a requisição veio por uma rota sem versão.
+func legado(c *gin.Context) bool {
+	return c.GetBool(chaveLegado)
+}
+
+// mensagemLegada é o texto de {"erro": ...}, com os problemas de cada campo
+// no fim, já que o formato antigo não tinha onde detalhá-los.
+func mensagemLegada(err *falha.Erro) string {
+	if len(err.Campos) == 0 {
+		return err.Mensagem
+	}
+	campos := make([]string, 0, len(err.Campos))
+	for _, c := range err.Campos {
+		campos = append(campos, c.Nome+": "+c.Mensagem)
+	}
+	return err.Mensagem + " (" + strings.Join(campos, "; ") + ")"
+}
+
+func novoClienteLegado(c *model.Cliente) clienteLegado {
+	itens := make([]ItemResponse, 0, len(c.Itens))
+	for _, item := range c.Itens {
+		itens = append(itens, ItemResponse{ID: item.ID, Nome: item.Nome})
+	}
+	dias := make([]diaCompraLegado, 0, len(c.DiasCompra))
+	for _, dia := range c.DiasCompra {
+		dias = append(dias, diaCompraLegado{DiaSemana: dia.DiaSemana})
+	}
+
+	return clienteLegado{
+		ID:            c.ID,
+		CNPJ:          c.CNPJ,
+		Nome:          c.Nome,
+		Telefone:      c.Telefone,
+		Email:         c.Email,
+		Endereco:      c.Endereco,
+		Representante: c.Representante,
+		Itens:         itens,
+		DiasCompra:    dias,
+	}
+}
+
+// novoHistoricoLegado mantém os null do formato antigo: sem compras, o
+// histórico vem null, e uma compra sem itens traz itens null.
+func novoHistoricoLegado(h *service.Historico) historicoLegado {
+	var compras []compraHistoricoLegada
+	for _, compra := range h.Compras {
+		compras = append(compras, compraHistoricoLegada{
+			Data:  compra.DataCompra,
+			Itens: novosItensCompraLegados(compra.Itens),
+		})
+	}
+
+	return historicoLegado{
+		Cliente: clienteResumoLegado{
+			Nome:          h.Cliente.Nome,
+			CNPJ:          h.Cliente.CNPJ,
+			Telefone:      h.Cliente.Telefone,
+			Endereco:      h.Cliente.Endereco,
+			Representante: h.Cliente.Representante,
+		},
+		Historico: compras,
+	}
+}
+
+// novasComprasLegadas mantém os null do formato antigo, como o histórico.
+func novasComprasLegadas(compras []model.Compra) []compraLegada {
+	var response []compraLegada
+	for _, compra := range compras {
+		response = append(response, compraLegada{
+			ID:          compra.ID,
+			ClienteID:   compra.ClienteID,
+			NomeCliente: compra.Cliente.Nome,
+			Data:        compra.DataCompra,
+			Itens:       novosItensCompraLegados(compra.Itens),
+		})
+	}
+	return response
+}
+
+func novaCompraCriadaLegada(compra *model.Compra) compraCriadaLegada {
+	itens := make([]compraItemCriadoLegado, 0, len(compra.Itens))
+	for _, ci := range compra.Itens {
+		itens = append(itens, compraItemCriadoLegado{ID: ci.ID, ItemID: ci.ItemID, Preco: ci.Preco})
+	}
+	return compraCriadaLegada{
+		ID:        compra.ID,
+		ClienteID: compra.ClienteID,
+		Data:      compra.DataCompra,
+		Itens:     itens,
+	}
+}
+
+func novosItensCompraLegados(itens []model.CompraItem) []itemCompraLegado {
+	var response []itemCompraLegado
+	for _, ci := range itens {
+		response = append(response, itemCompraLegado{Nome: ci.Item.Nome, Preco: ci.Preco})
+	}
+	return response
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"smart-retention/internal/infra/logs"
+)
+
+// CabecalhoRequestID é o cabeçalho lido da requisição (quando o balanceador
+// já gerou um ID) e devolvido na resposta.
+const CabecalhoRequestID = "X-Request-ID"
+
+// Rastrear dá a cada requisição um request ID, levado pelo contexto até os
+// logs e anexado ao span aberto pelo otelgin, e registra a requisição no log
+// ao final.
+func Rastrear() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inicio := time.Now()
+
+		id := c.GetHeader(CabecalhoRequestID)
+		if !requestIDValido(id) {
+			id = novoRequestID()
+		}
+		c.Header(CabecalhoRequestID, id)
+
+		rota := rotaDe(c)
+		ctx := logs.ComRequestID(c.Request.Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", id))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+
+		nivel := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			nivel = slog.LevelError
+		case status >= http.StatusBadRequest:
+			nivel = slog.LevelWarn
+		}
+		slog.Log(ctx, nivel, "requisição",
+			"metodo", c.Request.Method,
+			"rota", rota,
+			"caminho", c.Request.URL.Path,
+			"status", status,
+			"duracao_ms", time.Since(inicio).Milliseconds(),
+			"ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		)
+	}
+}
+
+// MedirRequisicoes registra a duração de cada requisição por método, rota
+// (o padrão registrado, não o caminho) e status.
+func MedirRequisicoes(duracao prometheus.ObserverVec) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inicio := time.Now()
+		c.Next()
+
+		duracao.WithLabelValues(c.Request.Method, rotaDe(c), strconv.Itoa(c.Writer.Status())).Observe(time.Since(inicio).Seconds())
+	}
+}
+
+// Obsoleta marca as respostas das rotas antigas, mantidas como apelidos
+// durante a transição para a versão nova: o cabeçalho Deprecation (RFC 9745)
+// traz a data em que foram depreciadas, o Sunset (RFC 8594) a data em que
+// serão removidas e o Link aponta a rota equivalente. Até a remoção, os
+// handlers respondem nessas rotas com os formatos anteriores (ver legado.go).
+func Obsoleta(prefixo, sucessor string, desde, remocao time.Time) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(desde.Unix(), 10)
+	sunset := remocao.UTC().Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunset)
+		c.Header("Link", "<"+sucessor+strings.TrimPrefix(c.Request.URL.Path, prefixo)+`>; rel="successor-version"`)
+		c.Set(chaveLegado, true)
+		c.Next()
+	}
+}
+
+func rotaDe(c *gin.Context) string {
+	if rota := c.FullPath(); rota != "" {
+		return rota
+	}
+	return "nao_encontrada"
+}
+
+func novoRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// requestIDValido aceita IDs recebidos de até 128 caracteres imprimíveis,
+// para que um cabeçalho arbitrário não polua os logs.
+func requestIDValido(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/model"
+)
+
+type (
+	// PedidoRecorrenteInput substitui os itens do pedido recorrente. Preco é
+	// o valor esperado da linha, como nas compras.
+	PedidoRecorrenteInput struct {
+		Itens []ItemPedidoInput `json:"itens" binding:"dive"`
+	}
+
+	ItemPedidoInput struct {
+		ItemID     string  `json:"item_id" binding:"required"`
+		Quantidade float64 `json:"quantidade" binding:"gt=0"`
+		Preco      float64 `json:"preco" binding:"min=0"`
+	}
+
+	// PedidoRecorrenteResponse traz o pedido salvo ou, com inferido, o
+	// calculado das últimas compras.
+	PedidoRecorrenteResponse struct {
+		Inferido     bool                 `json:"inferido"`
+		AtualizadoEm time.Time            `json:"atualizado_em"`
+		Total        float64              `json:"total"`
+		Itens        []CompraItemResponse `json:"itens"`
+	}
+
+	// ProximoPedidoResponse diz quando se espera o próximo pedido: pelos
+	// dias de compra (base dias_compra, com o último dia tolerado em ate) ou
+	// pelo intervalo entre as últimas compras (base historico). Sem nenhum
+	// dos dois, data e base vêm nulos.
+	ProximoPedidoResponse struct {
+		Data   *time.Time               `json:"data"`
+		Ate    *time.Time               `json:"ate"`
+		Base   *string                  `json:"base"`
+		Pedido PedidoRecorrenteResponse `json:"pedido"`
+	}
+
+	// RepetirCompraInput escolhe o que repetir; sem origem (ou sem corpo),
+	// o pedido recorrente salvo ou, sem ele, a última compra. Sem data, a
+	// compra é de hoje.
+	RepetirCompraInput struct {
+		Origem string `json:"origem" binding:"omitempty,oneof=pedido_recorrente ultima_compra"`
+		Data   string `json:"data"`
+	}
+)
+
+func (h *Handler) BuscarPedidoRecorrente(c *gin.Context) {
+	pedido, err := h.pedidos.PedidoRecorrente(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novoPedidoRecorrenteResponse(pedido))
+}
+
+func (h *Handler) SalvarPedidoRecorrente(c *gin.Context) {
+	var input PedidoRecorrenteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+
+	itens := make([]model.ItemPedidoRecorrente, 0, len(input.Itens))
+	for _, item := range input.Itens {
+		itens = append(itens, model.ItemPedidoRecorrente{ItemID: item.ItemID, Quantidade: item.Quantidade, Preco: item.Preco})
+	}
+
+	pedido, err := h.pedidos.SalvarPedidoRecorrente(c.Request.Context(), c.Param("id"), itens)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novoPedidoRecorrenteResponse(pedido))
+}
+
+func (h *Handler) InferirPedidoRecorrente(c *gin.Context) {
+	pedido, err := h.pedidos.InferirPedidoRecorrente(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novoPedidoRecorrenteResponse(pedido))
+}
+
+func (h *Handler) DeletarPedidoRecorrente(c *gin.Context) {
+	if err := h.pedidos.DeletarPedidoRecorrente(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ProximoPedido(c *gin.Context) {
+	proximo, err := h.pedidos.ProximoPedido(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := ProximoPedidoResponse{
+		Data:   proximo.Data,
+		Ate:    proximo.Ate,
+		Pedido: novoPedidoRecorrenteResponse(proximo.Pedido),
+	}
+	if proximo.Base != "" {
+		response.Base = &proximo.Base
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) RepetirCompra(c *gin.Context) {
+	var input RepetirCompraInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(entradaInvalida(err))
+		return
+	}
+	var data time.Time
+	if input.Data != "" {
+		var err error
+		if data, err = lerData("data", input.Data); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	compra, err := h.pedidos.Repetir(c.Request.Context(), c.Param("id"), input.Origem, data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, novaCompraResponse(compra))
+}
+
+func novoPedidoRecorrenteResponse(p *model.PedidoRecorrente) PedidoRecorrenteResponse {
+	itens := make([]CompraItemResponse, 0, len(p.Itens))
+	for _, item := range p.Itens {
+		itens = append(itens, CompraItemResponse{
+			ItemID:     item.ItemID,
+			Nome:       item.Item.Nome,
+			Quantidade: item.Quantidade,
+			Preco:      item.Preco,
+		})
+	}
+
+	return PedidoRecorrenteResponse{
+		Inferido:     p.Inferido,
+		AtualizadoEm: p.AtualizadoEm,
+		Total:        p.Total(),
+		Itens:        itens,
+	}
+}
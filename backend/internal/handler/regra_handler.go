@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/model"
+	"smart-retention/internal/service"
+)
+
+type (
+	RegraInput struct {
+		Nome      string `json:"nome" binding:"required"`
+		Expressao string `json:"expressao" binding:"required"`
+		Motivo    string `json:"motivo"`
+		Ativa     *bool  `json:"ativa"`
+	}
+
+	SimulacaoInput struct {
+		Expressao string `json:"expressao" binding:"required"`
+	}
+
+	RegraResponse struct {
+		ID        string `json:"id"`
+		Nome      string `json:"nome"`
+		Expressao string `json:"expressao"`
+		Motivo    string `json:"motivo"`
+		Ativa     bool   `json:"ativa"`
+	}
+
+	ClienteSimulado struct {
+		ClienteID   string `json:"cliente_id"`
+		NomeCliente string `json:"nome_cliente"`
+	}
+)
+
+func (h *Handler) ListarRegras(c *gin.Context) {
+	regras, err := h.regras.Listar(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]RegraResponse, 0, len(regras))
+	for i := range regras {
+		response = append(response, novaRegraResponse(&regras[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) BuscarRegraPeloID(c *gin.Context) {
+	regra, err := h.regras.BuscarPorID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novaRegraResponse(regra))
+}
+
+func (h *Handler) CriarRegra(c *gin.Context) {
+	var input RegraInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+
+	regra, err := h.regras.Criar(c.Request.Context(), input.dados())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, novaRegraResponse(regra))
+}
+
+func (h *Handler) AtualizarRegra(c *gin.Context) {
+	var input RegraInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+
+	regra, err := h.regras.Atualizar(c.Request.Context(), c.Param("id"), input.dados())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novaRegraResponse(regra))
+}
+
+func (h *Handler) DeletarRegra(c *gin.Context) {
+	if err := h.regras.Deletar(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SimularRegra mostra quais clientes seriam alertados hoje pela expressão,
+// sem salvar a regra.
+func (h *Handler) SimularRegra(c *gin.Context) {
+	var input SimulacaoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+
+	fatos, err := h.regras.Simular(c.Request.Context(), input.Expressao)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	clientes := make([]ClienteSimulado, 0, len(fatos))
+	for _, f := range fatos {
+		clientes = append(clientes, ClienteSimulado{
+			ClienteID:   f.ClienteID,
+			NomeCliente: f.NomeCliente,
+		})
+	}
+
+	c.JSON(http.StatusOK, clientes)
+}
+
+func (in RegraInput) dados() service.DadosRegra {
+	return service.DadosRegra{
+		Nome:      in.Nome,
+		Expressao: in.Expressao,
+		Motivo:    in.Motivo,
+		Ativa:     in.Ativa,
+	}
+}
+
+func novaRegraResponse(r *model.RegraAlerta) RegraResponse {
+	return RegraResponse{
+		ID:        r.ID,
+		Nome:      r.Nome,
+		Expressao: r.Expressao,
+		Motivo:    r.Motivo,
+		Ativa:     r.Ativa,
+	}
+}
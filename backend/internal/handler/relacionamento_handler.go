@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/model"
+)
+
+type (
+	ContatoInput struct {
+		Nome      string `json:"nome" binding:"required"`
+		Cargo     string `json:"cargo"`
+		Telefone  string `json:"telefone"`
+		Email     string `json:"email" binding:"omitempty,email"`
+		Principal bool   `json:"principal"`
+	}
+
+	ContatoResponse struct {
+		ID        string `json:"id"`
+		Nome      string `json:"nome"`
+		Cargo     string `json:"cargo"`
+		Telefone  string `json:"telefone"`
+		Email     string `json:"email"`
+		Principal bool   `json:"principal"`
+	}
+
+	// InteracaoInput registra uma interação. Data é o momento em que ela
+	// aconteceu (agora, se ausente); retorno_em e pausar_alertas_ate são
+	// datas AAAA-MM-DD.
+	InteracaoInput struct {
+		ContatoID        *string    `json:"contato_id"`
+		Tipo             string     `json:"tipo" binding:"required,oneof=nota ligacao visita email whatsapp"`
+		Descricao        string     `json:"descricao" binding:"required"`
+		Resultado        string     `json:"resultado"`
+		Autor            string     `json:"autor"`
+		Data             *time.Time `json:"data"`
+		RetornoEm        string     `json:"retorno_em"`
+		PausarAlertasAte string     `json:"pausar_alertas_ate"`
+	}
+
+	InteracaoResponse struct {
+		ID               string     `json:"id"`
+		Tipo             string     `json:"tipo"`
+		Descricao        string     `json:"descricao"`
+		Resultado        string     `json:"resultado"`
+		Autor            string     `json:"autor"`
+		Data             time.Time  `json:"data"`
+		ContatoID        *string    `json:"contato_id"`
+		NomeContato      string     `json:"nome_contato,omitempty"`
+		RetornoEm        *time.Time `json:"retorno_em"`
+		PausarAlertasAte *time.Time `json:"pausar_alertas_ate"`
+	}
+)
+
+func (h *Handler) ListarContatos(c *gin.Context) {
+	contatos, err := h.relacionamento.ListarContatos(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novosContatosResponse(contatos))
+}
+
+func (h *Handler) CriarContato(c *gin.Context) {
+	var input ContatoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+
+	contato, err := h.relacionamento.CriarContato(c.Request.Context(), c.Param("id"), input.contato())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, novoContatoResponse(contato))
+}
+
+func (h *Handler) AtualizarContato(c *gin.Context) {
+	var input ContatoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+
+	contato, err := h.relacionamento.AtualizarContato(c.Request.Context(), c.Param("id"), c.Param("contato_id"), input.contato())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novoContatoResponse(contato))
+}
+
+func (h *Handler) DeletarContato(c *gin.Context) {
+	if err := h.relacionamento.DeletarContato(c.Request.Context(), c.Param("id"), c.Param("contato_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListarInteracoes(c *gin.Context) {
+	interacoes, err := h.relacionamento.ListarInteracoes(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]InteracaoResponse, 0, len(interacoes))
+	for i := range interacoes {
+		response = append(response, novaInteracaoResponse(&interacoes[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) RegistrarInteracao(c *gin.Context) {
+	dados, err := lerInteracao(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	interacao, err := h.relacionamento.RegistrarInteracao(c.Request.Context(), c.Param("id"), dados)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, novaInteracaoResponse(interacao))
+}
+
+func (h *Handler) AtualizarInteracao(c *gin.Context) {
+	dados, err := lerInteracao(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	interacao, err := h.relacionamento.AtualizarInteracao(c.Request.Context(), c.Param("id"), c.Param("interacao_id"), dados)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novaInteracaoResponse(interacao))
+}
+
+func (h *Handler) DeletarInteracao(c *gin.Context) {
+	if err := h.relacionamento.DeletarInteracao(c.Request.Context(), c.Param("id"), c.Param("interacao_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// lerInteracao lê o corpo de criação ou de atualização de uma interação.
+func lerInteracao(c *gin.Context) (model.Interacao, error) {
+	var input InteracaoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return model.Interacao{}, entradaInvalida(err)
+	}
+
+	retorno, err := lerDataOpcional("retorno_em", input.RetornoEm)
+	if err != nil {
+		return model.Interacao{}, err
+	}
+	pausa, err := lerDataOpcional("pausar_alertas_ate", input.PausarAlertasAte)
+	if err != nil {
+		return model.Interacao{}, err
+	}
+
+	interacao := model.Interacao{
+		ContatoID:        input.ContatoID,
+		Tipo:             input.Tipo,
+		Descricao:        input.Descricao,
+		Resultado:        input.Resultado,
+		Autor:            input.Autor,
+		RetornoEm:        retorno,
+		PausarAlertasAte: pausa,
+	}
+	if input.Data != nil {
+		interacao.Data = *input.Data
+	}
+	return interacao, nil
+}
+
+// lerDataOpcional é como lerData, mas aceita o valor vazio como ausente.
+func lerDataOpcional(campo, valor string) (*time.Time, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	data, err := lerData(campo, valor)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (in ContatoInput) contato() model.Contato {
+	return model.Contato{
+		Nome:      in.Nome,
+		Cargo:     in.Cargo,
+		Telefone:  in.Telefone,
+		Email:     in.Email,
+		Principal: in.Principal,
+	}
+}
+
+func novoContatoResponse(c *model.Contato) ContatoResponse {
+	return ContatoResponse{
+		ID:        c.ID,
+		Nome:      c.Nome,
+		Cargo:     c.Cargo,
+		Telefone:  c.Telefone,
+		Email:     c.Email,
+		Principal: c.Principal,
+	}
+}
+
+func novosContatosResponse(contatos []model.Contato) []ContatoResponse {
+	response := make([]ContatoResponse, 0, len(contatos))
+	for i := range contatos {
+		response = append(response, novoContatoResponse(&contatos[i]))
+	}
+	return response
+}
+
+func novaInteracaoResponse(i *model.Interacao) InteracaoResponse {
+	response := InteracaoResponse{
+		ID:               i.ID,
+		Tipo:             i.Tipo,
+		Descricao:        i.Descricao,
+		Resultado:        i.Resultado,
+		Autor:            i.Autor,
+		Data:             i.Data,
+		ContatoID:        i.ContatoID,
+		RetornoEm:        i.RetornoEm,
+		PausarAlertasAte: i.PausarAlertasAte,
+	}
+	if i.Contato != nil {
+		response.NomeContato = i.Contato.Nome
+	}
+	return response
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"smart-retention/internal/infra/db"
+)
+
+// Tempo máximo de cada checagem do /readyz.
+const prazoChecagem = 2 * time.Second
+
+// SaudeHandler responde às checagens de liveness e readiness do orquestrador.
+type SaudeHandler struct {
+	db         *gorm.DB
+	migrador   *db.Migrador
+	encerrando atomic.Bool
+}
+
+func NewSaudeHandler(db *gorm.DB, migrador *db.Migrador) *SaudeHandler {
+	return &SaudeHandler{db: db, migrador: migrador}
+}
+
+// Encerrar faz o /readyz falhar, para que o balanceador pare de mandar
+// tráfego enquanto a instância desliga.
+func (s *SaudeHandler) Encerrar() {
+	s.encerrando.Store(true)
+}
+
+// Healthz indica apenas que o processo está de pé.
+func (s *SaudeHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz verifica se a instância pode receber tráfego: banco respondendo e
+// todas as migrações aplicadas.
+func (s *SaudeHandler) Readyz(c *gin.Context) {
+	if s.encerrando.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "encerrando"})
+		return
+	}
+
+	ctx, cancelar := context.WithTimeout(c.Request.Context(), prazoChecagem)
+	defer cancelar()
+
+	checagens := gin.H{"banco": "ok", "migracoes": "ok"}
+	pronto := true
+
+	if sqlDB, err := s.db.DB(); err != nil {
+		checagens["banco"] = err.Error()
+		pronto = false
+	} else if err := sqlDB.PingContext(ctx); err != nil {
+		checagens["banco"] = err.Error()
+		pronto = false
+	}
+
+	if n, err := s.migrador.Pendentes(ctx); err != nil {
+		checagens["migracoes"] = err.Error()
+		pronto = false
+	} else if n > 0 {
+		checagens["migracoes"] = fmt.Sprintf("%d pendentes", n)
+		pronto = false
+	}
+
+	if !pronto {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "indisponivel", "checagens": checagens})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checagens": checagens})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/ws"
+)
+
+// Intervalo dos comentários de heartbeat, abaixo do idle timeout do ALB.
+const sseHeartbeat = 15 * time.Second
+
+// HandleEventosSSE entrega os mesmos eventos do WebSocket via Server-Sent
+// Events, para navegadores atrás de proxies que bloqueiam o upgrade. A
+// assinatura vem da query string (?tipos=, ?clientes=, ?representante=) e a
+// retomada usa o cabeçalho Last-Event-ID ou ?desde=.
+func (w *WebSocketHandler) HandleEventosSSE(c *gin.Context) {
+	ultimo := c.GetHeader("Last-Event-ID")
+	if ultimo == "" {
+		ultimo = c.Query("desde")
+	}
+	desde, _ := strconv.ParseUint(ultimo, 10, 64)
+
+	client := w.Hub.AddStream(c.ClientIP(), ws.AssinaturaDaQuery(c.Request.URL.Query()), desde)
+	defer w.Hub.RemoveClient(client)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case msg, ok := <-client.Mensagens():
+			if !ok {
+				return
+			}
+			if msg.Seq > 0 {
+				fmt.Fprintf(c.Writer, "id: %d\n", msg.Seq)
+			}
+			fmt.Fprintf(c.Writer, "data: %s\n\n", msg.Corpo)
+			c.Writer.Flush()
+
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
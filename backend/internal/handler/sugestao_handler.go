@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/falha"
+)
+
+// limiteSugestoes é o número de sugestões devolvido sem ?limite=.
+const limiteSugestoes = 5
+
+type (
+	// SugestoesResponse traz os itens sugeridos, do mais para o menos
+	// indicado. atualizado_em é nulo enquanto o modelo não foi calculado.
+	SugestoesResponse struct {
+		AtualizadoEm *time.Time         `json:"atualizado_em"`
+		Sugestoes    []SugestaoResponse `json:"sugestoes"`
+	}
+
+	SugestaoResponse struct {
+		ItemID    string  `json:"item_id"`
+		Nome      string  `json:"nome"`
+		Pontuacao float64 `json:"pontuacao"`
+		Motivo    string  `json:"motivo"`
+	}
+)
+
+// SugestoesCliente aceita ?limite= de 1 a 20.
+func (h *Handler) SugestoesCliente(c *gin.Context) {
+	limite := limiteSugestoes
+	if v := c.Query("limite"); v != "" {
+		var err error
+		if limite, err = strconv.Atoi(v); err != nil || limite < 1 || limite > 20 {
+			c.Error(falha.RequisicaoInvalida("Parâmetro limite inválido",
+				falha.Campo{Nome: "limite", Mensagem: "use um número de 1 a 20"}))
+			return
+		}
+	}
+
+	sugestoes, err := h.sugestoes.Sugerir(c.Request.Context(), c.Param("id"), limite)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := SugestoesResponse{
+		AtualizadoEm: sugestoes.AtualizadoEm,
+		Sugestoes:    make([]SugestaoResponse, 0, len(sugestoes.Itens)),
+	}
+	for _, s := range sugestoes.Itens {
+		response.Sugestoes = append(response.Sugestoes, SugestaoResponse{
+			ItemID:    s.ItemID,
+			Nome:      s.Nome,
+			Pontuacao: s.Pontuacao,
+			Motivo:    s.Motivo,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
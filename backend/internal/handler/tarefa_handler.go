@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/service"
+)
+
+type (
+	// TarefaInput cria uma tarefa. Com tipo_alerta (e regra_id, para as
+	// regras personalizadas) a tarefa acompanha o alerta em aberto do
+	// cliente; nesse caso o título é opcional.
+	TarefaInput struct {
+		ClienteID   string `json:"cliente_id" binding:"required"`
+		TipoAlerta  string `json:"tipo_alerta"`
+		RegraID     string `json:"regra_id"`
+		Titulo      string `json:"titulo"`
+		Descricao   string `json:"descricao"`
+		Responsavel string `json:"responsavel"`
+		Vencimento  string `json:"vencimento" binding:"required"`
+		Status      string `json:"status"`
+	}
+
+	TarefaAtualizacaoInput struct {
+		Titulo      string `json:"titulo" binding:"required"`
+		Descricao   string `json:"descricao"`
+		Responsavel string `json:"responsavel"`
+		Vencimento  string `json:"vencimento" binding:"required"`
+		Status      string `json:"status" binding:"required"`
+	}
+
+	TarefaResponse struct {
+		ID          string     `json:"id"`
+		ClienteID   string     `json:"cliente_id"`
+		NomeCliente string     `json:"nome_cliente"`
+		AlertaID    *string    `json:"alerta_id"` // registro do alerta no histórico
+		TipoAlerta  string     `json:"tipo_alerta"`
+		Titulo      string     `json:"titulo"`
+		Descricao   string     `json:"descricao"`
+		Responsavel string     `json:"responsavel"`
+		Status      string     `json:"status"`
+		Vencimento  time.Time  `json:"vencimento"`
+		CriadaEm    time.Time  `json:"criada_em"`
+		ConcluidaEm *time.Time `json:"concluida_em"`
+	}
+
+	RegraTarefaInput struct {
+		Ativa       *bool  `json:"ativa"`
+		PrazoDias   int    `json:"prazo_dias" binding:"min=0"`
+		Responsavel string `json:"responsavel"`
+	}
+
+	RegraTarefaResponse struct {
+		TipoAlerta  string `json:"tipo_alerta"`
+		Ativa       bool   `json:"ativa"`
+		PrazoDias   int    `json:"prazo_dias"`
+		Responsavel string `json:"responsavel"`
+	}
+)
+
+// ListarTarefas aceita os filtros ?responsavel=, ?status= (um ou mais,
+// separados por vírgula) e ?cliente_id=.
+func (h *Handler) ListarTarefas(c *gin.Context) {
+	tarefas, err := h.tarefas.Listar(c.Request.Context(), repository.FiltroTarefas{
+		Responsavel: c.Query("responsavel"),
+		Status:      listaQuery(c.Query("status")),
+		ClienteID:   c.Query("cliente_id"),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]TarefaResponse, 0, len(tarefas))
+	for i := range tarefas {
+		response = append(response, novaTarefaResponse(&tarefas[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) BuscarTarefaPeloID(c *gin.Context) {
+	tarefa, err := h.tarefas.BuscarPorID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novaTarefaResponse(tarefa))
+}
+
+func (h *Handler) CriarTarefa(c *gin.Context) {
+	var input TarefaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+	vencimento, err := lerData("vencimento", input.Vencimento)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tarefa, err := h.tarefas.Criar(c.Request.Context(), service.DadosTarefa{
+		ClienteID:   input.ClienteID,
+		TipoAlerta:  input.TipoAlerta,
+		RegraID:     input.RegraID,
+		Titulo:      input.Titulo,
+		Descricao:   input.Descricao,
+		Responsavel: input.Responsavel,
+		Vencimento:  vencimento,
+		Status:      input.Status,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, novaTarefaResponse(tarefa))
+}
+
+func (h *Handler) AtualizarTarefa(c *gin.Context) {
+	var input TarefaAtualizacaoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+	vencimento, err := lerData("vencimento", input.Vencimento)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tarefa, err := h.tarefas.Atualizar(c.Request.Context(), c.Param("id"), service.DadosTarefa{
+		Titulo:      input.Titulo,
+		Descricao:   input.Descricao,
+		Responsavel: input.Responsavel,
+		Vencimento:  vencimento,
+		Status:      input.Status,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novaTarefaResponse(tarefa))
+}
+
+func (h *Handler) ListarRegrasTarefa(c *gin.Context) {
+	regras, err := h.tarefas.ListarRegras(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]RegraTarefaResponse, 0, len(regras))
+	for _, r := range regras {
+		response = append(response, novaRegraTarefaResponse(&r))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SalvarRegraTarefa cria ou substitui a regra do tipo de alerta do caminho.
+func (h *Handler) SalvarRegraTarefa(c *gin.Context) {
+	var input RegraTarefaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(entradaInvalida(err))
+		return
+	}
+
+	regra, err := h.tarefas.SalvarRegra(c.Request.Context(), model.RegraTarefa{
+		TipoAlerta:  c.Param("tipo_alerta"),
+		Ativa:       input.Ativa == nil || *input.Ativa,
+		PrazoDias:   input.PrazoDias,
+		Responsavel: input.Responsavel,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, novaRegraTarefaResponse(regra))
+}
+
+func (h *Handler) DeletarRegraTarefa(c *gin.Context) {
+	if err := h.tarefas.DeletarRegra(c.Request.Context(), c.Param("tipo_alerta")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func novaTarefaResponse(t *model.Tarefa) TarefaResponse {
+	return TarefaResponse{
+		ID:          t.ID,
+		ClienteID:   t.ClienteID,
+		NomeCliente: t.Cliente.Nome,
+		AlertaID:    t.AlertaHistoricoID,
+		TipoAlerta:  t.TipoAlerta,
+		Titulo:      t.Titulo,
+		Descricao:   t.Descricao,
+		Responsavel: t.Responsavel,
+		Status:      t.Status,
+		Vencimento:  t.Vencimento,
+		CriadaEm:    t.CriadaEm,
+		ConcluidaEm: t.ConcluidaEm,
+	}
+}
+
+func novaRegraTarefaResponse(r *model.RegraTarefa) RegraTarefaResponse {
+	return RegraTarefaResponse{
+		TipoAlerta:  r.TipoAlerta,
+		Ativa:       r.Ativa,
+		PrazoDias:   r.PrazoDias,
+		Responsavel: r.Responsavel,
+	}
+}
+
+// listaQuery separa um parâmetro de query com valores separados por vírgula.
+func listaQuery(v string) []string {
+	var itens []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			itens = append(itens, s)
+		}
+	}
+	return itens
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -22,13 +23,12 @@ func (w *WebSocketHandler) HandleAlertasWS(c *gin.Context) {
 		return
 	}
 
-	w.Hub.AddClient(conn)
+	// Quem reconecta informa ?desde=<seq> do último evento recebido para
+	// receber só o que perdeu.
+	desde, _ := strconv.ParseUint(c.Query("desde"), 10, 64)
 
-	for {
-		// WebSocket clients don't need to send messages in this case.
-		if _, _, err := conn.ReadMessage(); err != nil {
-			w.Hub.RemoveClient(conn)
-			break
-		}
-	}
+	// A assinatura inicial vem da query string e pode ser trocada depois
+	// com mensagens {"acao": "assinar", ...}.
+	client := w.Hub.AddClient(conn, ws.AssinaturaDaQuery(c.Request.URL.Query()), desde)
+	client.ReadPump()
 }
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ChaveLider é o advisory lock disputado pelas instâncias.
+	ChaveLider = 7_325_000
+
+	intervaloLider = 5 * time.Second
+)
+
+// Lider elege, entre as instâncias, a que roda os jobs agendados. A eleição
+// é um advisory lock de sessão: quem o obtém mantém a conexão aberta e
+// continua líder até ela cair, quando o Postgres libera o lock para outra
+// instância.
+type Lider struct {
+	db    *gorm.DB
+	chave int64
+	lider atomic.Bool
+}
+
+func NewLider(db *gorm.DB, chave int64) *Lider {
+	return &Lider{db: db, chave: chave}
+}
+
+// EhLider informa se esta instância é a líder no momento.
+func (l *Lider) EhLider() bool {
+	return l.lider.Load()
+}
+
+// SeLider roda fn apenas se esta instância for a líder.
+func (l *Lider) SeLider(fn func()) {
+	if l.EhLider() {
+		fn()
+	}
+}
+
+// Manter disputa a liderança até ctx ser cancelado.
+func (l *Lider) Manter(ctx context.Context) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		slog.Error("erro ao obter conexão para eleição de líder", "erro", err)
+		return
+	}
+
+	for {
+		l.disputar(ctx, sqlDB)
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(intervaloLider):
+		}
+	}
+}
+
+// disputar tenta obter o lock e, se conseguir, mantém a conexão viva até ela
+// falhar ou ctx ser cancelado.
+func (l *Lider) disputar(ctx context.Context, sqlDB *sql.DB) {
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var obtido bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.chave).Scan(&obtido); err != nil || !obtido {
+		return
+	}
+
+	l.lider.Store(true)
+	slog.Info("esta instância assumiu a liderança dos jobs agendados")
+	defer func() {
+		l.lider.Store(false)
+		slog.Info("esta instância deixou a liderança dos jobs agendados")
+	}()
+
+	ticker := time.NewTicker(intervaloLider)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Libera o lock explicitamente; a conexão volta ao pool
+			conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.chave)
+			return
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil {
+				// Descarta a conexão em vez de devolvê-la ao pool, para que
+				// um lock ainda preso a ela não volte a ser usado
+				conn.Raw(func(any) error { return driver.ErrBadConn })
+				return
+			}
+		}
+	}
+}
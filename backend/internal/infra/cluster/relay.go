@@ -0,0 +1,264 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"smart-retention/internal/model"
+	"smart-retention/internal/ws"
+)
+
+const (
+	canal = "smart_retention_eventos"
+
+	// chaveFila serializa as inserções na fila para que a ordem dos IDs seja
+	// a ordem de commit, e quem lê "id > último" não pule eventos.
+	chaveFila = 7_325_001
+
+	// Por quanto tempo os eventos ficam na fila, para retomada e depuração.
+	retencaoFila = 24 * time.Hour
+)
+
+const (
+	opPublicar = "publicar"
+	opReter    = "reter"
+	opLiberar  = "liberar"
+)
+
+type (
+	// Relay distribui os eventos do hub entre as instâncias usando uma fila
+	// no Postgres e LISTEN/NOTIFY. Cada instância, inclusive a que publicou,
+	// aplica os eventos da fila no seu hub local, na ordem da fila, usando o
+	// ID como sequência. Assim todas atribuem o mesmo Seq ao mesmo evento.
+	Relay struct {
+		db     *gorm.DB
+		hub    *ws.Hub
+		ultimo uint64
+	}
+
+	envelope struct {
+		Op            string          `json:"op"`
+		Chave         string          `json:"chave,omitempty"`
+		Nome          string          `json:"nome"`
+		Dados         json.RawMessage `json:"dados"`
+		ClienteID     string          `json:"cliente_id,omitempty"`
+		TipoAlerta    string          `json:"tipo_alerta,omitempty"`
+		Representante string          `json:"representante,omitempty"`
+	}
+)
+
+func NewRelay(db *gorm.DB, hub *ws.Hub) *Relay {
+	return &Relay{db: db, hub: hub}
+}
+
+func (r *Relay) Publicar(e ws.Evento) {
+	r.enviar(opPublicar, "", e)
+}
+
+func (r *Relay) Reter(chave string, e ws.Evento) {
+	r.enviar(opReter, chave, e)
+}
+
+func (r *Relay) Liberar(chave string, e ws.Evento) {
+	r.enviar(opLiberar, chave, e)
+}
+
+func (r *Relay) Retidos() map[string]ws.Evento {
+	return r.hub.Retidos()
+}
+
+// enviar grava o evento na fila (e no estado, quando retido) e notifica as
+// instâncias. A notificação só é entregue no commit.
+func (r *Relay) enviar(op, chave string, e ws.Evento) {
+	dados, err := json.Marshal(e.Dados)
+	if err != nil {
+		slog.Error("erro ao serializar evento do hub", "evento", e.Nome, "erro", err)
+		return
+	}
+
+	payload, err := json.Marshal(envelope{
+		Op:            op,
+		Chave:         chave,
+		Nome:          e.Nome,
+		Dados:         dados,
+		ClienteID:     e.ClienteID,
+		TipoAlerta:    e.TipoAlerta,
+		Representante: e.Representante,
+	})
+	if err != nil {
+		slog.Error("erro ao serializar evento do hub", "evento", e.Nome, "erro", err)
+		return
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chaveFila).Error; err != nil {
+			return err
+		}
+
+		evento := model.EventoHub{Payload: string(payload), CriadoEm: time.Now()}
+		if err := tx.Create(&evento).Error; err != nil {
+			return err
+		}
+
+		switch op {
+		case opReter:
+			estado := model.EstadoHub{Chave: chave, Payload: string(payload)}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&estado).Error; err != nil {
+				return err
+			}
+		case opLiberar:
+			if err := tx.Delete(&model.EstadoHub{}, "chave = ?", chave).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec("SELECT pg_notify(?, ?)", canal, strconv.FormatUint(evento.ID, 10)).Error
+	})
+	if err != nil {
+		slog.Error("erro ao publicar evento no cluster", "erro", err)
+	}
+}
+
+// Iniciar restaura o estado retido e posiciona a sequência do hub no fim da
+// fila. Deve ser chamado antes de Escutar e de aceitar conexões.
+func (r *Relay) Iniciar() error {
+	var ultimo uint64
+	if err := r.db.Model(&model.EventoHub{}).Select("COALESCE(MAX(id), 0)").Scan(&ultimo).Error; err != nil {
+		return err
+	}
+
+	var estados []model.EstadoHub
+	if err := r.db.Find(&estados).Error; err != nil {
+		return err
+	}
+
+	for _, estado := range estados {
+		var env envelope
+		if err := json.Unmarshal([]byte(estado.Payload), &env); err != nil {
+			slog.Warn("ignorando estado inválido", "chave", estado.Chave, "erro", err)
+			continue
+		}
+		r.hub.Restaurar(estado.Chave, env.evento(0))
+	}
+
+	r.ultimo = ultimo
+	r.hub.DefinirSeq(ultimo)
+	return nil
+}
+
+// Escutar mantém uma conexão dedicada em LISTEN e aplica no hub local os
+// eventos novos da fila a cada notificação. Reconecta sozinho até ctx ser
+// cancelado.
+func (r *Relay) Escutar(ctx context.Context) {
+	for {
+		err := r.escutar(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("conexão de LISTEN perdida, reconectando", "erro", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+func (r *Relay) escutar(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("driver do banco não é pgx")
+		}
+		pg := c.Conn()
+
+		if _, err := pg.Exec(ctx, "LISTEN "+canal); err != nil {
+			return err
+		}
+
+		// Eventos publicados enquanto a conexão estava fora
+		if err := r.aplicarPendentes(ctx); err != nil {
+			return err
+		}
+
+		for {
+			if _, err := pg.WaitForNotification(ctx); err != nil {
+				return err
+			}
+			if err := r.aplicarPendentes(ctx); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// aplicarPendentes lê da fila tudo o que veio depois do último evento
+// aplicado. Uma leitura pode cobrir várias notificações.
+func (r *Relay) aplicarPendentes(ctx context.Context) error {
+	var eventos []model.EventoHub
+	if err := r.db.WithContext(ctx).
+		Where("id > ?", r.ultimo).
+		Order("id").
+		Find(&eventos).Error; err != nil {
+		return err
+	}
+
+	for _, evento := range eventos {
+		r.ultimo = evento.ID
+
+		var env envelope
+		if err := json.Unmarshal([]byte(evento.Payload), &env); err != nil {
+			slog.Warn("ignorando evento inválido", "id", evento.ID, "erro", err)
+			continue
+		}
+
+		e := env.evento(evento.ID)
+		switch env.Op {
+		case opReter:
+			r.hub.Reter(env.Chave, e)
+		case opLiberar:
+			r.hub.Liberar(env.Chave, e)
+		default:
+			r.hub.Publicar(e)
+		}
+	}
+
+	return nil
+}
+
+// Limpar remove da fila os eventos mais antigos que a retenção. Deve rodar
+// apenas no líder.
+func (r *Relay) Limpar() error {
+	return r.db.Where("criado_em < ?", time.Now().Add(-retencaoFila)).Delete(&model.EventoHub{}).Error
+}
+
+func (env envelope) evento(seq uint64) ws.Evento {
+	return ws.Evento{
+		Seq:           seq,
+		Nome:          env.Nome,
+		Dados:         env.Dados,
+		ClienteID:     env.ClienteID,
+		TipoAlerta:    env.TipoAlerta,
+		Representante: env.Representante,
+	}
+}
@@ -2,66 +2,27 @@ package db
 
 import (
 	"fmt"
-	"github.com/joho/godotenv"
-	"log"
-	"os"
 
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 
-	"smart-retention/internal/model"
+	"smart-retention/internal/config"
 )
 
-var dsn string
-
-func init() {
-	appEnv := os.Getenv("APP_ENV")
-
-	if appEnv == "" || appEnv == "development" {
-		if err := godotenv.Load(".env.development"); err != nil {
-			log.Println("⚠️ Ignorando .env.development: não encontrado.")
-		}
-	}
-
-	host := os.Getenv("DB_HOST")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-	port := os.Getenv("DB_PORT")
-
-	if host == "" || user == "" || password == "" || dbname == "" || port == "" {
-		log.Fatal("variáveis de ambiente do banco não configuradas")
-	}
-
-	dsn = fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s  port=%s sslmode=disable",
-		host,
-		user,
-		password,
-		dbname,
-		port,
-	)
-}
-
-func Connect() *gorm.DB {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Connect abre o banco com as consultas registradas no log só quando lentas
+// ou com erro do servidor, e um span para cada uma pelo otelgorm; nos dois,
+// sem os valores dos parâmetros.
+func Connect(cfg config.Banco) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
+		Logger: gormLogger{nivel: logger.Warn},
+	})
 	if err != nil {
-		log.Fatal("erro ao conectar no banco: ", err)
+		return nil, fmt.Errorf("erro ao conectar no banco: %w", err)
 	}
-	return db
-}
-
-func AutoMigrate(db *gorm.DB) {
-	err := db.AutoMigrate(
-		&model.Cliente{},
-		&model.Item{},
-		&model.Compra{},
-		&model.CompraItem{},
-		&model.DiaCompraCliente{},
-	)
-	if err != nil {
-		log.Fatal("erro ao migrar o banco: ", err)
-	} else {
-		log.Println("Banco migrado com sucesso")
+	if err := db.Use(otelgorm.NewPlugin(otelgorm.WithDBName(cfg.Nome), otelgorm.WithoutQueryVariables(), otelgorm.WithoutMetrics())); err != nil {
+		return nil, fmt.Errorf("erro ao instrumentar o banco: %w", err)
 	}
+	return db, nil
 }
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// consultaLenta é o tempo a partir do qual uma consulta é registrada como
+// aviso.
+const consultaLenta = 200 * time.Millisecond
+
+// gormLogger registra as consultas do GORM no slog, com o contexto da
+// requisição. Consultas vão em debug, lentas em warn e erros em error, menos
+// os que o repositório devolve como erro do cliente (ver errosDoCliente). O
+// SQL vai sem os valores dos parâmetros. Os spans das consultas vêm do
+// plugin otelgorm (ver Connect).
+type gormLogger struct {
+	nivel logger.LogLevel
+}
+
+func (l gormLogger) LogMode(nivel logger.LogLevel) logger.Interface {
+	return gormLogger{nivel: nivel}
+}
+
+func (l gormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.nivel >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l gormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if l.nivel >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l gormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.nivel >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// ParamsFilter deixa os valores fora do SQL registrado, com os $1, $2 no
+// lugar: eles trazem dados dos clientes, como CNPJs, telefones e e-mails.
+func (l gormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
+
+// Trace só monta o SQL, com fc, quando a consulta vai mesmo ser registrada.
+func (l gormLogger) Trace(ctx context.Context, inicio time.Time, fc func() (string, int64), err error) {
+	duracao := time.Since(inicio)
+
+	nivel, msg := slog.LevelDebug, "consulta"
+	switch {
+	case err != nil && !erroDoCliente(err) && l.nivel >= logger.Error:
+		nivel, msg = slog.LevelError, "erro na consulta"
+	case duracao >= consultaLenta && l.nivel >= logger.Warn:
+		nivel, msg = slog.LevelWarn, "consulta lenta"
+	case l.nivel < logger.Info:
+		return
+	}
+	if !slog.Default().Enabled(ctx, nivel) {
+		return
+	}
+
+	sql, linhas := fc()
+	// Sem os valores, o Explain do GORM deixa os marcadores como $1$
+	sql = marcadorExplicado.ReplaceAllString(sql, "$$$1")
+	attrs := []any{"sql", sql, "linhas", linhas, "duracao_ms", duracao.Milliseconds()}
+	if err != nil {
+		attrs = append(attrs, "erro", err)
+	}
+	slog.Log(ctx, nivel, msg, attrs...)
+}
+
+var marcadorExplicado = regexp.MustCompile(`\$(\d+)\$`)
+
+// errosDoCliente são os códigos do Postgres que o repository.traduzir
+// devolve como 4xx: CNPJ repetido, referência inexistente, campo
+// obrigatório, UUID malformado. A resposta já é registrada pelo middleware
+// de log e o erro não é uma falha do servidor.
+var errosDoCliente = map[string]bool{
+	"23505": true, // unique_violation
+	"23503": true, // foreign_key_violation
+	"23502": true, // not_null_violation
+	"22P02": true, // invalid_text_representation
+}
+
+func erroDoCliente(err error) bool {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && errosDoCliente[pgErr.Code]
+}
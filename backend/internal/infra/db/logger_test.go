@@ -0,0 +1,92 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// registrarEm troca o logger padrão por um que escreve em texto no buffer,
+// com o nível informado.
+func registrarEm(t *testing.T, nivel slog.Level) *bytes.Buffer {
+	t.Helper()
+	anterior := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(anterior) })
+	var saida bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&saida, &slog.HandlerOptions{Level: nivel})))
+	return &saida
+}
+
+func TestGormLoggerTrace(t *testing.T) {
+	casos := []struct {
+		nome     string
+		nivel    logger.LogLevel
+		slog     slog.Level
+		duracao  time.Duration
+		err      error
+		esperado string // trecho do registro; vazio se nada deve ser registrado
+	}{
+		{nome: "consulta rápida", nivel: logger.Warn, slog: slog.LevelDebug},
+		{nome: "consulta em debug", nivel: logger.Info, slog: slog.LevelDebug, esperado: "level=DEBUG msg=consulta"},
+		{nome: "debug desligado no slog", nivel: logger.Info, slog: slog.LevelInfo},
+		{nome: "consulta lenta", nivel: logger.Warn, slog: slog.LevelInfo, duracao: time.Second, esperado: "level=WARN msg=\"consulta lenta\""},
+		{nome: "erro", nivel: logger.Warn, slog: slog.LevelInfo, err: errors.New("conexão perdida"), esperado: "level=ERROR msg=\"erro na consulta\""},
+		{nome: "registro não encontrado", nivel: logger.Warn, slog: slog.LevelInfo, err: gorm.ErrRecordNotFound},
+		{nome: "CNPJ repetido", nivel: logger.Warn, slog: slog.LevelInfo, err: &pgconn.PgError{Code: "23505"}},
+		{nome: "referência inexistente", nivel: logger.Warn, slog: slog.LevelInfo, err: &pgconn.PgError{Code: "23503"}},
+		{nome: "erro do cliente lento", nivel: logger.Warn, slog: slog.LevelInfo, duracao: time.Second, err: &pgconn.PgError{Code: "23505"}, esperado: "consulta lenta"},
+		{nome: "erro do cliente em debug", nivel: logger.Info, slog: slog.LevelDebug, err: &pgconn.PgError{Code: "23505"}, esperado: "level=DEBUG msg=consulta"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			saida := registrarEm(t, c.slog)
+			montado := false
+			fc := func() (string, int64) {
+				montado = true
+				return `SELECT * FROM clientes WHERE cnpj = $1`, 1
+			}
+
+			gormLogger{nivel: c.nivel}.Trace(context.Background(), time.Now().Add(-c.duracao), fc, c.err)
+
+			if c.esperado == "" {
+				if saida.Len() != 0 || montado {
+					t.Errorf("registrou %q (SQL montado: %v), esperado nada", saida, montado)
+				}
+				return
+			}
+			if !strings.Contains(saida.String(), c.esperado) || !strings.Contains(saida.String(), "cnpj = $1") {
+				t.Errorf("registro = %q, esperado %s com o SQL", saida, c.esperado)
+			}
+		})
+	}
+}
+
+// Pelo GORM, o SQL registrado traz os marcadores no lugar dos valores.
+func TestGormLoggerRegistraSQLSemValores(t *testing.T) {
+	saida := registrarEm(t, slog.LevelDebug)
+	conn, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		Logger:               gormLogger{nivel: logger.Info},
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nomes []string
+	conn.Table("clientes").Where("cnpj = ? AND nome = ?", "12345678000199", "Mercado Central").Pluck("nome", &nomes)
+
+	if registro := saida.String(); !strings.Contains(registro, "cnpj = $1 AND nome = $2") ||
+		strings.Contains(registro, "12345678000199") || strings.Contains(registro, "Mercado Central") {
+		t.Errorf("registro = %q, esperado o SQL com $1 e $2 e sem os valores", registro)
+	}
+}
@@ -0,0 +1,277 @@
+package db
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// chaveMigracao serializa as migrações entre instâncias que sobem juntas.
+const chaveMigracao = 7_325_002
+
+type (
+	// Migracao é um arquivo <versão>_<nome>.sql com as seções Up e Down.
+	Migracao struct {
+		Versao int64
+		Nome   string
+		Up     string
+		Down   string
+	}
+
+	// StatusMigracao indica se uma migração já foi aplicada e quando.
+	StatusMigracao struct {
+		Migracao
+		AplicadaEm *time.Time
+	}
+
+	// Migrador aplica as migrações na ordem das versões e registra as
+	// aplicadas na tabela schema_migrations.
+	Migrador struct {
+		db        *gorm.DB
+		migracoes []Migracao
+	}
+
+	migracaoAplicada struct {
+		Versao     int64
+		AplicadaEm time.Time
+	}
+)
+
+func NewMigrador(db *gorm.DB, fsys fs.FS) (*Migrador, error) {
+	migracoes, err := CarregarMigracoes(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrador{db: db, migracoes: migracoes}, nil
+}
+
+// CarregarMigracoes lê os arquivos .sql da raiz de fsys, ordenados por versão.
+func CarregarMigracoes(fsys fs.FS) ([]Migracao, error) {
+	arquivos, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migracoes []Migracao
+	for _, arquivo := range arquivos {
+		base := strings.TrimSuffix(path.Base(arquivo), ".sql")
+		versao, nome, _ := strings.Cut(base, "_")
+		v, err := strconv.ParseInt(versao, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migração %s: versão inválida", arquivo)
+		}
+
+		f, err := fsys.Open(arquivo)
+		if err != nil {
+			return nil, err
+		}
+		up, down, err := lerSecoes(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("migração %s: %w", arquivo, err)
+		}
+
+		migracoes = append(migracoes, Migracao{Versao: v, Nome: nome, Up: up, Down: down})
+	}
+
+	slices.SortFunc(migracoes, func(a, b Migracao) int {
+		return cmp.Compare(a.Versao, b.Versao)
+	})
+	for i := 1; i < len(migracoes); i++ {
+		if migracoes[i].Versao == migracoes[i-1].Versao {
+			return nil, fmt.Errorf("versão de migração repetida: %d", migracoes[i].Versao)
+		}
+	}
+	return migracoes, nil
+}
+
+// lerSecoes separa o SQL das seções "-- +goose Up" e "-- +goose Down". As
+// demais anotações do goose (StatementBegin/End) são ignoradas: cada seção é
+// executada inteira de uma vez.
+func lerSecoes(r io.Reader) (up, down string, err error) {
+	var atual *strings.Builder
+	var bUp, bDown strings.Builder
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		linha := s.Text()
+		if anotacao, ok := strings.CutPrefix(strings.TrimSpace(linha), "-- +goose "); ok {
+			switch strings.TrimSpace(anotacao) {
+			case "Up":
+				atual = &bUp
+			case "Down":
+				atual = &bDown
+			}
+			continue
+		}
+		if atual != nil {
+			atual.WriteString(linha)
+			atual.WriteByte('\n')
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(bUp.String()) == "" {
+		return "", "", fmt.Errorf("seção Up vazia")
+	}
+	return bUp.String(), bDown.String(), nil
+}
+
+// Up aplica as migrações pendentes. Um banco que já tem as tabelas criadas
+// pelo antigo AutoMigrate adota a primeira migração, que é só o esquema que
+// o AutoMigrate criava, sem executá-la; as demais rodam normalmente e por
+// isso não podem supor nada além desse esquema.
+func (m *Migrador) Up() (int, error) {
+	aplicadas := 0
+	for _, mig := range m.migracoes {
+		ok, err := m.aplicar(mig)
+		if err != nil {
+			return aplicadas, fmt.Errorf("migração %d_%s: %w", mig.Versao, mig.Nome, err)
+		}
+		if ok {
+			aplicadas++
+		}
+	}
+	return aplicadas, nil
+}
+
+func (m *Migrador) aplicar(mig Migracao) (bool, error) {
+	aplicou := false
+	err := m.transacao(func(tx *gorm.DB) error {
+		versoes, err := versoesAplicadas(tx)
+		if err != nil {
+			return err
+		}
+		if _, ok := versoes[mig.Versao]; ok {
+			return nil
+		}
+
+		if mig.Versao == m.migracoes[0].Versao {
+			var existe bool
+			if err := tx.Raw(`SELECT to_regclass('clientes') IS NOT NULL`).Scan(&existe).Error; err != nil {
+				return err
+			}
+			if existe {
+				return registrar(tx, mig.Versao)
+			}
+		}
+
+		if err := tx.Exec(mig.Up).Error; err != nil {
+			return err
+		}
+		aplicou = true
+		return registrar(tx, mig.Versao)
+	})
+	return aplicou, err
+}
+
+// Down desfaz a última migração aplicada.
+func (m *Migrador) Down() (*Migracao, error) {
+	var desfeita *Migracao
+	err := m.transacao(func(tx *gorm.DB) error {
+		versoes, err := versoesAplicadas(tx)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migracoes) - 1; i >= 0; i-- {
+			mig := m.migracoes[i]
+			if _, ok := versoes[mig.Versao]; !ok {
+				continue
+			}
+			if strings.TrimSpace(mig.Down) != "" {
+				if err := tx.Exec(mig.Down).Error; err != nil {
+					return fmt.Errorf("migração %d_%s: %w", mig.Versao, mig.Nome, err)
+				}
+			}
+			if err := tx.Exec(`DELETE FROM schema_migrations WHERE versao = ?`, mig.Versao).Error; err != nil {
+				return err
+			}
+			desfeita = &mig
+			return nil
+		}
+		return nil
+	})
+	return desfeita, err
+}
+
+// Status lista todas as migrações conhecidas e quando cada uma foi aplicada.
+func (m *Migrador) Status() ([]StatusMigracao, error) {
+	var status []StatusMigracao
+	err := m.transacao(func(tx *gorm.DB) error {
+		versoes, err := versoesAplicadas(tx)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migracoes {
+			s := StatusMigracao{Migracao: mig}
+			if em, ok := versoes[mig.Versao]; ok {
+				s.AplicadaEm = &em
+			}
+			status = append(status, s)
+		}
+		return nil
+	})
+	return status, err
+}
+
+// Pendentes conta as migrações ainda não aplicadas. Ao contrário de Status,
+// só lê a tabela de controle, sem lock, para ser usado em checagens de saúde.
+func (m *Migrador) Pendentes(ctx context.Context) (int, error) {
+	var versoes []int64
+	if err := m.db.WithContext(ctx).Raw(`SELECT versao FROM schema_migrations`).Scan(&versoes).Error; err != nil {
+		return 0, err
+	}
+
+	pendentes := 0
+	for _, mig := range m.migracoes {
+		if !slices.Contains(versoes, mig.Versao) {
+			pendentes++
+		}
+	}
+	return pendentes, nil
+}
+
+// transacao roda fn com a tabela de controle criada e o lock de migração.
+func (m *Migrador) transacao(fn func(tx *gorm.DB) error) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`SELECT pg_advisory_xact_lock(?)`, chaveMigracao).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				versao bigint PRIMARY KEY,
+				aplicada_em timestamptz NOT NULL DEFAULT now()
+			)
+		`).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+func versoesAplicadas(tx *gorm.DB) (map[int64]time.Time, error) {
+	var linhas []migracaoAplicada
+	if err := tx.Raw(`SELECT versao, aplicada_em FROM schema_migrations`).Scan(&linhas).Error; err != nil {
+		return nil, err
+	}
+	versoes := make(map[int64]time.Time, len(linhas))
+	for _, l := range linhas {
+		versoes[l.Versao] = l.AplicadaEm
+	}
+	return versoes, nil
+}
+
+func registrar(tx *gorm.DB, versao int64) error {
+	return tx.Exec(`INSERT INTO schema_migrations (versao) VALUES (?)`, versao).Error
+}
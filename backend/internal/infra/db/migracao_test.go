@@ -0,0 +1,77 @@
+package db_test
+
+import (
+	"testing"
+
+	"smart-retention/internal/infra/db"
+	"smart-retention/internal/infra/db/dbteste"
+	"smart-retention/migrations"
+)
+
+func TestMain(m *testing.M) { dbteste.Principal(m) }
+
+func TestCarregarMigracoes(t *testing.T) {
+	migracoes, err := db.CarregarMigracoes(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migracoes) == 0 || migracoes[0].Nome != "esquema_inicial" {
+		t.Fatalf("a primeira migração deve ser o esquema inicial, veio %+v", migracoes[0])
+	}
+	for i := 1; i < len(migracoes); i++ {
+		if migracoes[i].Versao <= migracoes[i-1].Versao {
+			t.Errorf("migrações fora de ordem: %d depois de %d", migracoes[i].Versao, migracoes[i-1].Versao)
+		}
+	}
+}
+
+// Um banco criado pelo AutoMigrate adota só o esquema inicial e recebe todo
+// o resto pelas migrações seguintes.
+func TestUpAdotaBancoDoAutoMigrate(t *testing.T) {
+	conn := dbteste.Abrir(t)
+	migracoes, err := db.CarregarMigracoes(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simula o AutoMigrate: as tabelas existem, sem nenhuma migração registrada
+	if err := conn.Exec(migracoes[0].Up).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	migrador, err := db.NewMigrador(conn, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aplicadas, err := migrador.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aplicadas != len(migracoes)-1 {
+		t.Errorf("aplicadas = %d, esperava %d (todas menos o esquema inicial)", aplicadas, len(migracoes)-1)
+	}
+
+	for _, tabela := range []string{"regra_alertas", "alerta_historicos", "evento_hubs", "estado_hubs", "pedidos_recorrentes"} {
+		var existe bool
+		conn.Raw(`SELECT to_regclass(?) IS NOT NULL`, tabela).Scan(&existe)
+		if !existe {
+			t.Errorf("tabela %s não foi criada", tabela)
+		}
+	}
+	var colunas int64
+	conn.Raw(`SELECT count(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'clientes' AND column_name = 'representante'`).Scan(&colunas)
+	if colunas != 1 {
+		t.Error("coluna clientes.representante não foi criada")
+	}
+	var cascata string
+	conn.Raw(`SELECT confdeltype::text FROM pg_constraint WHERE conname = 'fk_compras_cliente'
+		AND connamespace = current_schema()::regnamespace`).Scan(&cascata)
+	if cascata != "c" {
+		t.Errorf("fk_compras_cliente deveria ser ON DELETE CASCADE, confdeltype = %q", cascata)
+	}
+
+	pendentes, err := migrador.Pendentes(t.Context())
+	if err != nil || pendentes != 0 {
+		t.Errorf("Pendentes() = %d, %v", pendentes, err)
+	}
+}
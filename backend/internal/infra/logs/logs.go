@@ -0,0 +1,60 @@
+// Package logs configura o log estruturado da aplicação: JSON via log/slog,
+// com o request ID e o trace corrente tirados do context.Context.
+package logs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	chaveRequestID struct{}
+
+	// contextoHandler acrescenta request_id, trace_id e span_id aos
+	// registros feitos com um contexto que os carrega.
+	contextoHandler struct {
+		slog.Handler
+	}
+)
+
+// Configurar instala o logger JSON como padrão do slog e do pacote log.
+func Configurar(saida io.Writer, nivel slog.Level) *slog.Logger {
+	logger := slog.New(contextoHandler{slog.NewJSONHandler(saida, &slog.HandlerOptions{Level: nivel})})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// ComRequestID devolve ctx carregando o ID da requisição.
+func ComRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, chaveRequestID{}, id)
+}
+
+// RequestID devolve o ID da requisição em ctx, ou "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(chaveRequestID{}).(string)
+	return id
+}
+
+func (h contextoHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.Handler.Handle(ctx, r)
+	}
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if s := trace.SpanContextFromContext(ctx); s.IsValid() {
+		r.AddAttrs(slog.String("trace_id", s.TraceID().String()), slog.String("span_id", s.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextoHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextoHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextoHandler) WithGroup(nome string) slog.Handler {
+	return contextoHandler{h.Handler.WithGroup(nome)}
+}
@@ -0,0 +1,66 @@
+// Package rastreio configura o OpenTelemetry da aplicação: o TracerProvider
+// global, que exporta os spans em lotes para um coletor OTLP/HTTP, e a
+// propagação pelo W3C Trace Context.
+//
+// Sem endpoint os spans continuam sendo criados, para que os logs tenham
+// trace_id e span_id, mas são descartados ao finalizar.
+package rastreio
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"smart-retention/internal/config"
+)
+
+// escopo é o nome da instrumentação dos spans criados pela aplicação.
+const escopo = "smart-retention"
+
+// Configurar instala o provider global e devolve a função que exporta os
+// spans pendentes e o encerra, a ser chamada no desligamento.
+func Configurar(cfg config.Rastreio) (func(context.Context) error, error) {
+	recurso, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", cfg.Servico)))
+	if err != nil {
+		return nil, err
+	}
+
+	opcoes := []sdktrace.TracerProviderOption{sdktrace.WithResource(recurso)}
+	if cfg.Endpoint != "" {
+		exportador, err := otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces"))
+		if err != nil {
+			return nil, err
+		}
+		opcoes = append(opcoes, sdktrace.WithBatcher(exportador))
+	}
+
+	provider := sdktrace.NewTracerProvider(opcoes...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Iniciar abre um span interno filho do span em ctx (ou a raiz de um novo
+// trace). O span precisa ser finalizado com End.
+func Iniciar(ctx context.Context, nome string, atributos ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(escopo).Start(ctx, nome, trace.WithAttributes(atributos...))
+}
+
+// Erro marca o span como falho; err nil é ignorado.
+func Erro(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// AlertaHistorico registra um alerta desde a primeira vez em que foi gerado
+// até a compra que recuperou o cliente (RecuperadoEm) ou até deixar de ser
+// gerado sem compra (ResolvidoEm). Sem nenhum dos dois, está em aberto.
+type AlertaHistorico struct {
+	ID              string     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ClienteID       string     `gorm:"not null;index" json:"cliente_id"`
+	Tipo            string     `gorm:"not null" json:"tipo"`
+	RegraID         string     `json:"regra_id,omitempty"`
+	Motivo          string     `json:"motivo"`
+	Severidade      string     `json:"severidade"`
+	Prioridade      float64    `json:"prioridade"`
+	Representante   string     `json:"representante"`
+	GeradoEm        time.Time  `gorm:"not null;index" json:"gerado_em"`
+	VistoEm         time.Time  `gorm:"not null" json:"visto_em"`
+	RecuperadoEm    *time.Time `json:"recuperado_em"`
+	ResolvidoEm     *time.Time `json:"resolvido_em"`
+	CompraID        *string    `gorm:"type:uuid" json:"compra_id"`
+	ValorRecuperado float64    `json:"valor_recuperado"`
+	// ItensFaltantes são os nomes dos itens dos alertas de item faltando,
+	// atualizados enquanto o alerta está em aberto.
+	ItensFaltantes []string `gorm:"type:jsonb;serializer:json" json:"itens_faltantes,omitempty"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type (
+	// Feriado é um feriado cadastrado além dos nacionais, que são
+	// calculados: estaduais, municipais ou qualquer dia em que o comércio
+	// não abre. Vale para todos os clientes.
+	Feriado struct {
+		ID   string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+		Data time.Time `gorm:"not null;uniqueIndex:uni_feriados_data"` // à meia-noite UTC
+		Nome string    `gorm:"not null"`
+	}
+
+	// Fechamento é um período, de Inicio a Fim inclusive, em que o cliente
+	// avisou que estará fechado (férias coletivas, reforma, inventário).
+	Fechamento struct {
+		ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+		ClienteID string    `gorm:"type:uuid;not null;index"`
+		Inicio    time.Time `gorm:"not null"` // datas à meia-noite UTC
+		Fim       time.Time `gorm:"not null"`
+		Motivo    string
+		CriadoEm  time.Time `gorm:"not null"`
+	}
+)
@@ -4,14 +4,18 @@ import "time"
 
 type (
 	Cliente struct {
-		ID         string             `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-		CNPJ       string             `gorm:"unique;not null" json:"cnpj"`
-		Nome       string             `json:"nome"`
-		Telefone   string             `json:"telefone"`
-		Email      string             `json:"email"`
-		Endereco   string             `json:"endereco"`
-		Itens      []Item             `gorm:"many2many:cliente_itens" json:"itens"`
-		DiasCompra []DiaCompraCliente `gorm:"foreignKey:ClienteID;constraint:OnDelete:CASCADE" json:"dias_compra"`
+		ID            string `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+		CNPJ          string `gorm:"unique;not null" json:"cnpj"`
+		Nome          string `json:"nome"`
+		Telefone      string `json:"telefone"`
+		Email         string `json:"email"`
+		Endereco      string `json:"endereco"`
+		Representante string `json:"representante"` // vendedor responsável pela carteira
+		// FechaFacultativos indica que o cliente não abre nos pontos
+		// facultativos nacionais (carnaval, Corpus Christi)
+		FechaFacultativos bool               `gorm:"not null;default:false" json:"fecha_facultativos"`
+		Itens             []Item             `gorm:"many2many:cliente_itens" json:"itens"`
+		DiasCompra        []DiaCompraCliente `gorm:"foreignKey:ClienteID;constraint:OnDelete:CASCADE" json:"dias_compra"`
 	}
 
 	Item struct {
@@ -20,23 +24,42 @@ type (
 	}
 
 	Compra struct {
-		ID         string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-		ClienteID  string
-		Cliente    Cliente
-		DataCompra time.Time
-		Itens      []CompraItem
+		ID         string       `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+		ClienteID  string       `gorm:"index:idx_compras_cliente_data,priority:1" json:"cliente_id"`
+		Cliente    Cliente      `json:"-"`
+		DataCompra time.Time    `gorm:"index:idx_compras_cliente_data,priority:2" json:"data"`
+		Itens      []CompraItem `json:"itens"`
 	}
 
 	CompraItem struct {
-		ID       string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-		CompraID string
-		ItemID   string
-		Item     Item
-		Preco    float64
+		ID         string  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+		CompraID   string  `gorm:"index:idx_compra_items_item_compra,priority:2" json:"-"`
+		ItemID     string  `gorm:"index:idx_compra_items_item_compra,priority:1" json:"item_id"`
+		Item       Item    `json:"-"`
+		Quantidade float64 `gorm:"not null;default:1" json:"quantidade"`
+		Preco      float64 `json:"preco"` // valor da linha, não o unitário
 	}
 
+	// DiaCompraCliente é um dia da semana em que o cliente costuma comprar.
+	// A Frequencia diz em quais semanas: todas, uma sim outra não (contadas a
+	// partir da semana de Referencia) ou uma por mês (a SemanaDoMes, -1 para
+	// a última). HoraFim ("HH:MM", no fuso da aplicação) é o horário até o
+	// qual a compra é esperada, e a compra até ToleranciaDias dias antes ou
+	// depois do dia ainda conta como feita no dia.
 	DiaCompraCliente struct {
-		ClienteID string `gorm:"primaryKey" json:"-"`
-		DiaSemana int    `gorm:"primaryKey" json:"dia_semana"`
+		ClienteID      string     `gorm:"primaryKey" json:"-"`
+		DiaSemana      int        `gorm:"primaryKey" json:"dia_semana"`
+		Frequencia     string     `gorm:"not null;default:semanal" json:"frequencia"`
+		Referencia     *time.Time `json:"referencia"`
+		SemanaDoMes    int        `gorm:"not null;default:0" json:"semana_do_mes"`
+		HoraFim        string     `json:"hora_fim"`
+		ToleranciaDias int        `gorm:"not null;default:0" json:"tolerancia_dias"`
 	}
 )
+
+// Frequências de um dia de compra.
+const (
+	FrequenciaSemanal   = "semanal"
+	FrequenciaQuinzenal = "quinzenal"
+	FrequenciaMensal    = "mensal"
+)
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type (
+	// EventoHub é a fila de eventos do hub compartilhada entre as instâncias.
+	// O ID é a sequência global dos eventos.
+	EventoHub struct {
+		ID       uint64    `gorm:"primaryKey;autoIncrement"`
+		Payload  string    `gorm:"type:jsonb;not null"`
+		CriadoEm time.Time `gorm:"not null;index"`
+	}
+
+	// EstadoHub guarda os eventos retidos (alertas ativos) para que novas
+	// instâncias montem o snapshot sem esperar a próxima geração.
+	EstadoHub struct {
+		Chave   string `gorm:"primaryKey"`
+		Payload string `gorm:"type:jsonb;not null"`
+	}
+)
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+type (
+	// PedidoRecorrente é a cesta que o cliente costuma comprar, com a
+	// quantidade e o valor esperados de cada item. Inferido indica que foi
+	// calculado a partir das últimas compras, e não editado à mão.
+	PedidoRecorrente struct {
+		ClienteID    string                 `gorm:"type:uuid;primaryKey"`
+		Inferido     bool                   `gorm:"not null"`
+		AtualizadoEm time.Time              `gorm:"not null"`
+		Itens        []ItemPedidoRecorrente `gorm:"foreignKey:ClienteID;references:ClienteID"`
+	}
+
+	ItemPedidoRecorrente struct {
+		ClienteID  string `gorm:"type:uuid;primaryKey"`
+		ItemID     string `gorm:"type:uuid;primaryKey"`
+		Item       Item
+		Quantidade float64 `gorm:"not null"`
+		Preco      float64 `gorm:"not null"` // valor esperado da linha, como em CompraItem
+	}
+)
+
+// TableName evita o plural "pedido_recorrentes" que o GORM geraria.
+func (PedidoRecorrente) TableName() string {
+	return "pedidos_recorrentes"
+}
+
+func (ItemPedidoRecorrente) TableName() string {
+	return "pedido_recorrente_itens"
+}
+
+// Total soma o valor esperado dos itens.
+func (p *PedidoRecorrente) Total() float64 {
+	var total float64
+	for _, item := range p.Itens {
+		total += item.Preco
+	}
+	return total
+}
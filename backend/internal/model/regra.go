@@ -0,0 +1,11 @@
+package model
+
+// RegraAlerta é uma regra de alerta definida pelo usuário, escrita na
+// linguagem de expressões do pacote alerta.
+type RegraAlerta struct {
+	ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Nome      string `gorm:"not null" json:"nome"`
+	Expressao string `gorm:"not null" json:"expressao"`
+	Motivo    string `json:"motivo"`
+	Ativa     bool   `gorm:"not null" json:"ativa"`
+}
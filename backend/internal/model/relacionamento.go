@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Tipos de interação registrados com o cliente.
+const (
+	InteracaoNota     = "nota"
+	InteracaoLigacao  = "ligacao"
+	InteracaoVisita   = "visita"
+	InteracaoEmail    = "email"
+	InteracaoWhatsApp = "whatsapp"
+)
+
+type (
+	// Contato é uma pessoa do cliente com quem a equipe fala. No máximo um
+	// contato por cliente é o principal.
+	Contato struct {
+		ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+		ClienteID string `gorm:"type:uuid;not null;index"`
+		Nome      string `gorm:"not null"`
+		Cargo     string
+		Telefone  string
+		Email     string
+		Principal bool `gorm:"not null"`
+	}
+
+	// Interacao é um registro da linha do tempo do cliente: uma nota, uma
+	// ligação, uma visita. PausarAlertasAte, se informado, suspende os
+	// alertas do cliente até aquele dia, inclusive.
+	Interacao struct {
+		ID               string  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+		ClienteID        string  `gorm:"type:uuid;not null;index:idx_interacoes_cliente_data,priority:1"`
+		ContatoID        *string `gorm:"type:uuid"`
+		Contato          *Contato
+		Tipo             string     `gorm:"not null"`
+		Descricao        string     `gorm:"not null"`
+		Resultado        string     // o que ficou combinado ou aconteceu
+		Autor            string     // quem registrou
+		Data             time.Time  `gorm:"not null;index:idx_interacoes_cliente_data,priority:2"`
+		RetornoEm        *time.Time // data para voltar a falar com o cliente
+		PausarAlertasAte *time.Time
+		CriadaEm         time.Time `gorm:"not null"`
+	}
+)
+
+// TableName evita o plural "interacaos" que o GORM geraria.
+func (Interacao) TableName() string {
+	return "interacoes"
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Situações de uma tarefa. Abertas e em andamento contam como pendentes.
+const (
+	TarefaAberta      = "aberta"
+	TarefaEmAndamento = "em_andamento"
+	TarefaConcluida   = "concluida"
+	TarefaCancelada   = "cancelada"
+)
+
+type (
+	// Tarefa é um acompanhamento atribuído a alguém, normalmente criado a
+	// partir de um alerta. AlertaHistoricoID liga a tarefa ao alerta que a
+	// originou, enquanto o registro do alerta existir.
+	Tarefa struct {
+		ID                string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+		ClienteID         string `gorm:"type:uuid;not null;index"`
+		Cliente           Cliente
+		AlertaHistoricoID *string `gorm:"type:uuid;index"`
+		TipoAlerta        string
+		Titulo            string `gorm:"not null"`
+		Descricao         string
+		Responsavel       string    `gorm:"index:idx_tarefas_responsavel_status,priority:1"`
+		Status            string    `gorm:"not null;index:idx_tarefas_responsavel_status,priority:2"`
+		Vencimento        time.Time `gorm:"not null"` // data, à meia-noite UTC
+		CriadaEm          time.Time `gorm:"not null"`
+		ConcluidaEm       *time.Time
+		LembradaEm        *time.Time // dia do último lembrete de atraso, à meia-noite UTC
+	}
+
+	// RegraTarefa cria automaticamente uma tarefa para cada novo alerta do
+	// tipo, vencendo PrazoDias depois. Sem Responsavel, a tarefa vai para o
+	// representante do cliente.
+	RegraTarefa struct {
+		TipoAlerta  string `gorm:"primaryKey"`
+		Ativa       bool   `gorm:"not null"`
+		PrazoDias   int    `gorm:"not null"`
+		Responsavel string
+	}
+)
+
+// Pendente informa se a tarefa ainda precisa ser feita.
+func (t *Tarefa) Pendente() bool {
+	return t.Status == TarefaAberta || t.Status == TarefaEmAndamento
+}
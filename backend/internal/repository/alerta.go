@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/model"
+)
+
+type (
+	// GrupoEfetividade é a coluna do histórico pela qual a efetividade é
+	// agregada.
+	GrupoEfetividade string
+
+	EfetividadeAlertas struct {
+		Grupo                  string   `json:"grupo"`
+		Total                  int      `json:"total"`
+		Recuperados            int      `json:"recuperados"`
+		TaxaRecuperacao        float64  `json:"taxa_recuperacao"`
+		MedianaDiasRecuperacao *float64 `json:"mediana_dias_recuperacao"`
+		ReceitaRecuperada      float64  `json:"receita_recuperada"`
+	}
+
+	alertaGorm struct {
+		db *gorm.DB
+	}
+)
+
+const (
+	PorTipo          GrupoEfetividade = "tipo"
+	PorRepresentante GrupoEfetividade = "representante"
+)
+
+// consultaEfetividade agrega o histórico de alertas gerados no período pela
+// coluna informada. A receita é somada por compra distinta, para que uma
+// compra que fechou vários alertas do mesmo grupo não seja contada duas vezes.
+const consultaEfetividade = `
+	WITH periodo AS (
+		SELECT %[1]s AS grupo, recuperado_em, gerado_em, compra_id, valor_recuperado
+		FROM alerta_historicos
+		WHERE gerado_em >= ? AND gerado_em < ?
+	), receita AS (
+		SELECT grupo, SUM(valor_recuperado) AS receita_recuperada
+		FROM (SELECT DISTINCT grupo, compra_id, valor_recuperado FROM periodo WHERE compra_id IS NOT NULL) r
+		GROUP BY grupo
+	)
+	SELECT p.grupo,
+		COUNT(*) AS total,
+		COUNT(p.recuperado_em) AS recuperados,
+		percentile_cont(0.5) WITHIN GROUP (
+			ORDER BY GREATEST(EXTRACT(EPOCH FROM p.recuperado_em - p.gerado_em), 0) / 86400
+		) FILTER (WHERE p.recuperado_em IS NOT NULL) AS mediana_dias_recuperacao,
+		COALESCE(MAX(r.receita_recuperada), 0) AS receita_recuperada
+	FROM periodo p
+	LEFT JOIN receita r ON r.grupo = p.grupo
+	GROUP BY p.grupo
+	ORDER BY p.grupo
+`
+
+func (r *alertaGorm) RegistrarHistorico(ctx context.Context, alertas []alerta.Alerta, agora time.Time) error {
+	return registrarHistorico(r.db.WithContext(ctx), alertas, agora)
+}
+
+func (r *alertaGorm) Efetividade(ctx context.Context, grupo GrupoEfetividade, desde, ate time.Time) ([]EfetividadeAlertas, error) {
+	if grupo != PorTipo && grupo != PorRepresentante {
+		return nil, fmt.Errorf("grupo de efetividade desconhecido: %q", grupo)
+	}
+
+	var linhas []EfetividadeAlertas
+	if err := r.db.WithContext(ctx).Raw(fmt.Sprintf(consultaEfetividade, grupo), desde, ate).Scan(&linhas).Error; err != nil {
+		return nil, err
+	}
+	return linhas, nil
+}
+
+// registrarHistorico grava no histórico os alertas gerados agora, que devem ser
+// todos os alertas atuais. Um alerta que já está em aberto para o cliente
+// (mesmo tipo e regra) só tem o horário de visto atualizado; os demais abrem
+// um novo registro. Os registros em aberto que não estão mais entre os
+// alertas são fechados como resolvidos, sem compra.
+func registrarHistorico(db *gorm.DB, alertas []alerta.Alerta, agora time.Time) error {
+	var abertos []model.AlertaHistorico
+	if err := db.Select("id", "cliente_id", "tipo", "regra_id", "itens_faltantes").
+		Where("recuperado_em IS NULL AND resolvido_em IS NULL").
+		Find(&abertos).Error; err != nil {
+		return err
+	}
+
+	porChave := make(map[string]model.AlertaHistorico, len(abertos))
+	for _, h := range abertos {
+		porChave[alerta.Alerta{ClienteID: h.ClienteID, Tipo: h.Tipo, RegraID: h.RegraID}.Chave()] = h
+	}
+
+	var (
+		vistos     []string
+		faltantes  = map[string][]string{} // itens que mudaram nos vistos
+		novos      []model.AlertaHistorico
+		resolvidos []string
+	)
+	for _, a := range alertas {
+		if h, ok := porChave[a.Chave()]; ok {
+			vistos = append(vistos, h.ID)
+			if !slices.Equal(h.ItensFaltantes, a.ItensFaltantes) {
+				faltantes[h.ID] = a.ItensFaltantes
+			}
+			delete(porChave, a.Chave())
+			continue
+		}
+		novos = append(novos, model.AlertaHistorico{
+			ClienteID:      a.ClienteID,
+			Tipo:           a.Tipo,
+			RegraID:        a.RegraID,
+			Motivo:         a.Motivo,
+			Severidade:     string(a.Severidade),
+			Prioridade:     a.Prioridade,
+			Representante:  a.Representante,
+			GeradoEm:       agora,
+			VistoEm:        agora,
+			ItensFaltantes: a.ItensFaltantes,
+		})
+	}
+
+	for _, h := range porChave {
+		resolvidos = append(resolvidos, h.ID)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(resolvidos) > 0 {
+			if err := tx.Model(&model.AlertaHistorico{}).
+				Where("id IN ?", resolvidos).
+				Update("resolvido_em", agora).Error; err != nil {
+				return err
+			}
+		}
+		if len(vistos) > 0 {
+			if err := tx.Model(&model.AlertaHistorico{}).
+				Where("id IN ?", vistos).
+				Update("visto_em", agora).Error; err != nil {
+				return err
+			}
+		}
+		for id, itens := range faltantes {
+			if err := tx.Model(&model.AlertaHistorico{ID: id}).
+				Select("ItensFaltantes").
+				Updates(&model.AlertaHistorico{ItensFaltantes: itens}).Error; err != nil {
+				return err
+			}
+		}
+		if len(novos) > 0 {
+			if err := tx.CreateInBatches(&novos, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// registrarRecuperacao fecha os alertas em aberto do cliente gerados até o
+// dia da compra, atribuindo a eles a compra e o seu valor. Os já resolvidos
+// sem compra não contam como recuperados. Um alerta de item faltando só é
+// recuperado por uma compra com um dos itens que faltavam, então os itens da
+// compra precisam estar gravados; os registros anteriores à lista de itens
+// aceitam qualquer compra.
+func registrarRecuperacao(db *gorm.DB, compra model.Compra, valor float64) error {
+	return db.Model(&model.AlertaHistorico{}).
+		Where("cliente_id = ? AND recuperado_em IS NULL AND resolvido_em IS NULL AND gerado_em < ?", compra.ClienteID, compra.DataCompra.AddDate(0, 0, 1)).
+		Where(`tipo <> ? OR itens_faltantes IS NULL OR EXISTS (
+			SELECT 1 FROM compra_items ci JOIN items i ON i.id = ci.item_id
+			WHERE ci.compra_id = ? AND alerta_historicos.itens_faltantes @> jsonb_build_array(i.nome))`,
+			alerta.TipoItemFaltando, compra.ID).
+		Updates(map[string]any{
+			"recuperado_em":    compra.DataCompra,
+			"compra_id":        compra.ID,
+			"valor_recuperado": valor,
+		}).Error
+}
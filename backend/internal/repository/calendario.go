@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/model"
+)
+
+type (
+	feriadoGorm struct {
+		db *gorm.DB
+	}
+
+	fechamentoGorm struct {
+		db *gorm.DB
+	}
+)
+
+func (r *feriadoGorm) Listar(ctx context.Context, de, ate time.Time) ([]model.Feriado, error) {
+	var feriados []model.Feriado
+	if err := r.db.WithContext(ctx).
+		Where("data >= ? AND data < ?", de, ate).
+		Order("data").
+		Find(&feriados).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return feriados, nil
+}
+
+func (r *feriadoGorm) Criar(ctx context.Context, feriado *model.Feriado) error {
+	return traduzir(r.db.WithContext(ctx).Create(feriado).Error, nil)
+}
+
+func (r *feriadoGorm) Deletar(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Delete(&model.Feriado{}, "id = ?", id)
+	if res.Error != nil {
+		return traduzirExclusao(res.Error, ErrFeriadoNaoEncontrado)
+	}
+	if res.RowsAffected == 0 {
+		return ErrFeriadoNaoEncontrado
+	}
+	return nil
+}
+
+func (r *fechamentoGorm) ListarPorCliente(ctx context.Context, clienteID string) ([]model.Fechamento, error) {
+	var fechamentos []model.Fechamento
+	if err := r.db.WithContext(ctx).
+		Where("cliente_id = ?", clienteID).
+		Order("inicio DESC").
+		Find(&fechamentos).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return fechamentos, nil
+}
+
+func (r *fechamentoGorm) Criar(ctx context.Context, fechamento *model.Fechamento) error {
+	return traduzir(r.db.WithContext(ctx).Create(fechamento).Error, nil)
+}
+
+func (r *fechamentoGorm) Deletar(ctx context.Context, clienteID, id string) error {
+	res := r.db.WithContext(ctx).Delete(&model.Fechamento{}, "id = ? AND cliente_id = ?", id, clienteID)
+	if res.Error != nil {
+		return traduzirExclusao(res.Error, ErrFechamentoNaoEncontrado)
+	}
+	if res.RowsAffected == 0 {
+		return ErrFechamentoNaoEncontrado
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/model"
+)
+
+type clienteGorm struct {
+	db *gorm.DB
+}
+
+func (r *clienteGorm) Listar(ctx context.Context) ([]model.Cliente, error) {
+	var clientes []model.Cliente
+	if err := r.db.WithContext(ctx).Preload("Itens").Preload("DiasCompra").Find(&clientes).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return clientes, nil
+}
+
+func (r *clienteGorm) BuscarPorID(ctx context.Context, id string) (*model.Cliente, error) {
+	var cliente model.Cliente
+	if err := r.db.WithContext(ctx).Preload("Itens").Preload("DiasCompra").First(&cliente, "id = ?", id).Error; err != nil {
+		return nil, traduzir(err, ErrClienteNaoEncontrado)
+	}
+	return &cliente, nil
+}
+
+func (r *clienteGorm) Criar(ctx context.Context, cliente *model.Cliente) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dias := cliente.DiasCompra
+		cliente.DiasCompra = nil
+
+		// Cria cliente com itens
+		if err := tx.Create(cliente).Error; err != nil {
+			return err
+		}
+
+		return salvarDiasCompra(tx, cliente.ID, dias)
+	})
+	return traduzir(err, nil)
+}
+
+func (r *clienteGorm) Atualizar(ctx context.Context, cliente *model.Cliente) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(cliente).Select("Nome", "CNPJ", "Telefone", "Email", "Endereco", "Representante", "FechaFacultativos").Updates(cliente)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrClienteNaoEncontrado
+		}
+
+		// 🔁 Atualiza relação muitos-para-muitos: cliente_itens
+		itens := cliente.Itens
+		if err := tx.Model(cliente).Association("Itens").Clear(); err != nil {
+			return err
+		}
+		if len(itens) > 0 {
+			if err := tx.Model(cliente).Association("Itens").Replace(itens); err != nil {
+				return err
+			}
+		}
+
+		return salvarDiasCompra(tx, cliente.ID, cliente.DiasCompra)
+	})
+	return traduzir(err, nil)
+}
+
+func (r *clienteGorm) Deletar(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cliente := model.Cliente{ID: id}
+
+		// Remove associação many2many explicitamente (cliente_itens)
+		if err := tx.Model(&cliente).Association("Itens").Clear(); err != nil {
+			return err
+		}
+
+		// Dias de compra e compras caem em cascata
+		res := tx.Delete(&cliente)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrClienteNaoEncontrado
+		}
+		return nil
+	})
+	return traduzirExclusao(err, ErrClienteNaoEncontrado)
+}
+
+// salvarDiasCompra substitui os dias de compra do cliente.
+func salvarDiasCompra(tx *gorm.DB, clienteID string, dias []model.DiaCompraCliente) error {
+	if err := tx.Where("cliente_id = ?", clienteID).Delete(&model.DiaCompraCliente{}).Error; err != nil {
+		return err
+	}
+	if len(dias) == 0 {
+		return nil
+	}
+	for i := range dias {
+		dias[i].ClienteID = clienteID
+	}
+	return tx.Create(&dias).Error
+}
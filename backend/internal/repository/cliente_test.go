@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"smart-retention/internal/infra/db/dbteste"
+	"smart-retention/internal/model"
+)
+
+func nomesItens(itens []model.Item) []string {
+	nomes := make([]string, 0, len(itens))
+	for _, item := range itens {
+		nomes = append(nomes, item.Nome)
+	}
+	slices.Sort(nomes)
+	return nomes
+}
+
+func TestClienteGormCriarEAtualizar(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+	r := &clienteGorm{db: conn}
+	ctx := context.Background()
+
+	// Um item existente, pelo ID, e um novo, pelo nome
+	cliente := &model.Cliente{
+		CNPJ:  "3",
+		Nome:  "Carlos",
+		Itens: []model.Item{{ID: itemArroz}, {Nome: "Café"}},
+		DiasCompra: []model.DiaCompraCliente{
+			{DiaSemana: 1, Frequencia: model.FrequenciaSemanal, HoraFim: "10:00"},
+			{DiaSemana: 4, Frequencia: model.FrequenciaMensal, SemanaDoMes: -1, ToleranciaDias: 1},
+		},
+	}
+	if err := r.Criar(ctx, cliente); err != nil {
+		t.Fatal(err)
+	}
+
+	criado, err := r.BuscarPorID(ctx, cliente.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nomes := nomesItens(criado.Itens); !slices.Equal(nomes, []string{"Arroz", "Café"}) {
+		t.Errorf("itens = %v, esperado Arroz e Café", nomes)
+	}
+	dias := map[int]model.DiaCompraCliente{}
+	for _, d := range criado.DiasCompra {
+		dias[d.DiaSemana] = d
+	}
+	if len(dias) != 2 || dias[1].HoraFim != "10:00" || dias[4].SemanaDoMes != -1 || dias[4].ToleranciaDias != 1 {
+		t.Errorf("dias de compra = %+v", criado.DiasCompra)
+	}
+	var itens int64
+	conn.Model(&model.Item{}).Count(&itens)
+	if itens != 3 {
+		t.Errorf("%d itens cadastrados, esperado 3: o Arroz não pode ser duplicado", itens)
+	}
+
+	// A atualização substitui itens e dias de compra
+	criado.Nome = "Carlos Ltda"
+	criado.Itens = []model.Item{{ID: itemFeijao}}
+	criado.DiasCompra = []model.DiaCompraCliente{{DiaSemana: 2, Frequencia: model.FrequenciaSemanal}}
+	if err := r.Atualizar(ctx, criado); err != nil {
+		t.Fatal(err)
+	}
+
+	atualizado, err := r.BuscarPorID(ctx, cliente.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atualizado.Nome != "Carlos Ltda" {
+		t.Errorf("nome = %q", atualizado.Nome)
+	}
+	if nomes := nomesItens(atualizado.Itens); !slices.Equal(nomes, []string{"Feijão"}) {
+		t.Errorf("itens = %v, esperado só Feijão", nomes)
+	}
+	if len(atualizado.DiasCompra) != 1 || atualizado.DiasCompra[0].DiaSemana != 2 {
+		t.Errorf("dias de compra = %+v, esperado só a terça", atualizado.DiasCompra)
+	}
+
+	// Sem itens nem dias, a atualização limpa os dois
+	atualizado.Itens = nil
+	atualizado.DiasCompra = nil
+	if err := r.Atualizar(ctx, atualizado); err != nil {
+		t.Fatal(err)
+	}
+	vazio, err := r.BuscarPorID(ctx, cliente.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vazio.Itens) != 0 || len(vazio.DiasCompra) != 0 {
+		t.Errorf("itens %+v e dias %+v, esperado nenhum", vazio.Itens, vazio.DiasCompra)
+	}
+
+	// Os clientes do popularMotor não são afetados
+	ana, err := r.BuscarPorID(ctx, clienteAna)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ana.Itens) != 2 {
+		t.Errorf("itens da Ana = %+v", ana.Itens)
+	}
+}
+
+func TestClienteGormAtualizarInexistente(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	r := &clienteGorm{db: conn}
+
+	cliente := &model.Cliente{ID: "00000000-0000-0000-0000-0000000000ff", CNPJ: "9", Nome: "Ninguém"}
+	if err := r.Atualizar(context.Background(), cliente); !errors.Is(err, ErrClienteNaoEncontrado) {
+		t.Errorf("erro = %v, esperado cliente não encontrado", err)
+	}
+}
+
+// Falhar ao gravar os dias de compra desfaz o cliente.
+func TestClienteGormCriarDesfazComDiaInvalido(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	r := &clienteGorm{db: conn}
+
+	cliente := &model.Cliente{
+		CNPJ:       "9",
+		Nome:       "Dia repetido",
+		DiasCompra: []model.DiaCompraCliente{{DiaSemana: 1, Frequencia: model.FrequenciaSemanal}, {DiaSemana: 1, Frequencia: model.FrequenciaSemanal}},
+	}
+	if err := r.Criar(context.Background(), cliente); err == nil {
+		t.Fatal("o cliente foi criado com um dia de compra repetido")
+	}
+	var clientes int64
+	conn.Model(&model.Cliente{}).Count(&clientes)
+	if clientes != 0 {
+		t.Errorf("%d clientes gravados, esperado nenhum", clientes)
+	}
+}
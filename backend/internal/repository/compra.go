@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/model"
+)
+
+type compraGorm struct {
+	db *gorm.DB
+}
+
+func (r *compraGorm) Criar(ctx context.Context, compra *model.Compra) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		itens := compra.Itens
+		compra.Itens = nil
+		if err := tx.Omit("Cliente").Create(compra).Error; err != nil {
+			return err
+		}
+
+		var total float64
+		for i := range itens {
+			itens[i].CompraID = compra.ID
+			if err := tx.Omit("Item").Create(&itens[i]).Error; err != nil {
+				return err
+			}
+			total += itens[i].Preco
+		}
+		compra.Itens = itens
+
+		// Fecha os alertas em aberto do cliente: a compra conta como recuperação
+		return registrarRecuperacao(tx, *compra, total)
+	})
+	return traduzir(err, nil)
+}
+
+func (r *compraGorm) BuscarPorID(ctx context.Context, id string) (*model.Compra, error) {
+	var compra model.Compra
+	if err := r.db.WithContext(ctx).Preload("Cliente").Preload("Itens.Item").First(&compra, "id = ?", id).Error; err != nil {
+		return nil, traduzir(err, ErrCompraNaoEncontrada)
+	}
+	return &compra, nil
+}
+
+func (r *compraGorm) Listar(ctx context.Context) ([]model.Compra, error) {
+	var compras []model.Compra
+	if err := r.db.WithContext(ctx).Preload("Cliente").Preload("Itens.Item").Find(&compras).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return compras, nil
+}
+
+func (r *compraGorm) ListarPorCliente(ctx context.Context, clienteID string) ([]model.Compra, error) {
+	var compras []model.Compra
+	if err := r.db.WithContext(ctx).
+		Preload("Itens.Item").
+		Where("cliente_id = ?", clienteID).
+		Order("data_compra DESC").
+		Find(&compras).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return compras, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"smart-retention/internal/falha"
+	"smart-retention/internal/infra/db/dbteste"
+	"smart-retention/internal/model"
+)
+
+func TestCompraGormCriarRecuperaAlertas(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+	resolvido := dia("2026-10-18")
+	alertas := []model.AlertaHistorico{
+		{ClienteID: clienteAna, Tipo: "inatividade", GeradoEm: dia("2026-10-18"), VistoEm: dia("2026-10-19")},
+		// Gerado depois da compra
+		{ClienteID: clienteAna, Tipo: "dia_previsto", GeradoEm: dia("2026-10-21"), VistoEm: dia("2026-10-21")},
+		// Já resolvido sem compra
+		{ClienteID: clienteAna, Tipo: "compra_incompleta", GeradoEm: dia("2026-10-15"), VistoEm: dia("2026-10-17"), ResolvidoEm: &resolvido},
+		// De outro cliente
+		{ClienteID: clienteBruno, Tipo: "inatividade", GeradoEm: dia("2026-10-18"), VistoEm: dia("2026-10-19")},
+	}
+	if err := conn.Create(&alertas).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := &compraGorm{db: conn}
+	compra := &model.Compra{ClienteID: clienteAna, DataCompra: dia("2026-10-20"), Itens: []model.CompraItem{
+		{ItemID: itemArroz, Quantidade: 2, Preco: 30},
+		{ItemID: itemFeijao, Quantidade: 1, Preco: 12.5},
+	}}
+	if err := r.Criar(context.Background(), compra); err != nil {
+		t.Fatal(err)
+	}
+
+	criada, err := r.BuscarPorID(context.Background(), compra.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if criada.Cliente.Nome != "Ana" || len(criada.Itens) != 2 || criada.Itens[0].Item.Nome == "" {
+		t.Errorf("compra relida = %+v", criada)
+	}
+
+	var historico []model.AlertaHistorico
+	if err := conn.Order("cliente_id, gerado_em").Find(&historico).Error; err != nil {
+		t.Fatal(err)
+	}
+	recuperados := map[string]bool{}
+	for _, h := range historico {
+		if h.RecuperadoEm == nil {
+			continue
+		}
+		recuperados[h.ClienteID+" "+h.Tipo] = true
+		if !h.RecuperadoEm.Equal(dia("2026-10-20")) || h.CompraID == nil || *h.CompraID != compra.ID || h.ValorRecuperado != 42.5 {
+			t.Errorf("recuperação = %+v", h)
+		}
+	}
+	if len(recuperados) != 1 || !recuperados[clienteAna+" inatividade"] {
+		t.Errorf("recuperados = %v, esperado só a inatividade da Ana", recuperados)
+	}
+}
+
+// Um item inexistente desfaz a compra inteira, inclusive a recuperação dos
+// alertas.
+func TestCompraGormCriarDesfazComItemInexistente(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+	aberto := model.AlertaHistorico{ClienteID: clienteAna, Tipo: "inatividade", GeradoEm: dia("2026-10-18"), VistoEm: dia("2026-10-19")}
+	if err := conn.Create(&aberto).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := &compraGorm{db: conn}
+	compra := &model.Compra{ClienteID: clienteAna, DataCompra: dia("2026-10-20"), Itens: []model.CompraItem{
+		{ItemID: itemArroz, Quantidade: 1, Preco: 30},
+		{ItemID: "00000000-0000-0000-0000-0000000000ff", Quantidade: 1, Preco: 5},
+	}}
+	err := r.Criar(context.Background(), compra)
+	if e := falha.De(err); e.Codigo != falha.CodigoReferenciaInvalida || len(e.Campos) != 1 || e.Campos[0].Nome != "itens.item_id" {
+		t.Fatalf("erro = %+v, esperado item não encontrado", e)
+	}
+
+	var compras int64
+	conn.Model(&model.Compra{}).Where("data_compra = ?", dia("2026-10-20")).Count(&compras)
+	if compras != 0 {
+		t.Errorf("%d compras gravadas, esperado nenhuma", compras)
+	}
+	if err := conn.First(&aberto, "id = ?", aberto.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if aberto.RecuperadoEm != nil {
+		t.Errorf("alerta recuperado por uma compra desfeita: %+v", aberto)
+	}
+}
+
+// Um alerta de item faltando só é recuperado por uma compra com um dos itens
+// que faltavam; os demais alertas, por qualquer compra.
+func TestCompraGormCriarRecuperaItemFaltandoSoComOItem(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+	faltando := model.AlertaHistorico{ClienteID: clienteAna, Tipo: "item_faltando", ItensFaltantes: []string{"Feijão"},
+		GeradoEm: dia("2026-10-18"), VistoEm: dia("2026-10-19")}
+	inativo := model.AlertaHistorico{ClienteID: clienteAna, Tipo: "inatividade", GeradoEm: dia("2026-10-18"), VistoEm: dia("2026-10-19")}
+	if err := conn.Create(&[]*model.AlertaHistorico{&faltando, &inativo}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := &compraGorm{db: conn}
+	soArroz := &model.Compra{ClienteID: clienteAna, DataCompra: dia("2026-10-20"), Itens: []model.CompraItem{
+		{ItemID: itemArroz, Quantidade: 1, Preco: 30},
+	}}
+	if err := r.Criar(context.Background(), soArroz); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.First(&faltando, "id = ?", faltando.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.First(&inativo, "id = ?", inativo.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if faltando.RecuperadoEm != nil {
+		t.Errorf("item faltando recuperado por uma compra sem o feijão: %+v", faltando)
+	}
+	if inativo.CompraID == nil || *inativo.CompraID != soArroz.ID {
+		t.Errorf("inatividade não recuperada pela compra: %+v", inativo)
+	}
+
+	comFeijao := &model.Compra{ClienteID: clienteAna, DataCompra: dia("2026-10-22"), Itens: []model.CompraItem{
+		{ItemID: itemArroz, Quantidade: 1, Preco: 30},
+		{ItemID: itemFeijao, Quantidade: 2, Preco: 25},
+	}}
+	if err := r.Criar(context.Background(), comFeijao); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.First(&faltando, "id = ?", faltando.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if faltando.CompraID == nil || *faltando.CompraID != comFeijao.ID || faltando.ValorRecuperado != 55 {
+		t.Errorf("item faltando = %+v, esperado recuperado pela compra com o feijão", faltando)
+	}
+}
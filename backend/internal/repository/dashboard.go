@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/model"
+)
+
+type (
+	Dashboard struct {
+		TotalClientes      int                    `json:"total_clientes"`
+		TotalCompras       int                    `json:"total_compras"`
+		ComprasPorMes      []QuantidadePorPeriodo `json:"compras_por_mes"`
+		ItensMaisComprados []QuantidadePorItem    `json:"itens_mais_comprados"`
+		ClientesMaisAtivos []QuantidadePorCliente `json:"clientes_mais_ativos"`
+	}
+
+	QuantidadePorPeriodo struct {
+		Mes        string `json:"mes"`
+		Quantidade int    `json:"quantidade"`
+	}
+
+	QuantidadePorItem struct {
+		Nome       string `json:"nome"`
+		Quantidade int    `json:"quantidade"`
+	}
+
+	QuantidadePorCliente struct {
+		Nome       string `json:"nome"`
+		Quantidade int    `json:"quantidade"`
+	}
+
+	dashboardGorm struct {
+		db *gorm.DB
+	}
+)
+
+func (r *dashboardGorm) Resumo(ctx context.Context) (*Dashboard, error) {
+	db := r.db.WithContext(ctx)
+
+	var totalClientes, totalCompras int64
+	if err := db.Model(&model.Cliente{}).Count(&totalClientes).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&model.Compra{}).Count(&totalCompras).Error; err != nil {
+		return nil, err
+	}
+
+	d := &Dashboard{
+		TotalClientes: int(totalClientes),
+		TotalCompras:  int(totalCompras),
+	}
+
+	// Compras por mês (YYYY-MM)
+	if err := db.Raw(`
+		SELECT TO_CHAR(data_compra, 'YYYY-MM') as mes, COUNT(*) as quantidade
+		FROM compras
+		GROUP BY mes
+		ORDER BY mes DESC
+	`).Scan(&d.ComprasPorMes).Error; err != nil {
+		return nil, err
+	}
+
+	// Itens mais comprados
+	if err := db.Raw(`
+		SELECT i.nome, COUNT(*) as quantidade
+		FROM compra_items ci
+		JOIN items i ON i.id = ci.item_id
+		GROUP BY i.nome
+		ORDER BY quantidade DESC
+		LIMIT 5
+	`).Scan(&d.ItensMaisComprados).Error; err != nil {
+		return nil, err
+	}
+
+	// Clientes mais ativos
+	if err := db.Raw(`
+		SELECT c.nome, COUNT(*) as quantidade
+		FROM compras co
+		JOIN clientes c ON c.id = co.cliente_id
+		GROUP BY c.nome
+		ORDER BY quantidade DESC
+		LIMIT 5
+	`).Scan(&d.ClientesMaisAtivos).Error; err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
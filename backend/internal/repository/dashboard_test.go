@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"smart-retention/internal/infra/db/dbteste"
+)
+
+func TestDashboardGormResumo(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+	// Mais uma compra de arroz da Ana em outubro
+	comandos := []string{
+		`INSERT INTO compras (id, cliente_id, data_compra) VALUES
+			('00000000-0000-0000-0000-000000001015', '` + clienteAna + `', '2026-10-15T00:00:00Z')`,
+		`INSERT INTO compra_items (compra_id, item_id, preco) VALUES
+			('00000000-0000-0000-0000-000000001015', '` + itemArroz + `', 30)`,
+	}
+	for _, sql := range comandos {
+		if err := conn.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := (&dashboardGorm{db: conn}).Resumo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.TotalClientes != 2 || d.TotalCompras != 3 {
+		t.Errorf("totais = %d clientes e %d compras, esperado 2 e 3", d.TotalClientes, d.TotalCompras)
+	}
+	if esperado := []QuantidadePorPeriodo{{"2026-10", 2}, {"2026-07", 1}}; !slices.Equal(d.ComprasPorMes, esperado) {
+		t.Errorf("compras por mês = %+v, esperado %+v", d.ComprasPorMes, esperado)
+	}
+	if esperado := []QuantidadePorItem{{"Arroz", 2}, {"Feijão", 1}}; !slices.Equal(d.ItensMaisComprados, esperado) {
+		t.Errorf("itens mais comprados = %+v, esperado %+v", d.ItensMaisComprados, esperado)
+	}
+	// Bruno nunca comprou e fica fora
+	if esperado := []QuantidadePorCliente{{"Ana", 3}}; !slices.Equal(d.ClientesMaisAtivos, esperado) {
+		t.Errorf("clientes mais ativos = %+v, esperado %+v", d.ClientesMaisAtivos, esperado)
+	}
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/model"
+)
+
+// motorAlertaGorm implementa alerta.Repositorio, as consultas do motor de
+// alertas.
+type motorAlertaGorm struct {
+	db *gorm.DB
+}
+
+func (r *motorAlertaGorm) Feriados(ctx context.Context) ([]time.Time, error) {
+	var feriados []struct {
+		Data time.Time
+	}
+	if err := r.db.WithContext(ctx).Raw(`SELECT data FROM feriados ORDER BY data`).Scan(&feriados).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+
+	datas := make([]time.Time, len(feriados))
+	for i, f := range feriados {
+		datas[i] = f.Data
+	}
+	return datas, nil
+}
+
+func (r *motorAlertaGorm) Fechamentos(ctx context.Context) ([]alerta.Fechamento, error) {
+	var fechamentos []alerta.Fechamento
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT cliente_id, inicio, fim
+		FROM fechamentos
+		ORDER BY cliente_id, inicio
+	`).Scan(&fechamentos).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return fechamentos, nil
+}
+
+func (r *motorAlertaGorm) FechamFacultativos(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).Raw(`SELECT id FROM clientes WHERE fecha_facultativos`).Scan(&ids).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return ids, nil
+}
+
+func (r *motorAlertaGorm) DiasCompra(ctx context.Context) ([]alerta.DiaCompra, error) {
+	var linhas []struct {
+		ClienteID      string
+		NomeCliente    string
+		DiaSemana      int
+		Frequencia     string
+		Referencia     *time.Time
+		SemanaDoMes    int
+		HoraFim        sql.NullString
+		ToleranciaDias int
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id AS cliente_id, c.nome AS nome_cliente, d.dia_semana, d.frequencia, d.referencia,
+			d.semana_do_mes, d.hora_fim, d.tolerancia_dias
+		FROM clientes c
+		JOIN dia_compra_clientes d ON d.cliente_id = c.id
+		ORDER BY c.id, d.dia_semana
+	`).Scan(&linhas).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+
+	dias := make([]alerta.DiaCompra, len(linhas))
+	for i, l := range linhas {
+		dias[i] = alerta.DiaCompra{
+			ClienteID:      l.ClienteID,
+			NomeCliente:    l.NomeCliente,
+			DiaSemana:      l.DiaSemana,
+			Frequencia:     l.Frequencia,
+			Referencia:     l.Referencia,
+			SemanaDoMes:    l.SemanaDoMes,
+			HoraFim:        l.HoraFim.String,
+			ToleranciaDias: l.ToleranciaDias,
+		}
+	}
+	return dias, nil
+}
+
+func (r *motorAlertaGorm) Clientes(ctx context.Context) ([]alerta.ClienteCompra, error) {
+	var clientes []alerta.ClienteCompra
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id, c.nome,
+			(SELECT MAX(co.data_compra) FROM compras co WHERE co.cliente_id = c.id) AS ultima_compra
+		FROM clientes c
+		ORDER BY c.nome
+	`).Scan(&clientes).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return clientes, nil
+}
+
+func (r *motorAlertaGorm) UltimasCompras(ctx context.Context, ids []string, ate time.Time) (map[string]time.Time, error) {
+	ultimas := make(map[string]time.Time)
+	if len(ids) == 0 {
+		return ultimas, nil
+	}
+
+	var linhas []struct {
+		ClienteID    string
+		UltimaCompra time.Time
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT cliente_id, MAX(data_compra) AS ultima_compra
+		FROM compras
+		WHERE cliente_id IN ? AND data_compra < ?
+		GROUP BY cliente_id
+	`, ids, ate).Scan(&linhas).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	for _, l := range linhas {
+		ultimas[l.ClienteID] = l.UltimaCompra
+	}
+	return ultimas, nil
+}
+
+func (r *motorAlertaGorm) ComprasDesde(ctx context.Context, desde time.Time) ([]alerta.Compra, error) {
+	var linhas []struct {
+		CompraID   string
+		ClienteID  string
+		DataCompra time.Time
+		NomeItem   sql.NullString
+		Preco      sql.NullFloat64
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT co.id AS compra_id, co.cliente_id, co.data_compra, i.nome AS nome_item, ci.preco
+		FROM compras co
+		LEFT JOIN compra_items ci ON ci.compra_id = co.id
+		LEFT JOIN items i ON i.id = ci.item_id
+		WHERE co.data_compra >= ?
+		ORDER BY co.cliente_id, co.data_compra, co.id
+	`, desde).Scan(&linhas).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+
+	var compras []alerta.Compra
+	for _, l := range linhas {
+		if n := len(compras); n == 0 || compras[n-1].ID != l.CompraID {
+			compras = append(compras, alerta.Compra{ID: l.CompraID, ClienteID: l.ClienteID, Data: l.DataCompra})
+		}
+		// Sem itens, a compra vem numa única linha sem item
+		if l.NomeItem.Valid {
+			compra := &compras[len(compras)-1]
+			compra.Itens = append(compra.Itens, alerta.ItemComprado{Nome: l.NomeItem.String, Preco: l.Preco.Float64})
+		}
+	}
+	return compras, nil
+}
+
+func (r *motorAlertaGorm) ItensNaoComprados(ctx context.Context, ids []string, inicio, fim time.Time) ([]alerta.ItemCliente, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var itens []alerta.ItemCliente
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id AS cliente_id, c.nome AS nome_cliente, i.nome AS nome_item
+		FROM clientes c
+		JOIN cliente_itens cit ON cit.cliente_id = c.id
+		JOIN items i ON i.id = cit.item_id
+		WHERE c.id IN ? AND EXISTS (
+			SELECT 1
+			FROM compras co
+			WHERE co.cliente_id = c.id AND co.data_compra >= ? AND co.data_compra < ?
+		) AND NOT EXISTS (
+			SELECT 1
+			FROM compras co
+			JOIN compra_items ci ON ci.compra_id = co.id
+			WHERE co.cliente_id = c.id AND ci.item_id = cit.item_id AND
+				co.data_compra >= ? AND co.data_compra < ?
+		)
+		ORDER BY c.id, i.nome
+	`, ids, inicio, fim, inicio, fim).Scan(&itens).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return itens, nil
+}
+
+func (r *motorAlertaGorm) ItensSemCompra(ctx context.Context, antes time.Time) ([]alerta.ItemCliente, error) {
+	// Uma única consulta traz a última compra de cada item recorrente por
+	// cliente, já filtrando os comprados dentro do prazo.
+	var itens []alerta.ItemCliente
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id AS cliente_id, c.nome AS nome_cliente, i.nome AS nome_item, u.ultima_compra
+		FROM cliente_itens cit
+		JOIN clientes c ON c.id = cit.cliente_id
+		JOIN items i ON i.id = cit.item_id
+		LEFT JOIN (
+			SELECT co.cliente_id, ci.item_id, MAX(co.data_compra) AS ultima_compra
+			FROM compras co
+			JOIN compra_items ci ON ci.compra_id = co.id
+			GROUP BY co.cliente_id, ci.item_id
+		) u ON u.cliente_id = cit.cliente_id AND u.item_id = cit.item_id
+		WHERE u.ultima_compra IS NULL OR u.ultima_compra < ?
+		ORDER BY c.id, i.nome
+	`, antes).Scan(&itens).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return itens, nil
+}
+
+func (r *motorAlertaGorm) Pausados(ctx context.Context, dia time.Time) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT cliente_id
+		FROM interacoes
+		WHERE pausar_alertas_ate >= ?
+	`, dia).Scan(&ids).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return ids, nil
+}
+
+func (r *motorAlertaGorm) ValorPorCliente(ctx context.Context, desde time.Time) (map[string]float64, error) {
+	var linhas []struct {
+		ClienteID string
+		Valor     float64
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT co.cliente_id, SUM(ci.preco) AS valor
+		FROM compras co
+		JOIN compra_items ci ON ci.compra_id = co.id
+		WHERE co.data_compra >= ?
+		GROUP BY co.cliente_id
+	`, desde).Scan(&linhas).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+
+	valores := make(map[string]float64, len(linhas))
+	for _, l := range linhas {
+		valores[l.ClienteID] = l.Valor
+	}
+	return valores, nil
+}
+
+func (r *motorAlertaGorm) Representantes(ctx context.Context) (map[string]string, error) {
+	var linhas []struct {
+		ID            string
+		Representante string
+	}
+	if err := r.db.WithContext(ctx).Raw(`SELECT id, representante FROM clientes WHERE representante <> ''`).Scan(&linhas).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+
+	representantes := make(map[string]string, len(linhas))
+	for _, l := range linhas {
+		representantes[l.ID] = l.Representante
+	}
+	return representantes, nil
+}
+
+func (r *motorAlertaGorm) RegrasAtivas(ctx context.Context) ([]model.RegraAlerta, error) {
+	var regras []model.RegraAlerta
+	if err := r.db.WithContext(ctx).Where("ativa = ?", true).Order("nome").Find(&regras).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return regras, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/infra/db/dbteste"
+)
+
+// popularVolume grava 500 clientes com 5 itens recorrentes e um dia de
+// compra cada, 50 itens e 80 compras por cliente no último ano (40 mil
+// compras, com cerca de 160 mil itens).
+func popularVolume(b *testing.B, conn *gorm.DB) {
+	b.Helper()
+	comandos := []string{
+		`INSERT INTO clientes (cnpj, nome, representante)
+			SELECT 'volume-' || g, 'Cliente ' || g, 'Representante ' || g % 10
+			FROM generate_series(1, 500) g`,
+		`INSERT INTO items (nome) SELECT 'Item ' || g FROM generate_series(1, 50) g`,
+		`INSERT INTO cliente_itens (cliente_id, item_id)
+			SELECT c.id, i.id
+			FROM clientes c
+			CROSS JOIN LATERAL (SELECT id FROM items ORDER BY md5(c.id::text || items.id::text) LIMIT 5) i`,
+		`INSERT INTO dia_compra_clientes (cliente_id, dia_semana, tolerancia_dias)
+			SELECT id, abs(hashtext(id::text)) % 6 + 1, abs(hashtext(id::text)) % 3 FROM clientes`,
+		`INSERT INTO compras (cliente_id, data_compra)
+			SELECT c.id, (current_date - (random() * 365)::int)::timestamp AT TIME ZONE 'UTC'
+			FROM clientes c, generate_series(1, 80)`,
+		`INSERT INTO compra_items (compra_id, item_id, preco)
+			SELECT co.id, ci.item_id, round((random() * 100)::numeric, 2)
+			FROM compras co
+			JOIN cliente_itens ci ON ci.cliente_id = co.cliente_id
+			WHERE random() < 0.8`,
+		`ANALYZE`,
+	}
+	for _, sql := range comandos {
+		if err := conn.Exec(sql).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGerarAlertas mede a geração de cada tipo de alerta, e de todos
+// juntos, sobre o volume de popularVolume. Precisa de TEST_DATABASE_URL:
+//
+//	go test -run '^$' -bench GerarAlertas ./internal/repository
+func BenchmarkGerarAlertas(b *testing.B) {
+	conn := dbteste.Migrado(b)
+	popularVolume(b, conn)
+
+	motor := alerta.NewDefaultEngine(&motorAlertaGorm{db: conn}, time.UTC, alerta.Opcoes{
+		DiasInatividade:  30,
+		DiasItemFaltando: 30,
+	})
+	ctx := context.Background()
+
+	casos := []struct {
+		nome  string
+		tipos []string
+	}{
+		{"todos", nil},
+		{alerta.TipoDiaPrevisto, []string{alerta.TipoDiaPrevisto}},
+		{alerta.TipoCompraIncompleta, []string{alerta.TipoCompraIncompleta}},
+		{alerta.TipoInatividade, []string{alerta.TipoInatividade}},
+		{alerta.TipoItemFaltando, []string{alerta.TipoItemFaltando}},
+	}
+	for _, c := range casos {
+		b.Run(c.nome, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := motor.Gerar(ctx, c.tipos...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/infra/db/dbteste"
+	"smart-retention/internal/model"
+)
+
+const (
+	clienteAna   = "00000000-0000-0000-0000-0000000000a1"
+	clienteBruno = "00000000-0000-0000-0000-0000000000b1"
+	itemArroz    = "00000000-0000-0000-0000-00000000a770"
+	itemFeijao   = "00000000-0000-0000-0000-00000000fe10"
+)
+
+// popularMotor grava dois clientes: Ana, com os itens recorrentes arroz e
+// feijão, comprou feijão em 01/07 e arroz em 01/10; Bruno nunca comprou e
+// pausou os alertas até 25/10.
+func popularMotor(t *testing.T, conn *gorm.DB) {
+	t.Helper()
+	comandos := []string{
+		`INSERT INTO clientes (id, cnpj, nome, representante) VALUES
+			('` + clienteAna + `', '1', 'Ana', 'Carla'),
+			('` + clienteBruno + `', '2', 'Bruno', '')`,
+		`INSERT INTO items (id, nome) VALUES ('` + itemArroz + `', 'Arroz'), ('` + itemFeijao + `', 'Feijão')`,
+		`INSERT INTO cliente_itens (cliente_id, item_id) VALUES
+			('` + clienteAna + `', '` + itemArroz + `'), ('` + clienteAna + `', '` + itemFeijao + `')`,
+		`INSERT INTO compras (id, cliente_id, data_compra) VALUES
+			('00000000-0000-0000-0000-000000000701', '` + clienteAna + `', '2026-07-01T00:00:00Z'),
+			('00000000-0000-0000-0000-000000001001', '` + clienteAna + `', '2026-10-01T00:00:00Z')`,
+		`INSERT INTO compra_items (compra_id, item_id, preco) VALUES
+			('00000000-0000-0000-0000-000000000701', '` + itemFeijao + `', 10),
+			('00000000-0000-0000-0000-000000001001', '` + itemArroz + `', 30)`,
+		`INSERT INTO interacoes (cliente_id, tipo, descricao, data, pausar_alertas_ate, criada_em) VALUES
+			('` + clienteBruno + `', 'nota', 'Em reforma', '2026-10-10T00:00:00Z', '2026-10-25T00:00:00Z', now())`,
+	}
+	for _, sql := range comandos {
+		if err := conn.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func dia(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestMotorAlertaGorm(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+	r := &motorAlertaGorm{db: conn}
+	ctx := context.Background()
+
+	clientes, err := r.Clientes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clientes) != 2 || clientes[0].Nome != "Ana" || !clientes[0].UltimaCompra.Equal(dia("2026-10-01")) || clientes[1].UltimaCompra != nil {
+		t.Errorf("clientes = %+v", clientes)
+	}
+
+	ultimas, err := r.UltimasCompras(ctx, []string{clienteAna, clienteBruno}, dia("2026-09-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ultimas) != 1 || !ultimas[clienteAna].Equal(dia("2026-07-01")) {
+		t.Errorf("últimas compras antes de 01/09 = %v", ultimas)
+	}
+
+	compras, err := r.ComprasDesde(ctx, dia("2026-09-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compras) != 1 || !slices.Equal(compras[0].Itens, []alerta.ItemComprado{{Nome: "Arroz", Preco: 30}}) {
+		t.Errorf("compras desde 01/09 = %+v", compras)
+	}
+
+	naoComprados, err := r.ItensNaoComprados(ctx, []string{clienteAna, clienteBruno}, dia("2026-10-01"), dia("2026-10-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(naoComprados) != 1 || naoComprados[0].NomeItem != "Feijão" || naoComprados[0].NomeCliente != "Ana" {
+		t.Errorf("itens não comprados em 01/10 = %+v", naoComprados)
+	}
+
+	semCompra, err := r.ItensSemCompra(ctx, dia("2026-09-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(semCompra) != 1 || semCompra[0].NomeItem != "Feijão" || !semCompra[0].UltimaCompra.Equal(dia("2026-07-01")) {
+		t.Errorf("itens sem compra desde 01/09 = %+v", semCompra)
+	}
+
+	valores, err := r.ValorPorCliente(ctx, dia("2026-06-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(valores, map[string]float64{clienteAna: 40}) {
+		t.Errorf("valor por cliente = %v", valores)
+	}
+
+	representantes, err := r.Representantes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(representantes, map[string]string{clienteAna: "Carla"}) {
+		t.Errorf("representantes = %v", representantes)
+	}
+
+	for data, esperado := range map[string][]string{"2026-10-19": {clienteBruno}, "2026-10-26": nil} {
+		pausados, err := r.Pausados(ctx, dia(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(pausados, esperado) {
+			t.Errorf("pausados em %s = %v, esperado %v", data, pausados, esperado)
+		}
+	}
+}
+
+// Um alerta que some sem compra fica resolvido e não é atribuído a uma
+// compra posterior.
+func TestRegistrarHistoricoResolveAlertasQueSumiram(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+
+	inativo := alerta.Alerta{ClienteID: clienteBruno, Tipo: alerta.TipoInatividade}
+	previsto := alerta.Alerta{ClienteID: clienteAna, Tipo: alerta.TipoDiaPrevisto}
+	if err := registrarHistorico(conn, []alerta.Alerta{inativo, previsto}, dia("2026-10-19")); err != nil {
+		t.Fatal(err)
+	}
+	if err := registrarHistorico(conn, []alerta.Alerta{previsto}, dia("2026-10-20")); err != nil {
+		t.Fatal(err)
+	}
+	compra := model.Compra{ID: "00000000-0000-0000-0000-000000001021", ClienteID: clienteBruno, DataCompra: dia("2026-10-21")}
+	if err := registrarRecuperacao(conn, compra, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	var historico []model.AlertaHistorico
+	if err := conn.Order("tipo").Find(&historico).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(historico) != 2 {
+		t.Fatalf("registros = %d, esperado 2", len(historico))
+	}
+	previstoReg, inativoReg := historico[0], historico[1]
+	if previstoReg.ResolvidoEm != nil || !previstoReg.VistoEm.Equal(dia("2026-10-20")) {
+		t.Errorf("alerta ainda gerado: %+v", previstoReg)
+	}
+	if inativoReg.ResolvidoEm == nil || !inativoReg.ResolvidoEm.Equal(dia("2026-10-20")) || inativoReg.RecuperadoEm != nil {
+		t.Errorf("alerta que sumiu: %+v", inativoReg)
+	}
+}
+
+// A lista de itens de um alerta de item faltando em aberto acompanha os itens
+// gerados a cada vez.
+func TestRegistrarHistoricoAtualizaItensFaltantes(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+
+	faltando := alerta.Alerta{ClienteID: clienteAna, Tipo: alerta.TipoItemFaltando, ItensFaltantes: []string{"Feijão"}}
+	if err := registrarHistorico(conn, []alerta.Alerta{faltando}, dia("2026-10-19")); err != nil {
+		t.Fatal(err)
+	}
+	faltando.ItensFaltantes = []string{"Arroz", "Feijão"}
+	if err := registrarHistorico(conn, []alerta.Alerta{faltando}, dia("2026-10-20")); err != nil {
+		t.Fatal(err)
+	}
+
+	var historico []model.AlertaHistorico
+	if err := conn.Find(&historico).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(historico) != 1 || !slices.Equal(historico[0].ItensFaltantes, []string{"Arroz", "Feijão"}) ||
+		!historico[0].GeradoEm.Equal(dia("2026-10-19")) {
+		t.Errorf("histórico = %+v, esperado um registro com os dois itens", historico)
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"smart-retention/internal/model"
+)
+
+type pedidoRecorrenteGorm struct {
+	db *gorm.DB
+}
+
+func (r *pedidoRecorrenteGorm) BuscarPorCliente(ctx context.Context, clienteID string) (*model.PedidoRecorrente, error) {
+	var pedido model.PedidoRecorrente
+	err := r.db.WithContext(ctx).Preload("Itens.Item").First(&pedido, "cliente_id = ?", clienteID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, traduzir(err, ErrClienteNaoEncontrado)
+	}
+
+	sort.Slice(pedido.Itens, func(i, j int) bool { return pedido.Itens[i].Item.Nome < pedido.Itens[j].Item.Nome })
+	return &pedido, nil
+}
+
+func (r *pedidoRecorrenteGorm) Salvar(ctx context.Context, pedido *model.PedidoRecorrente) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		itens := pedido.Itens
+		if err := tx.Omit("Itens").Clauses(clause.OnConflict{UpdateAll: true}).Create(pedido).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cliente_id = ?", pedido.ClienteID).Delete(&model.ItemPedidoRecorrente{}).Error; err != nil {
+			return err
+		}
+		for i := range itens {
+			itens[i].ClienteID = pedido.ClienteID
+		}
+		if len(itens) == 0 {
+			return nil
+		}
+		return tx.Omit("Item").Create(&itens).Error
+	})
+	return traduzir(err, nil)
+}
+
+func (r *pedidoRecorrenteGorm) Deletar(ctx context.Context, clienteID string) error {
+	res := r.db.WithContext(ctx).Delete(&model.PedidoRecorrente{}, "cliente_id = ?", clienteID)
+	if res.Error != nil {
+		return traduzirExclusao(res.Error, ErrPedidoRecorrenteNaoEncontrado)
+	}
+	if res.RowsAffected == 0 {
+		return ErrPedidoRecorrenteNaoEncontrado
+	}
+	return nil
+}
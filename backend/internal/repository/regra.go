@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/model"
+)
+
+type regraGorm struct {
+	db *gorm.DB
+}
+
+func (r *regraGorm) Listar(ctx context.Context) ([]model.RegraAlerta, error) {
+	var regras []model.RegraAlerta
+	if err := r.db.WithContext(ctx).Order("nome").Find(&regras).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return regras, nil
+}
+
+func (r *regraGorm) BuscarPorID(ctx context.Context, id string) (*model.RegraAlerta, error) {
+	var regra model.RegraAlerta
+	if err := r.db.WithContext(ctx).First(&regra, "id = ?", id).Error; err != nil {
+		return nil, traduzir(err, ErrRegraNaoEncontrada)
+	}
+	return &regra, nil
+}
+
+func (r *regraGorm) Criar(ctx context.Context, regra *model.RegraAlerta) error {
+	return traduzir(r.db.WithContext(ctx).Create(regra).Error, nil)
+}
+
+func (r *regraGorm) Atualizar(ctx context.Context, regra *model.RegraAlerta) error {
+	res := r.db.WithContext(ctx).Model(regra).Select("Nome", "Expressao", "Motivo", "Ativa").Updates(regra)
+	if res.Error != nil {
+		return traduzir(res.Error, ErrRegraNaoEncontrada)
+	}
+	if res.RowsAffected == 0 {
+		return ErrRegraNaoEncontrada
+	}
+	return nil
+}
+
+func (r *regraGorm) Deletar(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Delete(&model.RegraAlerta{}, "id = ?", id)
+	if res.Error != nil {
+		return traduzirExclusao(res.Error, ErrRegraNaoEncontrada)
+	}
+	if res.RowsAffected == 0 {
+		return ErrRegraNaoEncontrada
+	}
+	return nil
+}
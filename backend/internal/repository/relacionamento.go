@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/model"
+)
+
+type (
+	contatoGorm struct {
+		db *gorm.DB
+	}
+
+	interacaoGorm struct {
+		db *gorm.DB
+	}
+)
+
+func (r *contatoGorm) ListarPorCliente(ctx context.Context, clienteID string) ([]model.Contato, error) {
+	var contatos []model.Contato
+	if err := r.db.WithContext(ctx).
+		Where("cliente_id = ?", clienteID).
+		Order("principal DESC, nome").
+		Find(&contatos).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return contatos, nil
+}
+
+func (r *contatoGorm) BuscarPorID(ctx context.Context, clienteID, id string) (*model.Contato, error) {
+	var contato model.Contato
+	if err := r.db.WithContext(ctx).First(&contato, "id = ? AND cliente_id = ?", id, clienteID).Error; err != nil {
+		return nil, traduzir(err, ErrContatoNaoEncontrado)
+	}
+	return &contato, nil
+}
+
+func (r *contatoGorm) Criar(ctx context.Context, contato *model.Contato) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(contato).Error; err != nil {
+			return err
+		}
+		return manterPrincipal(tx, contato)
+	})
+	return traduzir(err, nil)
+}
+
+func (r *contatoGorm) Atualizar(ctx context.Context, contato *model.Contato) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(contato).
+			Where("cliente_id = ?", contato.ClienteID).
+			Select("Nome", "Cargo", "Telefone", "Email", "Principal").
+			Updates(contato)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrContatoNaoEncontrado
+		}
+		return manterPrincipal(tx, contato)
+	})
+	return traduzir(err, ErrContatoNaoEncontrado)
+}
+
+func (r *contatoGorm) Deletar(ctx context.Context, clienteID, id string) error {
+	// As interações do contato ficam, sem o contato (ON DELETE SET NULL)
+	res := r.db.WithContext(ctx).Delete(&model.Contato{}, "id = ? AND cliente_id = ?", id, clienteID)
+	if res.Error != nil {
+		return traduzirExclusao(res.Error, ErrContatoNaoEncontrado)
+	}
+	if res.RowsAffected == 0 {
+		return ErrContatoNaoEncontrado
+	}
+	return nil
+}
+
+// manterPrincipal desmarca os outros contatos do cliente quando este passa
+// a ser o principal.
+func manterPrincipal(tx *gorm.DB, contato *model.Contato) error {
+	if !contato.Principal {
+		return nil
+	}
+	return tx.Model(&model.Contato{}).
+		Where("cliente_id = ? AND id <> ?", contato.ClienteID, contato.ID).
+		Update("principal", false).Error
+}
+
+func (r *interacaoGorm) ListarPorCliente(ctx context.Context, clienteID string) ([]model.Interacao, error) {
+	var interacoes []model.Interacao
+	if err := r.db.WithContext(ctx).
+		Preload("Contato").
+		Where("cliente_id = ?", clienteID).
+		Order("data DESC").
+		Find(&interacoes).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return interacoes, nil
+}
+
+func (r *interacaoGorm) BuscarPorID(ctx context.Context, clienteID, id string) (*model.Interacao, error) {
+	var interacao model.Interacao
+	if err := r.db.WithContext(ctx).Preload("Contato").First(&interacao, "id = ? AND cliente_id = ?", id, clienteID).Error; err != nil {
+		return nil, traduzir(err, ErrInteracaoNaoEncontrada)
+	}
+	return &interacao, nil
+}
+
+func (r *interacaoGorm) Criar(ctx context.Context, interacao *model.Interacao) error {
+	return traduzir(r.db.WithContext(ctx).Omit("Contato").Create(interacao).Error, nil)
+}
+
+func (r *interacaoGorm) Atualizar(ctx context.Context, interacao *model.Interacao) error {
+	res := r.db.WithContext(ctx).Model(interacao).
+		Where("cliente_id = ?", interacao.ClienteID).
+		Select("ContatoID", "Tipo", "Descricao", "Resultado", "Autor", "Data", "RetornoEm", "PausarAlertasAte").
+		Updates(interacao)
+	if res.Error != nil {
+		return traduzir(res.Error, ErrInteracaoNaoEncontrada)
+	}
+	if res.RowsAffected == 0 {
+		return ErrInteracaoNaoEncontrada
+	}
+	return nil
+}
+
+func (r *interacaoGorm) Deletar(ctx context.Context, clienteID, id string) error {
+	res := r.db.WithContext(ctx).Delete(&model.Interacao{}, "id = ? AND cliente_id = ?", id, clienteID)
+	if res.Error != nil {
+		return traduzirExclusao(res.Error, ErrInteracaoNaoEncontrada)
+	}
+	if res.RowsAffected == 0 {
+		return ErrInteracaoNaoEncontrada
+	}
+	return nil
+}
+
+func (r *interacaoGorm) AlertasPausadosAte(ctx context.Context, clienteID string, hoje time.Time) (*time.Time, error) {
+	var ate sql.NullTime
+	if err := r.db.WithContext(ctx).Model(&model.Interacao{}).
+		Select("MAX(pausar_alertas_ate)").
+		Where("cliente_id = ? AND pausar_alertas_ate >= ?", clienteID, hoje).
+		Row().Scan(&ate); err != nil {
+		return nil, traduzir(err, nil)
+	}
+	if !ate.Valid {
+		return nil, nil
+	}
+	return &ate.Time, nil
+}
@@ -0,0 +1,292 @@
+// Package repository isola o acesso ao banco. Cada repositório é uma
+// interface, implementada aqui sobre o GORM, para que os serviços possam ser
+// exercitados com implementações em memória.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/sugestao"
+)
+
+// Erros devolvidos quando o registro pedido não existe.
+var (
+	ErrClienteNaoEncontrado    = falha.NaoEncontrado("Cliente não encontrado")
+	ErrRegraNaoEncontrada      = falha.NaoEncontrado("Regra não encontrada")
+	ErrCompraNaoEncontrada     = falha.NaoEncontrado("Compra não encontrada")
+	ErrContatoNaoEncontrado    = falha.NaoEncontrado("Contato não encontrado")
+	ErrInteracaoNaoEncontrada  = falha.NaoEncontrado("Interação não encontrada")
+	ErrTarefaNaoEncontrada     = falha.NaoEncontrado("Tarefa não encontrada")
+	ErrFeriadoNaoEncontrado    = falha.NaoEncontrado("Feriado não encontrado")
+	ErrFechamentoNaoEncontrado = falha.NaoEncontrado("Fechamento não encontrado")
+
+	ErrRegraTarefaNaoEncontrada      = falha.NaoEncontrado("Não há regra de tarefa para este tipo de alerta")
+	ErrPedidoRecorrenteNaoEncontrado = falha.NaoEncontrado("O cliente não tem pedido recorrente salvo")
+)
+
+// restricoes descreve para o usuário as restrições do esquema que os dados
+// recebidos pela API podem violar.
+var restricoes = map[string]falha.Campo{
+	"uni_clientes_cnpj":               {Nome: "cnpj", Mensagem: "Já existe um cliente com este CNPJ"},
+	"fk_compras_cliente":              {Nome: "cliente_id", Mensagem: "Cliente não encontrado"},
+	"fk_compra_items_item":            {Nome: "itens.item_id", Mensagem: "Item não encontrado"},
+	"fk_cliente_itens_item":           {Nome: "itens.id", Mensagem: "Item não encontrado"},
+	"fk_interacoes_contato":           {Nome: "contato_id", Mensagem: "Contato não encontrado"},
+	"fk_tarefas_cliente":              {Nome: "cliente_id", Mensagem: "Cliente não encontrado"},
+	"fk_pedido_recorrente_itens_item": {Nome: "itens.item_id", Mensagem: "Item não encontrado"},
+	"uni_feriados_data":               {Nome: "data", Mensagem: "Já existe um feriado cadastrado nesta data"},
+}
+
+type (
+	ClienteRepository interface {
+		// Listar e BuscarPorID trazem os itens e os dias de compra.
+		Listar(ctx context.Context) ([]model.Cliente, error)
+		BuscarPorID(ctx context.Context, id string) (*model.Cliente, error)
+		// Criar e Atualizar gravam também os itens e os dias de compra.
+		Criar(ctx context.Context, cliente *model.Cliente) error
+		Atualizar(ctx context.Context, cliente *model.Cliente) error
+		Deletar(ctx context.Context, id string) error
+	}
+
+	CompraRepository interface {
+		// Criar grava a compra com seus itens e, na mesma transação, fecha os
+		// alertas em aberto do cliente como recuperados por ela; os de item
+		// faltando, só se ela traz um dos itens que faltavam.
+		Criar(ctx context.Context, compra *model.Compra) error
+		// BuscarPorID, Listar e ListarPorCliente trazem os itens com o item
+		// carregado; BuscarPorID e Listar trazem também o cliente.
+		BuscarPorID(ctx context.Context, id string) (*model.Compra, error)
+		Listar(ctx context.Context) ([]model.Compra, error)
+		ListarPorCliente(ctx context.Context, clienteID string) ([]model.Compra, error)
+	}
+
+	// ContatoRepository e InteracaoRepository só enxergam os registros do
+	// cliente informado; os de outro cliente são tratados como inexistentes.
+	ContatoRepository interface {
+		// ListarPorCliente traz o contato principal primeiro.
+		ListarPorCliente(ctx context.Context, clienteID string) ([]model.Contato, error)
+		BuscarPorID(ctx context.Context, clienteID, id string) (*model.Contato, error)
+		// Criar e Atualizar desmarcam os demais contatos do cliente quando o
+		// contato gravado é o principal.
+		Criar(ctx context.Context, contato *model.Contato) error
+		Atualizar(ctx context.Context, contato *model.Contato) error
+		Deletar(ctx context.Context, clienteID, id string) error
+	}
+
+	InteracaoRepository interface {
+		// ListarPorCliente e BuscarPorID trazem o contato; a lista vem da
+		// interação mais recente para a mais antiga.
+		ListarPorCliente(ctx context.Context, clienteID string) ([]model.Interacao, error)
+		BuscarPorID(ctx context.Context, clienteID, id string) (*model.Interacao, error)
+		Criar(ctx context.Context, interacao *model.Interacao) error
+		Atualizar(ctx context.Context, interacao *model.Interacao) error
+		Deletar(ctx context.Context, clienteID, id string) error
+		// AlertasPausadosAte devolve o fim da pausa de alertas mais longa do
+		// cliente que ainda vale em hoje, ou nil se não há pausa.
+		AlertasPausadosAte(ctx context.Context, clienteID string, hoje time.Time) (*time.Time, error)
+	}
+
+	TarefaRepository interface {
+		// Listar e BuscarPorID trazem o cliente; a lista vem pelo vencimento.
+		Listar(ctx context.Context, filtro FiltroTarefas) ([]model.Tarefa, error)
+		BuscarPorID(ctx context.Context, id string) (*model.Tarefa, error)
+		Criar(ctx context.Context, tarefa *model.Tarefa) error
+		Atualizar(ctx context.Context, tarefa *model.Tarefa) error
+		// AlertaEmAberto devolve o registro em aberto do alerta do cliente
+		// (tipo e regra), ou nil se não houver.
+		AlertaEmAberto(ctx context.Context, clienteID, tipo, regraID string) (*model.AlertaHistorico, error)
+		// AlertasSemTarefa devolve os alertas em aberto dos tipos que ainda
+		// não têm nenhuma tarefa ligada.
+		AlertasSemTarefa(ctx context.Context, tipos []string) ([]model.AlertaHistorico, error)
+		// Vencidas devolve as tarefas pendentes vencidas antes de hoje que
+		// ainda não foram lembradas hoje.
+		Vencidas(ctx context.Context, hoje time.Time) ([]model.Tarefa, error)
+		// MarcarLembradas registra o dia do lembrete, à meia-noite UTC como
+		// o hoje de Vencidas, e não o instante, que à noite já cai no dia
+		// seguinte em UTC.
+		MarcarLembradas(ctx context.Context, ids []string, dia time.Time) error
+	}
+
+	// FiltroTarefas restringe a listagem; campos vazios não filtram.
+	FiltroTarefas struct {
+		Responsavel string
+		Status      []string
+		ClienteID   string
+	}
+
+	RegraTarefaRepository interface {
+		Listar(ctx context.Context) ([]model.RegraTarefa, error)
+		// Salvar cria ou substitui a regra do tipo de alerta.
+		Salvar(ctx context.Context, regra *model.RegraTarefa) error
+		Deletar(ctx context.Context, tipoAlerta string) error
+	}
+
+	PedidoRecorrenteRepository interface {
+		// BuscarPorCliente traz os itens, pelo nome, ou nil se o cliente
+		// não tem pedido recorrente salvo.
+		BuscarPorCliente(ctx context.Context, clienteID string) (*model.PedidoRecorrente, error)
+		// Salvar cria ou substitui o pedido do cliente, com todos os itens.
+		Salvar(ctx context.Context, pedido *model.PedidoRecorrente) error
+		Deletar(ctx context.Context, clienteID string) error
+	}
+
+	// FeriadoRepository guarda só os feriados cadastrados; os nacionais são
+	// calculados pelo pacote calendario.
+	FeriadoRepository interface {
+		// Listar traz os feriados do período [de, ate), pela data.
+		Listar(ctx context.Context, de, ate time.Time) ([]model.Feriado, error)
+		Criar(ctx context.Context, feriado *model.Feriado) error
+		Deletar(ctx context.Context, id string) error
+	}
+
+	// FechamentoRepository só enxerga os fechamentos do cliente informado.
+	FechamentoRepository interface {
+		// ListarPorCliente traz os fechamentos do mais recente ao mais antigo.
+		ListarPorCliente(ctx context.Context, clienteID string) ([]model.Fechamento, error)
+		Criar(ctx context.Context, fechamento *model.Fechamento) error
+		Deletar(ctx context.Context, clienteID, id string) error
+	}
+
+	RegraRepository interface {
+		Listar(ctx context.Context) ([]model.RegraAlerta, error)
+		BuscarPorID(ctx context.Context, id string) (*model.RegraAlerta, error)
+		Criar(ctx context.Context, regra *model.RegraAlerta) error
+		Atualizar(ctx context.Context, regra *model.RegraAlerta) error
+		Deletar(ctx context.Context, id string) error
+	}
+
+	AlertaRepository interface {
+		// RegistrarHistorico recebe todos os alertas atuais: abre ou
+		// atualiza os seus registros e fecha como resolvidos os que sumiram.
+		RegistrarHistorico(ctx context.Context, alertas []alerta.Alerta, agora time.Time) error
+		// Efetividade agrega o histórico do período [desde, ate) pelo tipo
+		// do alerta ou pelo representante.
+		Efetividade(ctx context.Context, grupo GrupoEfetividade, desde, ate time.Time) ([]EfetividadeAlertas, error)
+	}
+
+	DashboardRepository interface {
+		Resumo(ctx context.Context) (*Dashboard, error)
+	}
+
+	// Repositorios reúne as implementações usadas pela aplicação.
+	Repositorios struct {
+		Clientes     ClienteRepository
+		Compras      CompraRepository
+		Contatos     ContatoRepository
+		Interacoes   InteracaoRepository
+		Tarefas      TarefaRepository
+		RegrasTarefa RegraTarefaRepository
+		Pedidos      PedidoRecorrenteRepository
+		Feriados     FeriadoRepository
+		Fechamentos  FechamentoRepository
+		Regras       RegraRepository
+		Alertas      AlertaRepository
+		Dashboard    DashboardRepository
+		// MotorAlertas é o acesso aos dados do motor de alertas.
+		MotorAlertas alerta.Repositorio
+		// Sugestoes é o acesso aos dados do modelo de sugestões.
+		Sugestoes sugestao.Repositorio
+	}
+)
+
+func NewGorm(db *gorm.DB) Repositorios {
+	return Repositorios{
+		Clientes:     &clienteGorm{db: db},
+		Compras:      &compraGorm{db: db},
+		Contatos:     &contatoGorm{db: db},
+		Interacoes:   &interacaoGorm{db: db},
+		Tarefas:      &tarefaGorm{db: db},
+		RegrasTarefa: &regraTarefaGorm{db: db},
+		Pedidos:      &pedidoRecorrenteGorm{db: db},
+		Feriados:     &feriadoGorm{db: db},
+		Fechamentos:  &fechamentoGorm{db: db},
+		Regras:       &regraGorm{db: db},
+		Alertas:      &alertaGorm{db: db},
+		Dashboard:    &dashboardGorm{db: db},
+		MotorAlertas: &motorAlertaGorm{db: db},
+		Sugestoes:    &sugestaoGorm{db: db},
+	}
+}
+
+// traduzir converte os erros do GORM e do Postgres em *falha.Erro, com a
+// causa preservada para os logs. naoEncontrado, se informado, é devolvido
+// quando o registro não existe ou o ID nem é um UUID válido. Os demais erros
+// seguem como estão e viram erro interno. Uma chave estrangeira violada é
+// uma referência a um registro inexistente; nas exclusões, use
+// traduzirExclusao.
+func traduzir(err error, naoEncontrado *falha.Erro) error {
+	return converterErro(err, naoEncontrado, false)
+}
+
+// traduzirExclusao é o traduzir das exclusões, em que uma chave estrangeira
+// violada só pode ser outro registro que ainda aponta para o excluído. Quem
+// decide é a operação, e não o detalhe do erro do Postgres, que vem no idioma
+// do servidor.
+func traduzirExclusao(err error, naoEncontrado *falha.Erro) error {
+	return converterErro(err, naoEncontrado, true)
+}
+
+func converterErro(err error, naoEncontrado *falha.Erro, exclusao bool) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if naoEncontrado != nil {
+			return naoEncontrado
+		}
+		return &falha.Erro{Codigo: falha.CodigoNaoEncontrado, Mensagem: "Registro não encontrado", Causa: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	campo, conhecida := restricoes[pgErr.ConstraintName]
+	erro := &falha.Erro{Causa: err}
+	if conhecida {
+		erro.Mensagem = campo.Mensagem
+		erro.Campos = []falha.Campo{campo}
+	}
+
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		erro.Codigo = falha.CodigoConflito
+		if !conhecida {
+			erro.Mensagem = "Registro duplicado"
+		}
+	case "23503": // foreign_key_violation
+		if exclusao {
+			erro.Codigo = falha.CodigoEmUso
+			erro.Mensagem = "Registro em uso por outros dados"
+			erro.Campos = nil
+		} else {
+			erro.Codigo = falha.CodigoReferenciaInvalida
+			if !conhecida {
+				erro.Mensagem = "Referência a um registro inexistente"
+			}
+		}
+	case "23502": // not_null_violation
+		erro.Codigo = falha.CodigoValidacao
+		erro.Mensagem = "Campo obrigatório não informado"
+		erro.Campos = []falha.Campo{{Nome: pgErr.ColumnName, Mensagem: "obrigatório"}}
+	case "22P02": // invalid_text_representation, como um UUID malformado
+		if naoEncontrado != nil {
+			return naoEncontrado
+		}
+		erro.Codigo = falha.CodigoRequisicaoInvalida
+		erro.Mensagem = "Valor em formato inválido"
+		erro.Campos = nil
+	default:
+		return err
+	}
+	return erro
+}
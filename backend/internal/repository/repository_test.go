@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"smart-retention/internal/falha"
+	"smart-retention/internal/infra/db/dbteste"
+	"smart-retention/internal/model"
+)
+
+func TestMain(m *testing.M) { dbteste.Principal(m) }
+
+// traduzir e traduzirExclusao recebem os erros reais do Postgres para as
+// restrições do esquema.
+func TestTraduzirErrosDoPostgres(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+
+	casos := []struct {
+		nome     string
+		sql      string
+		exclusao bool
+		codigo   falha.Codigo
+		campo    string
+	}{
+		{
+			nome:   "CNPJ repetido",
+			sql:    `INSERT INTO clientes (cnpj, nome) VALUES ('1', 'Outra Ana')`,
+			codigo: falha.CodigoConflito,
+			campo:  "cnpj",
+		},
+		{
+			nome:   "compra de cliente inexistente",
+			sql:    `INSERT INTO compras (cliente_id, data_compra) VALUES ('00000000-0000-0000-0000-0000000000ff', now())`,
+			codigo: falha.CodigoReferenciaInvalida,
+			campo:  "cliente_id",
+		},
+		{
+			nome:     "item ainda ligado a um cliente",
+			sql:      `DELETE FROM items WHERE id = '` + itemArroz + `'`,
+			exclusao: true,
+			codigo:   falha.CodigoEmUso,
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			err := conn.Exec(c.sql).Error
+			if err == nil {
+				t.Fatal("o Postgres aceitou o comando")
+			}
+			traduzirComo := traduzir
+			if c.exclusao {
+				traduzirComo = traduzirExclusao
+			}
+			e := falha.De(traduzirComo(err, nil))
+			if e.Codigo != c.codigo {
+				t.Fatalf("código = %s, esperado %s (%v)", e.Codigo, c.codigo, err)
+			}
+			if c.campo != "" && (len(e.Campos) != 1 || e.Campos[0].Nome != c.campo) {
+				t.Errorf("campos = %+v, esperado %s", e.Campos, c.campo)
+			}
+			if c.campo == "" && len(e.Campos) != 0 {
+				t.Errorf("campos = %+v, esperado nenhum", e.Campos)
+			}
+			if e.Causa == nil {
+				t.Errorf("erro sem a causa: %+v", e)
+			}
+		})
+	}
+
+	// Um ID que nem é UUID conta como registro inexistente
+	var cliente model.Cliente
+	err := conn.First(&cliente, "id = ?", "nao-e-uuid").Error
+	if got := traduzir(err, ErrClienteNaoEncontrado); got != ErrClienteNaoEncontrado {
+		t.Errorf("ID malformado = %v, esperado cliente não encontrado", got)
+	}
+}
+
+// A violação de chave estrangeira é decidida pela operação, com o detalhe do
+// erro em qualquer idioma.
+func TestTraduzirChaveEstrangeiraPelaOperacao(t *testing.T) {
+	err := &pgconn.PgError{
+		Code:           "23503",
+		ConstraintName: "fk_cliente_itens_item",
+		TableName:      "cliente_itens",
+		Detail:         `Chave (id)=(` + itemArroz + `) ainda é referenciada pela tabela "cliente_itens".`,
+	}
+
+	if e := falha.De(traduzirExclusao(err, nil)); e.Codigo != falha.CodigoEmUso || len(e.Campos) != 0 {
+		t.Errorf("na exclusão = %+v, esperado em uso, sem campos", e)
+	}
+	if e := falha.De(traduzir(err, nil)); e.Codigo != falha.CodigoReferenciaInvalida || len(e.Campos) != 1 || e.Campos[0].Nome != "itens.id" {
+		t.Errorf("na gravação = %+v, esperado referência inválida em itens.id", e)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-retention/internal/sugestao"
+)
+
+// sugestaoGorm implementa sugestao.Repositorio, as consultas do modelo de
+// sugestões.
+type sugestaoGorm struct {
+	db *gorm.DB
+}
+
+func (r *sugestaoGorm) ItensComprados(ctx context.Context, desde time.Time) ([]sugestao.ItemCliente, error) {
+	var itens []sugestao.ItemCliente
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT co.cliente_id, ci.item_id, i.nome
+		FROM compras co
+		JOIN compra_items ci ON ci.compra_id = co.id
+		JOIN items i ON i.id = ci.item_id
+		WHERE co.data_compra >= ?
+	`, desde).Scan(&itens).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return itens, nil
+}
+
+func (r *sugestaoGorm) ItensCadastrados(ctx context.Context) ([]sugestao.ItemCliente, error) {
+	var itens []sugestao.ItemCliente
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT cit.cliente_id, cit.item_id, i.nome
+		FROM cliente_itens cit
+		JOIN items i ON i.id = cit.item_id
+	`).Scan(&itens).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return itens, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"smart-retention/internal/model"
+)
+
+type (
+	tarefaGorm struct {
+		db *gorm.DB
+	}
+
+	regraTarefaGorm struct {
+		db *gorm.DB
+	}
+)
+
+// pendentes são as situações das tarefas que ainda precisam ser feitas.
+var pendentes = []string{model.TarefaAberta, model.TarefaEmAndamento}
+
+func (r *tarefaGorm) Listar(ctx context.Context, filtro FiltroTarefas) ([]model.Tarefa, error) {
+	q := r.db.WithContext(ctx).Preload("Cliente")
+	if filtro.Responsavel != "" {
+		q = q.Where("responsavel = ?", filtro.Responsavel)
+	}
+	if len(filtro.Status) > 0 {
+		q = q.Where("status IN ?", filtro.Status)
+	}
+	if filtro.ClienteID != "" {
+		q = q.Where("cliente_id = ?", filtro.ClienteID)
+	}
+
+	var tarefas []model.Tarefa
+	if err := q.Order("vencimento, criada_em").Find(&tarefas).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return tarefas, nil
+}
+
+func (r *tarefaGorm) BuscarPorID(ctx context.Context, id string) (*model.Tarefa, error) {
+	var tarefa model.Tarefa
+	if err := r.db.WithContext(ctx).Preload("Cliente").First(&tarefa, "id = ?", id).Error; err != nil {
+		return nil, traduzir(err, ErrTarefaNaoEncontrada)
+	}
+	return &tarefa, nil
+}
+
+func (r *tarefaGorm) Criar(ctx context.Context, tarefa *model.Tarefa) error {
+	return traduzir(r.db.WithContext(ctx).Omit("Cliente").Create(tarefa).Error, nil)
+}
+
+func (r *tarefaGorm) Atualizar(ctx context.Context, tarefa *model.Tarefa) error {
+	res := r.db.WithContext(ctx).Model(tarefa).
+		Select("Titulo", "Descricao", "Responsavel", "Status", "Vencimento", "ConcluidaEm").
+		Updates(tarefa)
+	if res.Error != nil {
+		return traduzir(res.Error, ErrTarefaNaoEncontrada)
+	}
+	if res.RowsAffected == 0 {
+		return ErrTarefaNaoEncontrada
+	}
+	return nil
+}
+
+func (r *tarefaGorm) AlertaEmAberto(ctx context.Context, clienteID, tipo, regraID string) (*model.AlertaHistorico, error) {
+	var historico []model.AlertaHistorico
+	if err := r.db.WithContext(ctx).
+		Where("cliente_id = ? AND tipo = ? AND COALESCE(regra_id, '') = ? AND recuperado_em IS NULL AND resolvido_em IS NULL", clienteID, tipo, regraID).
+		Order("gerado_em DESC").
+		Limit(1).
+		Find(&historico).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	if len(historico) == 0 {
+		return nil, nil
+	}
+	return &historico[0], nil
+}
+
+func (r *tarefaGorm) AlertasSemTarefa(ctx context.Context, tipos []string) ([]model.AlertaHistorico, error) {
+	var historico []model.AlertaHistorico
+	if err := r.db.WithContext(ctx).
+		Where("recuperado_em IS NULL AND resolvido_em IS NULL AND tipo IN ?", tipos).
+		Where("NOT EXISTS (SELECT 1 FROM tarefas t WHERE t.alerta_historico_id = alerta_historicos.id)").
+		Find(&historico).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return historico, nil
+}
+
+func (r *tarefaGorm) Vencidas(ctx context.Context, hoje time.Time) ([]model.Tarefa, error) {
+	var tarefas []model.Tarefa
+	if err := r.db.WithContext(ctx).
+		Preload("Cliente").
+		Where("status IN ? AND vencimento < ?", pendentes, hoje).
+		Where("lembrada_em IS NULL OR lembrada_em < ?", hoje).
+		Order("vencimento").
+		Find(&tarefas).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return tarefas, nil
+}
+
+func (r *tarefaGorm) MarcarLembradas(ctx context.Context, ids []string, dia time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return traduzir(r.db.WithContext(ctx).Model(&model.Tarefa{}).
+		Where("id IN ?", ids).
+		Update("lembrada_em", dia).Error, nil)
+}
+
+func (r *regraTarefaGorm) Listar(ctx context.Context) ([]model.RegraTarefa, error) {
+	var regras []model.RegraTarefa
+	if err := r.db.WithContext(ctx).Order("tipo_alerta").Find(&regras).Error; err != nil {
+		return nil, traduzir(err, nil)
+	}
+	return regras, nil
+}
+
+func (r *regraTarefaGorm) Salvar(ctx context.Context, regra *model.RegraTarefa) error {
+	return traduzir(r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(regra).Error, nil)
+}
+
+func (r *regraTarefaGorm) Deletar(ctx context.Context, tipoAlerta string) error {
+	res := r.db.WithContext(ctx).Delete(&model.RegraTarefa{}, "tipo_alerta = ?", tipoAlerta)
+	if res.Error != nil {
+		return traduzirExclusao(res.Error, nil)
+	}
+	if res.RowsAffected == 0 {
+		return ErrRegraTarefaNaoEncontrada
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"smart-retention/internal/infra/db/dbteste"
+	"smart-retention/internal/model"
+)
+
+func TestTarefaGormVencidasUmaVezPorDia(t *testing.T) {
+	conn := dbteste.Migrado(t)
+	popularMotor(t, conn)
+	hoje, ontem := dia("2026-10-19"), dia("2026-10-18")
+	tarefas := []model.Tarefa{
+		{Titulo: "vencida", Status: model.TarefaAberta, Vencimento: dia("2026-10-17")},
+		{Titulo: "lembrada ontem", Status: model.TarefaEmAndamento, Vencimento: dia("2026-10-10"), LembradaEm: &ontem},
+		{Titulo: "lembrada hoje", Status: model.TarefaAberta, Vencimento: dia("2026-10-10"), LembradaEm: &hoje},
+		{Titulo: "vence hoje", Status: model.TarefaAberta, Vencimento: hoje},
+		{Titulo: "concluída", Status: model.TarefaConcluida, Vencimento: dia("2026-10-17")},
+	}
+	for i := range tarefas {
+		tarefas[i].ClienteID = clienteAna
+		tarefas[i].CriadaEm = dia("2026-10-01")
+	}
+	if err := conn.Create(&tarefas).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := &tarefaGorm{db: conn}
+	ctx := context.Background()
+	titulos := func(hoje time.Time) []string {
+		t.Helper()
+		vencidas, err := r.Vencidas(ctx, hoje)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titulos []string
+		for _, v := range vencidas {
+			if v.Cliente.Nome != "Ana" {
+				t.Errorf("tarefa %q sem o cliente: %+v", v.Titulo, v.Cliente)
+			}
+			titulos = append(titulos, v.Titulo)
+		}
+		return titulos
+	}
+
+	// Em ordem de vencimento
+	if obtido := titulos(hoje); !slices.Equal(obtido, []string{"lembrada ontem", "vencida"}) {
+		t.Fatalf("vencidas = %v", obtido)
+	}
+
+	if err := r.MarcarLembradas(ctx, []string{tarefas[0].ID, tarefas[1].ID}, hoje); err != nil {
+		t.Fatal(err)
+	}
+	if obtido := titulos(hoje); len(obtido) != 0 {
+		t.Errorf("vencidas depois do lembrete = %v, esperado nenhuma no mesmo dia", obtido)
+	}
+	if obtido := titulos(dia("2026-10-20")); !slices.Equal(obtido, []string{"lembrada ontem", "lembrada hoje", "vencida"}) {
+		t.Errorf("vencidas no dia seguinte = %v", obtido)
+	}
+}
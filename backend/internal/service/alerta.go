@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+// alertasDoDia são os tipos de alerta que dizem respeito ao dia corrente.
+var alertasDoDia = []string{alerta.TipoDiaPrevisto, alerta.TipoCompraIncompleta}
+
+type (
+	AlertaService struct {
+		gerador   GeradorAlertas
+		historico repository.AlertaRepository
+		tarefas   *TarefaService
+		hub       ws.Publicador
+		mu        sync.Mutex // serializa a publicação dos alertas
+	}
+
+	// Efetividade mostra, por tipo de alerta e por representante, quantos
+	// clientes voltaram a comprar depois de alertados.
+	Efetividade struct {
+		PorTipo          []repository.EfetividadeAlertas
+		PorRepresentante []repository.EfetividadeAlertas
+	}
+)
+
+func NewAlertaService(gerador GeradorAlertas, historico repository.AlertaRepository, tarefas *TarefaService, hub ws.Publicador) *AlertaService {
+	return &AlertaService{gerador: gerador, historico: historico, tarefas: tarefas, hub: hub}
+}
+
+// DoDia gera só os alertas que dizem respeito a hoje.
+func (s *AlertaService) DoDia(ctx context.Context) ([]alerta.Alerta, error) {
+	return s.gerador.Gerar(ctx, alertasDoDia...)
+}
+
+func (s *AlertaService) Todos(ctx context.Context) ([]alerta.Alerta, error) {
+	return s.gerador.Gerar(ctx)
+}
+
+// Incidentes agrupa os alertas por cliente, do incidente mais prioritário
+// para o menos.
+func (s *AlertaService) Incidentes(ctx context.Context) ([]alerta.Incidente, error) {
+	alertas, err := s.Todos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return alerta.Agrupar(alertas), nil
+}
+
+// DispararDiario registra no log os alertas do dia. Usado pelo cron.
+func (s *AlertaService) DispararDiario(ctx context.Context) {
+	alertas, err := s.DoDia(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao gerar alertas do dia", "erro", err)
+		return
+	}
+
+	for _, a := range alertas {
+		slog.InfoContext(ctx, "alerta do dia",
+			"cliente_id", a.ClienteID,
+			"cliente", a.NomeCliente,
+			"tipo", a.Tipo,
+			"motivo", a.Motivo,
+			"itens_faltantes", a.ItensFaltantes,
+		)
+	}
+}
+
+// Publicar gera os alertas, registra-os no histórico (fechando como
+// resolvidos os que deixaram de ser gerados), cria as tarefas automáticas
+// dos alertas novos e publica no hub o que mudou. Usado pelo
+// loop periódico do main, apenas na instância líder.
+func (s *AlertaService) Publicar(ctx context.Context) error {
+	alertas, err := s.Todos(ctx)
+	if err != nil {
+		return err
+	}
+
+	// As tarefas se ligam ao registro do alerta no histórico
+	if err := s.historico.RegistrarHistorico(ctx, alertas, time.Now()); err != nil {
+		slog.ErrorContext(ctx, "erro ao registrar histórico de alertas", "erro", err)
+	} else if err := s.tarefas.CriarDeAlertas(ctx, alertas); err != nil {
+		slog.ErrorContext(ctx, "erro ao criar tarefas dos alertas", "erro", err)
+	}
+
+	s.publicar(alertas)
+	return nil
+}
+
+// publicar compara os alertas com o estado retido no hub e publica apenas
+// as diferenças: alerta.criado para os novos, alerta.alterado para os que
+// mudaram e alerta.resolvido para os que sumiram. Comparar com o estado do
+// hub, e não com a geração anterior desta instância, mantém o resultado
+// correto quando a liderança muda de instância.
+func (s *AlertaService) publicar(alertas []alerta.Alerta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	retidos := s.hub.Retidos()
+
+	atuais := make(map[string]bool, len(alertas))
+	for _, a := range alertas {
+		chave := a.Chave()
+		atuais[chave] = true
+
+		anterior, ok := retidos[chave]
+		switch {
+		case !ok:
+			s.hub.Reter(chave, eventoAlerta(ws.EventoAlertaCriado, a))
+		case !mesmoConteudo(anterior.Dados, a):
+			s.hub.Reter(chave, eventoAlerta(ws.EventoAlertaAlterado, a))
+		}
+	}
+
+	for chave, anterior := range retidos {
+		if !atuais[chave] {
+			anterior.Nome = ws.EventoAlertaResolvido
+			anterior.Seq = 0
+			s.hub.Liberar(chave, anterior)
+		}
+	}
+}
+
+// Efetividade agrega o histórico dos alertas gerados em [desde, ate).
+func (s *AlertaService) Efetividade(ctx context.Context, desde, ate time.Time) (*Efetividade, error) {
+	porTipo, err := s.historico.Efetividade(ctx, repository.PorTipo, desde, ate)
+	if err != nil {
+		return nil, err
+	}
+	porRepresentante, err := s.historico.Efetividade(ctx, repository.PorRepresentante, desde, ate)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lista := range [][]repository.EfetividadeAlertas{porTipo, porRepresentante} {
+		for i := range lista {
+			if lista[i].Total > 0 {
+				lista[i].TaxaRecuperacao = float64(lista[i].Recuperados) / float64(lista[i].Total)
+			}
+		}
+	}
+
+	return &Efetividade{PorTipo: porTipo, PorRepresentante: porRepresentante}, nil
+}
+
+// mesmoConteudo compara os dados já decodificados do JSON: o estado vindo
+// de outra instância passou pelo jsonb, que reordena as chaves, e não pode
+// ser comparado byte a byte com a serialização do alerta.
+func mesmoConteudo(a, b any) bool {
+	da, okA := decodificar(a)
+	db, okB := decodificar(b)
+	return okA && okB && reflect.DeepEqual(da, db)
+}
+
+func decodificar(v any) (any, bool) {
+	dados, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var decodificado any
+	if err := json.Unmarshal(dados, &decodificado); err != nil {
+		return nil, false
+	}
+	return decodificado, true
+}
+
+func eventoAlerta(nome string, a alerta.Alerta) ws.Evento {
+	return ws.Evento{
+		Nome:          nome,
+		Dados:         a,
+		ClienteID:     a.ClienteID,
+		TipoAlerta:    a.Tipo,
+		Representante: a.Representante,
+	}
+}
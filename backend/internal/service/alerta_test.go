@@ -0,0 +1,295 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+// geradorFixo devolve os alertas da vez, um lote por chamada a Gerar.
+type geradorFixo struct {
+	GeradorAlertas
+	lotes [][]alerta.Alerta
+}
+
+func (g *geradorFixo) Gerar(context.Context, ...string) ([]alerta.Alerta, error) {
+	lote := g.lotes[0]
+	g.lotes = g.lotes[1:]
+	return lote, nil
+}
+
+type historicoMemoria struct {
+	repository.AlertaRepository
+	erro        error
+	registrados [][]alerta.Alerta
+}
+
+func (h *historicoMemoria) RegistrarHistorico(_ context.Context, alertas []alerta.Alerta, _ time.Time) error {
+	h.registrados = append(h.registrados, alertas)
+	return h.erro
+}
+
+// tarefasMemoria trata todo alerta registrado como em aberto e sem tarefa.
+type tarefasMemoria struct {
+	repository.TarefaRepository
+	historico *historicoMemoria
+	criadas   []model.Tarefa
+}
+
+func (t *tarefasMemoria) AlertasSemTarefa(_ context.Context, tipos []string) ([]model.AlertaHistorico, error) {
+	var abertos []model.AlertaHistorico
+	for _, a := range t.historico.registrados[len(t.historico.registrados)-1] {
+		if slices.Contains(tipos, a.Tipo) && !slices.ContainsFunc(t.criadas, func(c model.Tarefa) bool { return c.ClienteID == a.ClienteID }) {
+			abertos = append(abertos, model.AlertaHistorico{ID: a.ClienteID, ClienteID: a.ClienteID, Tipo: a.Tipo, Motivo: a.Motivo})
+		}
+	}
+	return abertos, nil
+}
+
+func (t *tarefasMemoria) Criar(_ context.Context, tarefa *model.Tarefa) error {
+	t.criadas = append(t.criadas, *tarefa)
+	return nil
+}
+
+type regrasTarefaFixas struct {
+	repository.RegraTarefaRepository
+	regras []model.RegraTarefa
+}
+
+func (r regrasTarefaFixas) Listar(context.Context) ([]model.RegraTarefa, error) {
+	return r.regras, nil
+}
+
+// hubMemoria guarda o estado retido, a ordem dos eventos e os eventos
+// publicados sem retenção. Com jsonb, os dados retidos voltam como da tabela
+// de eventos de outra instância: em JSON, com as chaves na ordem do jsonb.
+type hubMemoria struct {
+	estado     map[string]ws.Evento
+	eventos    []string
+	publicados []ws.Evento
+	jsonb      bool
+}
+
+func (h *hubMemoria) Publicar(e ws.Evento) {
+	h.eventos = append(h.eventos, e.Nome)
+	h.publicados = append(h.publicados, e)
+}
+
+func (h *hubMemoria) Reter(chave string, e ws.Evento) {
+	if h.jsonb {
+		e.Dados = comoJsonb(e.Dados)
+	}
+	h.estado[chave] = e
+	h.eventos = append(h.eventos, e.Nome+" "+chave)
+}
+
+func (h *hubMemoria) Liberar(chave string, e ws.Evento) {
+	delete(h.estado, chave)
+	h.eventos = append(h.eventos, e.Nome+" "+chave)
+}
+
+func (h *hubMemoria) Retidos() map[string]ws.Evento {
+	retidos := make(map[string]ws.Evento, len(h.estado))
+	for chave, e := range h.estado {
+		retidos[chave] = e
+	}
+	return retidos
+}
+
+// comoJsonb serializa v como o Postgres devolve uma coluna jsonb: chaves
+// ordenadas pelo tamanho e depois pelos bytes, com espaço após ":" e ",".
+func comoJsonb(v any) json.RawMessage {
+	dados, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var decodificado any
+	if err := json.Unmarshal(dados, &decodificado); err != nil {
+		panic(err)
+	}
+	var b strings.Builder
+	escreverJsonb(&b, decodificado)
+	return json.RawMessage(b.String())
+}
+
+func escreverJsonb(b *strings.Builder, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		chaves := slices.Collect(maps.Keys(v))
+		slices.SortFunc(chaves, func(x, y string) int {
+			return cmp.Or(cmp.Compare(len(x), len(y)), strings.Compare(x, y))
+		})
+		b.WriteString("{")
+		for i, chave := range chaves {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			escreverJsonb(b, chave)
+			b.WriteString(": ")
+			escreverJsonb(b, v[chave])
+		}
+		b.WriteString("}")
+	case []any:
+		b.WriteString("[")
+		for i, item := range v {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			escreverJsonb(b, item)
+		}
+		b.WriteString("]")
+	default:
+		dados, _ := json.Marshal(v)
+		b.Write(dados)
+	}
+}
+
+type cenarioAlertas struct {
+	servico   *AlertaService
+	historico *historicoMemoria
+	tarefas   *tarefasMemoria
+	hub       *hubMemoria
+}
+
+func novoCenarioAlertas(lotes ...[]alerta.Alerta) cenarioAlertas {
+	historico := &historicoMemoria{}
+	tarefas := &tarefasMemoria{historico: historico}
+	hub := &hubMemoria{estado: map[string]ws.Evento{}}
+	regras := regrasTarefaFixas{regras: []model.RegraTarefa{
+		{TipoAlerta: alerta.TipoInatividade, Ativa: true, PrazoDias: 2, Responsavel: "Carla"},
+	}}
+	servicoTarefas := NewTarefaService(tarefas, regras, hub, time.UTC)
+	return cenarioAlertas{
+		servico:   NewAlertaService(&geradorFixo{lotes: lotes}, historico, servicoTarefas, hub),
+		historico: historico,
+		tarefas:   tarefas,
+		hub:       hub,
+	}
+}
+
+func TestPublicarEnviaSoAsDiferencas(t *testing.T) {
+	inativo := alerta.Alerta{ClienteID: "c1", Tipo: alerta.TipoInatividade, Motivo: "Cliente não compra há mais de 30 dias.", DiasAtraso: 3}
+	previsto := alerta.Alerta{ClienteID: "c2", Tipo: alerta.TipoDiaPrevisto, Motivo: "O cliente não comprou no dia previsto (14/10)."}
+	atrasado := inativo
+	atrasado.DiasAtraso = 4
+
+	c := novoCenarioAlertas(
+		[]alerta.Alerta{inativo, previsto},
+		[]alerta.Alerta{inativo, previsto},
+		[]alerta.Alerta{atrasado},
+	)
+	ctx := context.Background()
+
+	if err := c.servico.Publicar(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// A tarefa sai antes, ao criar as tarefas dos alertas registrados
+	esperado := []string{
+		ws.EventoTarefaCriada,
+		"alerta.criado c1|inatividade|",
+		"alerta.criado c2|dia_previsto|",
+	}
+	if !slices.Equal(c.hub.eventos, esperado) {
+		t.Fatalf("primeira publicação = %v, esperado %v", c.hub.eventos, esperado)
+	}
+
+	// Sem mudança nos alertas, nada é publicado e a tarefa não se repete
+	c.hub.eventos = nil
+	if err := c.servico.Publicar(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.hub.eventos) != 0 {
+		t.Fatalf("segunda publicação = %v, esperado nenhum evento", c.hub.eventos)
+	}
+
+	if err := c.servico.Publicar(ctx); err != nil {
+		t.Fatal(err)
+	}
+	esperado = []string{"alerta.alterado c1|inatividade|", "alerta.resolvido c2|dia_previsto|"}
+	if !slices.Equal(c.hub.eventos, esperado) {
+		t.Fatalf("terceira publicação = %v, esperado %v", c.hub.eventos, esperado)
+	}
+
+	if len(c.historico.registrados) != 3 || len(c.historico.registrados[2]) != 1 {
+		t.Errorf("histórico registrado = %v", c.historico.registrados)
+	}
+	if len(c.tarefas.criadas) != 1 {
+		t.Fatalf("tarefas criadas = %d, esperado 1", len(c.tarefas.criadas))
+	}
+	tarefa := c.tarefas.criadas[0]
+	if tarefa.ClienteID != "c1" || tarefa.Responsavel != "Carla" || tarefa.Descricao != inativo.Motivo {
+		t.Errorf("tarefa = %+v", tarefa)
+	}
+}
+
+// Com o estado retido vindo do jsonb, alertas que não mudaram não são
+// reenviados como alterados.
+func TestPublicarComEstadoDoJsonb(t *testing.T) {
+	faltando := alerta.Alerta{
+		ClienteID:      "c1",
+		NomeCliente:    "Ana",
+		Tipo:           alerta.TipoItemFaltando,
+		Motivo:         "Cliente deixou de comprar itens recorrentes.",
+		Severidade:     alerta.SeveridadeAlta,
+		Prioridade:     0.75,
+		ItensFaltantes: []string{"Arroz", "Feijão"},
+		ItensDetalhados: []alerta.ItemDetalhado{
+			{Nome: "Arroz", UltimaCompra: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	alterado := faltando
+	alterado.Prioridade = 0.5
+
+	c := novoCenarioAlertas([]alerta.Alerta{faltando}, []alerta.Alerta{faltando}, []alerta.Alerta{alterado})
+	c.hub.jsonb = true
+	ctx := context.Background()
+
+	if err := c.servico.Publicar(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if dados := string(c.hub.estado[faltando.Chave()].Dados.(json.RawMessage)); !strings.HasPrefix(dados, `{"tipo": `) {
+		t.Fatalf("dados retidos = %s, esperado na ordem do jsonb", dados)
+	}
+
+	c.hub.eventos = nil
+	if err := c.servico.Publicar(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.hub.eventos) != 0 {
+		t.Fatalf("segunda publicação = %v, esperado nenhum evento", c.hub.eventos)
+	}
+
+	if err := c.servico.Publicar(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alerta.alterado " + faltando.Chave()}; !slices.Equal(c.hub.eventos, want) {
+		t.Errorf("terceira publicação = %v, esperado %v", c.hub.eventos, want)
+	}
+}
+
+func TestPublicarSemHistoricoNaoCriaTarefas(t *testing.T) {
+	c := novoCenarioAlertas([]alerta.Alerta{{ClienteID: "c1", Tipo: alerta.TipoInatividade}})
+	c.historico.erro = errors.New("banco fora do ar")
+
+	if err := c.servico.Publicar(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// A tarefa precisa do registro do alerta; os alertas saem mesmo assim
+	if len(c.tarefas.criadas) != 0 {
+		t.Errorf("tarefas criadas = %d, esperado 0", len(c.tarefas.criadas))
+	}
+	if want := []string{"alerta.criado c1|inatividade|"}; !slices.Equal(c.hub.eventos, want) {
+		t.Errorf("eventos = %v, esperado %v", c.hub.eventos, want)
+	}
+}
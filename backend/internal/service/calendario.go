@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"smart-retention/internal/calendario"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+)
+
+type (
+	// CalendarioService mantém os feriados cadastrados e os fechamentos dos
+	// clientes, que o motor de alertas consulta junto com os feriados
+	// nacionais.
+	CalendarioService struct {
+		clientes    repository.ClienteRepository
+		feriados    repository.FeriadoRepository
+		fechamentos repository.FechamentoRepository
+		local       *time.Location
+	}
+
+	// DiaFeriado é um feriado do calendário: nacional, calculado e sem ID,
+	// ou cadastrado.
+	DiaFeriado struct {
+		ID          string
+		Data        time.Time
+		Nome        string
+		Nacional    bool
+		Facultativo bool
+	}
+)
+
+func NewCalendarioService(clientes repository.ClienteRepository, feriados repository.FeriadoRepository, fechamentos repository.FechamentoRepository, local *time.Location) *CalendarioService {
+	return &CalendarioService{clientes: clientes, feriados: feriados, fechamentos: fechamentos, local: local}
+}
+
+// ListarFeriados junta os feriados nacionais e os cadastrados do ano, em
+// ordem de data. Sem ano, usa o corrente.
+func (s *CalendarioService) ListarFeriados(ctx context.Context, ano int) ([]DiaFeriado, error) {
+	if ano == 0 {
+		ano = time.Now().In(s.local).Year()
+	}
+	inicio := time.Date(ano, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cadastrados, err := s.feriados.Listar(ctx, inicio, inicio.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	var dias []DiaFeriado
+	for _, f := range calendario.Nacionais(ano) {
+		dias = append(dias, DiaFeriado{Data: f.Data, Nome: f.Nome, Nacional: true, Facultativo: f.Facultativo})
+	}
+	for _, f := range cadastrados {
+		dias = append(dias, DiaFeriado{ID: f.ID, Data: f.Data.UTC(), Nome: f.Nome})
+	}
+	sort.SliceStable(dias, func(i, j int) bool { return dias[i].Data.Before(dias[j].Data) })
+	return dias, nil
+}
+
+// CriarFeriado cadastra um feriado; datas que já são feriado nacional são
+// recusadas. Um ponto facultativo pode ser cadastrado, o que o torna feriado
+// para todos os clientes.
+func (s *CalendarioService) CriarFeriado(ctx context.Context, feriado model.Feriado) (*model.Feriado, error) {
+	for _, f := range calendario.Nacionais(feriado.Data.Year()) {
+		if f.Data.Equal(feriado.Data) && !f.Facultativo {
+			return nil, falha.Conflito("A data já é feriado nacional",
+				falha.Campo{Nome: "data", Mensagem: "feriado nacional: " + f.Nome})
+		}
+	}
+
+	feriado.ID = ""
+	if err := s.feriados.Criar(ctx, &feriado); err != nil {
+		return nil, err
+	}
+	return &feriado, nil
+}
+
+func (s *CalendarioService) DeletarFeriado(ctx context.Context, id string) error {
+	return s.feriados.Deletar(ctx, id)
+}
+
+func (s *CalendarioService) ListarFechamentos(ctx context.Context, clienteID string) ([]model.Fechamento, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	return s.fechamentos.ListarPorCliente(ctx, clienteID)
+}
+
+func (s *CalendarioService) CriarFechamento(ctx context.Context, clienteID string, fechamento model.Fechamento) (*model.Fechamento, error) {
+	if fechamento.Fim.Before(fechamento.Inicio) {
+		return nil, falha.Validacao("Período inválido",
+			falha.Campo{Nome: "fim", Mensagem: "deve ser igual ou posterior a inicio"})
+	}
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+
+	fechamento.ID = ""
+	fechamento.ClienteID = clienteID
+	fechamento.CriadoEm = time.Now()
+	if err := s.fechamentos.Criar(ctx, &fechamento); err != nil {
+		return nil, err
+	}
+	return &fechamento, nil
+}
+
+func (s *CalendarioService) DeletarFechamento(ctx context.Context, clienteID, id string) error {
+	return s.fechamentos.Deletar(ctx, clienteID, id)
+}
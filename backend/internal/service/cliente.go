@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+type (
+	ClienteService struct {
+		clientes   repository.ClienteRepository
+		compras    repository.CompraRepository
+		contatos   repository.ContatoRepository
+		interacoes repository.InteracaoRepository
+		hub        ws.Publicador
+		local      *time.Location // fuso do "hoje" dos alertas
+	}
+
+	// Historico é o cliente com os seus contatos, as compras e as
+	// interações, estas duas da mais recente para a mais antiga.
+	// AlertasPausadosAte é o fim da pausa de alertas em vigor, se houver.
+	Historico struct {
+		Cliente            *model.Cliente
+		Contatos           []model.Contato
+		Compras            []model.Compra
+		Interacoes         []model.Interacao
+		AlertasPausadosAte *time.Time
+	}
+)
+
+func NewClienteService(
+	clientes repository.ClienteRepository,
+	compras repository.CompraRepository,
+	contatos repository.ContatoRepository,
+	interacoes repository.InteracaoRepository,
+	hub ws.Publicador,
+	local *time.Location,
+) *ClienteService {
+	return &ClienteService{
+		clientes:   clientes,
+		compras:    compras,
+		contatos:   contatos,
+		interacoes: interacoes,
+		hub:        hub,
+		local:      local,
+	}
+}
+
+func (s *ClienteService) Listar(ctx context.Context) ([]model.Cliente, error) {
+	return s.clientes.Listar(ctx)
+}
+
+func (s *ClienteService) BuscarPorID(ctx context.Context, id string) (*model.Cliente, error) {
+	return s.clientes.BuscarPorID(ctx, id)
+}
+
+// Criar grava o cliente com itens e dias de compra e o devolve como ficou
+// no banco.
+func (s *ClienteService) Criar(ctx context.Context, cliente model.Cliente) (*model.Cliente, error) {
+	if err := normalizarDiasCompra(cliente.DiasCompra); err != nil {
+		return nil, err
+	}
+	if err := s.clientes.Criar(ctx, &cliente); err != nil {
+		return nil, err
+	}
+	return s.clientes.BuscarPorID(ctx, cliente.ID)
+}
+
+// Atualizar troca os dados, os itens e os dias de compra do cliente e avisa
+// o hub.
+func (s *ClienteService) Atualizar(ctx context.Context, id string, dados model.Cliente) (*model.Cliente, error) {
+	if err := normalizarDiasCompra(dados.DiasCompra); err != nil {
+		return nil, err
+	}
+	cliente, err := s.clientes.BuscarPorID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cliente.Nome = dados.Nome
+	cliente.CNPJ = dados.CNPJ
+	cliente.Telefone = dados.Telefone
+	cliente.Email = dados.Email
+	cliente.Endereco = dados.Endereco
+	cliente.Representante = dados.Representante
+	cliente.FechaFacultativos = dados.FechaFacultativos
+	cliente.Itens = dados.Itens
+	cliente.DiasCompra = dados.DiasCompra
+
+	if err := s.clientes.Atualizar(ctx, cliente); err != nil {
+		return nil, err
+	}
+
+	s.hub.Publicar(ws.Evento{
+		Nome: ws.EventoClienteAtualizado,
+		Dados: map[string]any{
+			"id":            cliente.ID,
+			"nome":          cliente.Nome,
+			"cnpj":          cliente.CNPJ,
+			"representante": cliente.Representante,
+		},
+		ClienteID:     cliente.ID,
+		Representante: cliente.Representante,
+	})
+
+	return cliente, nil
+}
+
+func (s *ClienteService) Deletar(ctx context.Context, id string) error {
+	return s.clientes.Deletar(ctx, id)
+}
+
+func (s *ClienteService) Historico(ctx context.Context, id string) (*Historico, error) {
+	cliente, err := s.clientes.BuscarPorID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	compras, err := s.compras.ListarPorCliente(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	contatos, err := s.contatos.ListarPorCliente(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	interacoes, err := s.interacoes.ListarPorCliente(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pausa, err := s.interacoes.AlertasPausadosAte(ctx, id, alerta.Dia(time.Now().In(s.local)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Historico{
+		Cliente:            cliente,
+		Contatos:           contatos,
+		Compras:            compras,
+		Interacoes:         interacoes,
+		AlertasPausadosAte: pausa,
+	}, nil
+}
+
+// normalizarDiasCompra confere os campos exigidos por cada frequência e
+// descarta os que não se aplicam a ela. Os horários ficam sempre em HH:MM.
+func normalizarDiasCompra(dias []model.DiaCompraCliente) error {
+	for i := range dias {
+		d := &dias[i]
+		campo := func(nome string) string { return fmt.Sprintf("dias_compra[%d].%s", i, nome) }
+
+		if d.Frequencia == "" {
+			d.Frequencia = model.FrequenciaSemanal
+		}
+		switch d.Frequencia {
+		case model.FrequenciaQuinzenal:
+			if d.Referencia == nil {
+				return falha.Validacao("Dados inválidos",
+					falha.Campo{Nome: campo("referencia"), Mensagem: "obrigatório na frequência quinzenal"})
+			}
+			d.SemanaDoMes = 0
+		case model.FrequenciaMensal:
+			if d.SemanaDoMes == 0 {
+				return falha.Validacao("Dados inválidos",
+					falha.Campo{Nome: campo("semana_do_mes"), Mensagem: "obrigatório na frequência mensal"})
+			}
+			d.Referencia = nil
+		default:
+			d.Referencia = nil
+			d.SemanaDoMes = 0
+		}
+
+		if err := lerHorario(campo("hora_fim"), &d.HoraFim); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lerHorario lê o horário HH:MM, se informado, e o regrava com dois dígitos
+// na hora ("9:00" vira "09:00").
+func lerHorario(campo string, valor *string) error {
+	if *valor == "" {
+		return nil
+	}
+	hora, err := time.Parse("15:04", *valor)
+	if err != nil {
+		return falha.Validacao("Dados inválidos", falha.Campo{Nome: campo, Mensagem: "use o formato HH:MM"})
+	}
+	*valor = hora.Format("15:04")
+	return nil
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+// clientesMemoria guarda cópias dos clientes, como o banco, para que os
+// testes vejam só o que foi gravado.
+type clientesMemoria struct {
+	repository.ClienteRepository
+	clientes   map[string]model.Cliente
+	gravacoes  int
+	erroGravar error
+	proximoID  string
+}
+
+func novosClientesMemoria(clientes ...model.Cliente) *clientesMemoria {
+	r := &clientesMemoria{clientes: map[string]model.Cliente{}, proximoID: "novo"}
+	for _, c := range clientes {
+		r.clientes[c.ID] = c
+	}
+	return r
+}
+
+func (r *clientesMemoria) Listar(context.Context) ([]model.Cliente, error) {
+	clientes := make([]model.Cliente, 0, len(r.clientes))
+	for _, c := range r.clientes {
+		clientes = append(clientes, c)
+	}
+	return clientes, nil
+}
+
+func (r *clientesMemoria) BuscarPorID(_ context.Context, id string) (*model.Cliente, error) {
+	c, ok := r.clientes[id]
+	if !ok {
+		return nil, repository.ErrClienteNaoEncontrado
+	}
+	return &c, nil
+}
+
+func (r *clientesMemoria) Criar(_ context.Context, cliente *model.Cliente) error {
+	r.gravacoes++
+	if r.erroGravar != nil {
+		return r.erroGravar
+	}
+	cliente.ID = r.proximoID
+	r.clientes[cliente.ID] = *cliente
+	return nil
+}
+
+func (r *clientesMemoria) Atualizar(_ context.Context, cliente *model.Cliente) error {
+	r.gravacoes++
+	if r.erroGravar != nil {
+		return r.erroGravar
+	}
+	r.clientes[cliente.ID] = *cliente
+	return nil
+}
+
+// comprasMemoria devolve as compras com o cliente de clientes, como o
+// Preload do repositório.
+type comprasMemoria struct {
+	repository.CompraRepository
+	compras    []model.Compra
+	clientes   *clientesMemoria
+	erroCriar  error
+	erroBuscar error
+	erroListar error
+}
+
+func (r *comprasMemoria) Criar(_ context.Context, compra *model.Compra) error {
+	if r.erroCriar != nil {
+		return r.erroCriar
+	}
+	compra.ID = "compra-" + compra.DataCompra.Format("2006-01-02")
+	r.compras = append(r.compras, *compra)
+	return nil
+}
+
+func (r *comprasMemoria) BuscarPorID(_ context.Context, id string) (*model.Compra, error) {
+	if r.erroBuscar != nil {
+		return nil, r.erroBuscar
+	}
+	for _, c := range r.compras {
+		if c.ID == id {
+			if r.clientes != nil {
+				c.Cliente = r.clientes.clientes[c.ClienteID]
+			}
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrCompraNaoEncontrada
+}
+
+func (r *comprasMemoria) Listar(context.Context) ([]model.Compra, error) {
+	return r.compras, r.erroListar
+}
+
+func (r *comprasMemoria) ListarPorCliente(_ context.Context, clienteID string) ([]model.Compra, error) {
+	if r.erroListar != nil {
+		return nil, r.erroListar
+	}
+	var compras []model.Compra
+	for _, c := range r.compras {
+		if c.ClienteID == clienteID {
+			compras = append(compras, c)
+		}
+	}
+	return compras, nil
+}
+
+type contatosFixos struct {
+	repository.ContatoRepository
+	contatos []model.Contato
+}
+
+func (r contatosFixos) ListarPorCliente(context.Context, string) ([]model.Contato, error) {
+	return r.contatos, nil
+}
+
+// interacoesFixas registra o dia pedido a AlertasPausadosAte.
+type interacoesFixas struct {
+	repository.InteracaoRepository
+	interacoes []model.Interacao
+	pausa      *time.Time
+	hoje       time.Time
+}
+
+func (r *interacoesFixas) ListarPorCliente(context.Context, string) ([]model.Interacao, error) {
+	return r.interacoes, nil
+}
+
+func (r *interacoesFixas) AlertasPausadosAte(_ context.Context, _ string, hoje time.Time) (*time.Time, error) {
+	r.hoje = hoje
+	return r.pausa, nil
+}
+
+func novoHub() *hubMemoria {
+	return &hubMemoria{estado: map[string]ws.Evento{}}
+}
+
+func TestNormalizarDiasCompra(t *testing.T) {
+	referencia := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+
+	casos := []struct {
+		nome      string
+		dia       model.DiaCompraCliente
+		esperado  model.DiaCompraCliente
+		erroCampo string
+	}{
+		{
+			nome:     "sem frequência é semanal e descarta referência e semana do mês",
+			dia:      model.DiaCompraCliente{DiaSemana: 1, Referencia: &referencia, SemanaDoMes: 2},
+			esperado: model.DiaCompraCliente{DiaSemana: 1, Frequencia: model.FrequenciaSemanal},
+		},
+		{
+			nome:     "quinzenal mantém a referência e descarta a semana do mês",
+			dia:      model.DiaCompraCliente{DiaSemana: 1, Frequencia: model.FrequenciaQuinzenal, Referencia: &referencia, SemanaDoMes: 2},
+			esperado: model.DiaCompraCliente{DiaSemana: 1, Frequencia: model.FrequenciaQuinzenal, Referencia: &referencia},
+		},
+		{
+			nome:      "quinzenal sem referência",
+			dia:       model.DiaCompraCliente{DiaSemana: 1, Frequencia: model.FrequenciaQuinzenal},
+			erroCampo: "dias_compra[0].referencia",
+		},
+		{
+			nome:     "mensal mantém a semana do mês e descarta a referência",
+			dia:      model.DiaCompraCliente{DiaSemana: 5, Frequencia: model.FrequenciaMensal, Referencia: &referencia, SemanaDoMes: -1},
+			esperado: model.DiaCompraCliente{DiaSemana: 5, Frequencia: model.FrequenciaMensal, SemanaDoMes: -1},
+		},
+		{
+			nome:      "mensal sem semana do mês",
+			dia:       model.DiaCompraCliente{DiaSemana: 5, Frequencia: model.FrequenciaMensal},
+			erroCampo: "dias_compra[0].semana_do_mes",
+		},
+		{
+			nome:     "horário com um dígito na hora",
+			dia:      model.DiaCompraCliente{DiaSemana: 2, HoraFim: "9:30"},
+			esperado: model.DiaCompraCliente{DiaSemana: 2, Frequencia: model.FrequenciaSemanal, HoraFim: "09:30"},
+		},
+		{
+			nome:      "horário inválido",
+			dia:       model.DiaCompraCliente{DiaSemana: 2, HoraFim: "25:00"},
+			erroCampo: "dias_compra[0].hora_fim",
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			dias := []model.DiaCompraCliente{c.dia}
+			err := normalizarDiasCompra(dias)
+
+			if c.erroCampo != "" {
+				e := falha.De(err)
+				if e.Codigo != falha.CodigoValidacao || len(e.Campos) != 1 || e.Campos[0].Nome != c.erroCampo {
+					t.Fatalf("erro = %+v, esperada validação de %s", e, c.erroCampo)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(dias[0], c.esperado) {
+				t.Errorf("dia = %+v, esperado %+v", dias[0], c.esperado)
+			}
+		})
+	}
+}
+
+// O índice no nome do campo é o do dia com problema.
+func TestNormalizarDiasCompraApontaODia(t *testing.T) {
+	dias := []model.DiaCompraCliente{{DiaSemana: 1}, {DiaSemana: 3, Frequencia: model.FrequenciaMensal}}
+	e := falha.De(normalizarDiasCompra(dias))
+	if len(e.Campos) != 1 || e.Campos[0].Nome != "dias_compra[1].semana_do_mes" {
+		t.Errorf("campos = %+v, esperado dias_compra[1].semana_do_mes", e.Campos)
+	}
+}
+
+func TestCriarClienteNormalizaEDevolveOGravado(t *testing.T) {
+	clientes := novosClientesMemoria()
+	s := NewClienteService(clientes, &comprasMemoria{}, contatosFixos{}, &interacoesFixas{}, novoHub(), time.UTC)
+
+	criado, err := s.Criar(context.Background(), model.Cliente{
+		Nome:       "Mercado Central",
+		DiasCompra: []model.DiaCompraCliente{{DiaSemana: 1, HoraFim: "8:00"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if criado.ID != "novo" {
+		t.Errorf("id = %q, esperado o atribuído pelo repositório", criado.ID)
+	}
+	dia := clientes.clientes["novo"].DiasCompra[0]
+	if dia.Frequencia != model.FrequenciaSemanal || dia.HoraFim != "08:00" {
+		t.Errorf("dia gravado = %+v, esperado normalizado", dia)
+	}
+}
+
+func TestCriarClienteInvalidoNaoGrava(t *testing.T) {
+	clientes := novosClientesMemoria()
+	s := NewClienteService(clientes, &comprasMemoria{}, contatosFixos{}, &interacoesFixas{}, novoHub(), time.UTC)
+
+	_, err := s.Criar(context.Background(), model.Cliente{
+		DiasCompra: []model.DiaCompraCliente{{DiaSemana: 1, Frequencia: model.FrequenciaQuinzenal}},
+	})
+	if falha.De(err).Codigo != falha.CodigoValidacao {
+		t.Fatalf("erro = %v, esperado de validação", err)
+	}
+	if clientes.gravacoes != 0 {
+		t.Errorf("%d gravações, esperado nenhuma", clientes.gravacoes)
+	}
+}
+
+func TestAtualizarClienteTrocaOsDadosEAvisaOHub(t *testing.T) {
+	clientes := novosClientesMemoria(model.Cliente{
+		ID:         "c1",
+		Nome:       "Mercado Central",
+		CNPJ:       "12345678000199",
+		Itens:      []model.Item{{ID: "i1", Nome: "Arroz"}},
+		DiasCompra: []model.DiaCompraCliente{{DiaSemana: 1, Frequencia: model.FrequenciaSemanal}},
+	})
+	hub := novoHub()
+	s := NewClienteService(clientes, &comprasMemoria{}, contatosFixos{}, &interacoesFixas{}, hub, time.UTC)
+
+	atualizado, err := s.Atualizar(context.Background(), "c1", model.Cliente{
+		Nome:          "Mercado Central Ltda",
+		CNPJ:          "12345678000199",
+		Representante: "Bruno",
+		Itens:         []model.Item{{Nome: "Feijão"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atualizado.ID != "c1" || atualizado.Nome != "Mercado Central Ltda" {
+		t.Errorf("cliente = %+v", atualizado)
+	}
+	gravado := clientes.clientes["c1"]
+	if len(gravado.Itens) != 1 || gravado.Itens[0].Nome != "Feijão" || len(gravado.DiasCompra) != 0 {
+		t.Errorf("itens e dias gravados = %+v %+v, esperado só os novos", gravado.Itens, gravado.DiasCompra)
+	}
+
+	if len(hub.publicados) != 1 {
+		t.Fatalf("%d eventos publicados, esperado 1", len(hub.publicados))
+	}
+	e := hub.publicados[0]
+	if e.Nome != ws.EventoClienteAtualizado || e.ClienteID != "c1" || e.Representante != "Bruno" {
+		t.Errorf("evento = %+v", e)
+	}
+}
+
+func TestAtualizarClienteInexistente(t *testing.T) {
+	clientes := novosClientesMemoria()
+	hub := novoHub()
+	s := NewClienteService(clientes, &comprasMemoria{}, contatosFixos{}, &interacoesFixas{}, hub, time.UTC)
+
+	_, err := s.Atualizar(context.Background(), "c1", model.Cliente{Nome: "Mercado"})
+	if !errors.Is(err, repository.ErrClienteNaoEncontrado) {
+		t.Fatalf("erro = %v, esperado cliente não encontrado", err)
+	}
+	if clientes.gravacoes != 0 || len(hub.publicados) != 0 {
+		t.Errorf("gravou %d vezes e publicou %d eventos, esperado nada", clientes.gravacoes, len(hub.publicados))
+	}
+}
+
+func TestAtualizarClienteComErroNaoAvisaOHub(t *testing.T) {
+	clientes := novosClientesMemoria(model.Cliente{ID: "c1", Nome: "Mercado"})
+	clientes.erroGravar = falha.Novo(falha.CodigoConflito, "Já existe um cliente com este CNPJ")
+	hub := novoHub()
+	s := NewClienteService(clientes, &comprasMemoria{}, contatosFixos{}, &interacoesFixas{}, hub, time.UTC)
+
+	if _, err := s.Atualizar(context.Background(), "c1", model.Cliente{Nome: "Mercado"}); !errors.Is(err, clientes.erroGravar) {
+		t.Fatalf("erro = %v, esperado o do repositório", err)
+	}
+	if len(hub.publicados) != 0 {
+		t.Errorf("%d eventos publicados, esperado nenhum", len(hub.publicados))
+	}
+}
+
+func TestHistoricoReuneOsDadosDoCliente(t *testing.T) {
+	local := time.FixedZone("BRT", -3*60*60)
+	clientes := novosClientesMemoria(model.Cliente{ID: "c1", Nome: "Mercado"}, model.Cliente{ID: "c2", Nome: "Padaria"})
+	compras := &comprasMemoria{compras: []model.Compra{
+		{ID: "k1", ClienteID: "c1", DataCompra: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+		{ID: "k2", ClienteID: "c2", DataCompra: time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)},
+	}}
+	pausa := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	interacoes := &interacoesFixas{
+		interacoes: []model.Interacao{{ID: "n1", ClienteID: "c1"}},
+		pausa:      &pausa,
+	}
+	contatos := contatosFixos{contatos: []model.Contato{{ID: "p1", ClienteID: "c1", Nome: "Ana"}}}
+	s := NewClienteService(clientes, compras, contatos, interacoes, novoHub(), local)
+
+	h, err := s.Historico(context.Background(), "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Cliente.ID != "c1" || len(h.Compras) != 1 || h.Compras[0].ID != "k1" {
+		t.Errorf("cliente %q com compras %+v, esperado só a compra do cliente", h.Cliente.ID, h.Compras)
+	}
+	if len(h.Contatos) != 1 || len(h.Interacoes) != 1 {
+		t.Errorf("contatos %+v e interações %+v", h.Contatos, h.Interacoes)
+	}
+	if h.AlertasPausadosAte == nil || !h.AlertasPausadosAte.Equal(pausa) {
+		t.Errorf("pausa = %v, esperado %v", h.AlertasPausadosAte, pausa)
+	}
+	// A pausa é conferida no dia corrente do fuso dos alertas
+	if hoje := alerta.Dia(time.Now().In(local)); !interacoes.hoje.Equal(hoje) {
+		t.Errorf("pausa conferida em %v, esperado %v", interacoes.hoje, hoje)
+	}
+}
+
+func TestHistoricoDeClienteInexistente(t *testing.T) {
+	s := NewClienteService(novosClientesMemoria(), &comprasMemoria{}, contatosFixos{}, &interacoesFixas{}, novoHub(), time.UTC)
+
+	if _, err := s.Historico(context.Background(), "c1"); !errors.Is(err, repository.ErrClienteNaoEncontrado) {
+		t.Errorf("erro = %v, esperado cliente não encontrado", err)
+	}
+}
+
+func TestHistoricoComErroNasCompras(t *testing.T) {
+	erro := errors.New("conexão perdida")
+	compras := &comprasMemoria{erroListar: erro}
+	s := NewClienteService(novosClientesMemoria(model.Cliente{ID: "c1"}), compras, contatosFixos{}, &interacoesFixas{}, novoHub(), time.UTC)
+
+	if _, err := s.Historico(context.Background(), "c1"); !errors.Is(err, erro) {
+		t.Errorf("erro = %v, esperado o do repositório de compras", err)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+type CompraService struct {
+	compras repository.CompraRepository
+	hub     ws.Publicador
+}
+
+func NewCompraService(compras repository.CompraRepository, hub ws.Publicador) *CompraService {
+	return &CompraService{compras: compras, hub: hub}
+}
+
+// Criar registra a compra, o que também fecha os alertas em aberto do
+// cliente, e avisa o hub. Devolve a compra como ficou no banco, com cliente
+// e itens.
+func (s *CompraService) Criar(ctx context.Context, clienteID string, data time.Time, itens []model.CompraItem) (*model.Compra, error) {
+	compra := model.Compra{
+		ClienteID:  clienteID,
+		DataCompra: data,
+		Itens:      itens,
+	}
+	if err := s.compras.Criar(ctx, &compra); err != nil {
+		return nil, err
+	}
+
+	// A compra já está gravada: se a releitura falhar, devolve o que foi
+	// enviado, sem os nomes, em vez de um erro que levaria a repetir a compra.
+	criada, err := s.compras.BuscarPorID(ctx, compra.ID)
+	if err != nil {
+		slog.WarnContext(ctx, "erro ao reler compra criada", "compra_id", compra.ID, "erro", err)
+		return &compra, nil
+	}
+
+	var total float64
+	for _, item := range criada.Itens {
+		total += item.Preco
+	}
+
+	s.hub.Publicar(ws.Evento{
+		Nome: ws.EventoCompraCriada,
+		Dados: map[string]any{
+			"id":           criada.ID,
+			"cliente_id":   criada.ClienteID,
+			"nome_cliente": criada.Cliente.Nome,
+			"data":         criada.DataCompra,
+			"total":        total,
+		},
+		ClienteID:     criada.ClienteID,
+		Representante: criada.Cliente.Representante,
+	})
+
+	return criada, nil
+}
+
+func (s *CompraService) Listar(ctx context.Context) ([]model.Compra, error) {
+	return s.compras.Listar(ctx)
+}
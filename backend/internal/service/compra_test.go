@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smart-retention/internal/model"
+	"smart-retention/internal/ws"
+)
+
+func TestCriarCompraDevolveARelidaEAvisaOHub(t *testing.T) {
+	clientes := novosClientesMemoria(model.Cliente{ID: "c1", Nome: "Mercado Central", Representante: "Ana"})
+	compras := &comprasMemoria{clientes: clientes}
+	hub := novoHub()
+	s := NewCompraService(compras, hub)
+
+	data := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	criada, err := s.Criar(context.Background(), "c1", data, []model.CompraItem{
+		{ItemID: "i1", Quantidade: 2, Preco: 20},
+		{ItemID: "i2", Quantidade: 1, Preco: 9.5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if criada.ID != "compra-2026-10-19" || criada.Cliente.Nome != "Mercado Central" {
+		t.Errorf("compra = %+v, esperada a relida do repositório com o cliente", criada)
+	}
+
+	if len(hub.publicados) != 1 {
+		t.Fatalf("%d eventos publicados, esperado 1", len(hub.publicados))
+	}
+	e := hub.publicados[0]
+	if e.Nome != ws.EventoCompraCriada || e.ClienteID != "c1" || e.Representante != "Ana" {
+		t.Errorf("evento = %+v", e)
+	}
+	dados := e.Dados.(map[string]any)
+	if dados["total"] != 29.5 || dados["nome_cliente"] != "Mercado Central" || !dados["data"].(time.Time).Equal(data) {
+		t.Errorf("dados do evento = %+v", dados)
+	}
+}
+
+func TestCriarCompraComErroNaoAvisaOHub(t *testing.T) {
+	erro := errors.New("conexão perdida")
+	hub := novoHub()
+	s := NewCompraService(&comprasMemoria{erroCriar: erro}, hub)
+
+	if _, err := s.Criar(context.Background(), "c1", time.Now(), nil); !errors.Is(err, erro) {
+		t.Fatalf("erro = %v, esperado o do repositório", err)
+	}
+	if len(hub.publicados) != 0 {
+		t.Errorf("%d eventos publicados, esperado nenhum", len(hub.publicados))
+	}
+}
+
+// Gravada a compra, uma falha ao relê-la não vira erro: o cliente repetiria
+// uma compra que já existe.
+func TestCriarCompraSemReleituraDevolveOEnviado(t *testing.T) {
+	compras := &comprasMemoria{erroBuscar: errors.New("conexão perdida")}
+	hub := novoHub()
+	s := NewCompraService(compras, hub)
+
+	itens := []model.CompraItem{{ItemID: "i1", Quantidade: 1, Preco: 10}}
+	criada, err := s.Criar(context.Background(), "c1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), itens)
+	if err != nil {
+		t.Fatalf("erro = %v, esperado nenhum", err)
+	}
+	if criada.ID != "compra-2026-10-19" || criada.ClienteID != "c1" || len(criada.Itens) != 1 {
+		t.Errorf("compra = %+v, esperado o que foi enviado, com o ID gravado", criada)
+	}
+	if len(compras.compras) != 1 {
+		t.Errorf("%d compras gravadas, esperado 1", len(compras.compras))
+	}
+	if len(hub.publicados) != 0 {
+		t.Errorf("%d eventos publicados, esperado nenhum", len(hub.publicados))
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"smart-retention/internal/repository"
+)
+
+type DashboardService struct {
+	dashboard repository.DashboardRepository
+}
+
+func NewDashboardService(dashboard repository.DashboardRepository) *DashboardService {
+	return &DashboardService{dashboard: dashboard}
+}
+
+func (s *DashboardService) Resumo(ctx context.Context) (*repository.Dashboard, error) {
+	return s.dashboard.Resumo(ctx)
+}
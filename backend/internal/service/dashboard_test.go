@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"smart-retention/internal/repository"
+)
+
+type dashboardFixo struct {
+	resumo *repository.Dashboard
+	erro   error
+}
+
+func (d dashboardFixo) Resumo(context.Context) (*repository.Dashboard, error) {
+	return d.resumo, d.erro
+}
+
+func TestResumoDoDashboard(t *testing.T) {
+	resumo := &repository.Dashboard{
+		TotalClientes: 2,
+		TotalCompras:  3,
+		ComprasPorMes: []repository.QuantidadePorPeriodo{{Mes: "2026-10", Quantidade: 3}},
+	}
+	obtido, err := NewDashboardService(dashboardFixo{resumo: resumo}).Resumo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obtido != resumo {
+		t.Errorf("resumo = %+v, esperado o do repositório", obtido)
+	}
+
+	erro := errors.New("conexão perdida")
+	if _, err := NewDashboardService(dashboardFixo{erro: erro}).Resumo(context.Background()); !errors.Is(err, erro) {
+		t.Errorf("erro = %v, esperado o do repositório", err)
+	}
+}
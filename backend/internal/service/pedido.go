@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+)
+
+// comprasInferencia é quantas das últimas compras são usadas para inferir o
+// pedido recorrente e o intervalo entre pedidos.
+const comprasInferencia = 8
+
+// Origens de uma compra repetida.
+const (
+	OrigemPedidoRecorrente = "pedido_recorrente"
+	OrigemUltimaCompra     = "ultima_compra"
+)
+
+// Bases da previsão do próximo pedido.
+const (
+	BaseDiasCompra = "dias_compra"
+	BaseHistorico  = "historico"
+)
+
+type (
+	// PedidoService cuida do pedido recorrente de cada cliente e da previsão
+	// do próximo pedido. As compras repetidas são registradas pelo
+	// CompraService, como as demais.
+	PedidoService struct {
+		clientes repository.ClienteRepository
+		compras  repository.CompraRepository
+		pedidos  repository.PedidoRecorrenteRepository
+		registro *CompraService
+		gerador  GeradorAlertas
+	}
+
+	// ProximoPedido diz quando o cliente deve voltar a comprar e o quê.
+	// Pelos dias de compra (Base dias_compra), Data é o dia previsto e Ate o
+	// último dia tolerado; sem eles, Data vem do intervalo mediano entre as
+	// últimas compras (Base historico). Sem nenhum dos dois, Data é nil.
+	ProximoPedido struct {
+		Data   *time.Time
+		Ate    *time.Time
+		Base   string
+		Pedido *model.PedidoRecorrente
+	}
+)
+
+func NewPedidoService(clientes repository.ClienteRepository, compras repository.CompraRepository, pedidos repository.PedidoRecorrenteRepository, registro *CompraService, gerador GeradorAlertas) *PedidoService {
+	return &PedidoService{clientes: clientes, compras: compras, pedidos: pedidos, registro: registro, gerador: gerador}
+}
+
+// PedidoRecorrente devolve o pedido salvo do cliente ou, sem ele, o inferido
+// das últimas compras, sem salvá-lo.
+func (s *PedidoService) PedidoRecorrente(ctx context.Context, clienteID string) (*model.PedidoRecorrente, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	pedido, err := s.pedidos.BuscarPorCliente(ctx, clienteID)
+	if err != nil || pedido != nil {
+		return pedido, err
+	}
+	return s.inferir(ctx, clienteID)
+}
+
+// SalvarPedidoRecorrente substitui o pedido do cliente pelo editado à mão.
+func (s *PedidoService) SalvarPedidoRecorrente(ctx context.Context, clienteID string, itens []model.ItemPedidoRecorrente) (*model.PedidoRecorrente, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	vistos := make(map[string]bool, len(itens))
+	for i, item := range itens {
+		if vistos[item.ItemID] {
+			return nil, falha.Validacao("Item repetido no pedido",
+				falha.Campo{Nome: fmt.Sprintf("itens[%d].item_id", i), Mensagem: "item repetido"})
+		}
+		vistos[item.ItemID] = true
+	}
+
+	return s.salvar(ctx, &model.PedidoRecorrente{ClienteID: clienteID, Itens: itens})
+}
+
+// InferirPedidoRecorrente recalcula o pedido a partir das últimas compras e
+// o salva no lugar do atual.
+func (s *PedidoService) InferirPedidoRecorrente(ctx context.Context, clienteID string) (*model.PedidoRecorrente, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	pedido, err := s.inferir(ctx, clienteID)
+	if err != nil {
+		return nil, err
+	}
+	return s.salvar(ctx, pedido)
+}
+
+func (s *PedidoService) DeletarPedidoRecorrente(ctx context.Context, clienteID string) error {
+	return s.pedidos.Deletar(ctx, clienteID)
+}
+
+// ProximoPedido prevê o próximo pedido do cliente com o seu pedido
+// recorrente (salvo ou inferido).
+func (s *PedidoService) ProximoPedido(ctx context.Context, clienteID string) (*ProximoPedido, error) {
+	pedido, err := s.PedidoRecorrente(ctx, clienteID)
+	if err != nil {
+		return nil, err
+	}
+	proximo := &ProximoPedido{Pedido: pedido}
+
+	janela, err := s.gerador.ProximaJanela(ctx, clienteID)
+	if err != nil {
+		return nil, err
+	}
+	if janela != nil {
+		proximo.Data, proximo.Ate, proximo.Base = &janela.Dia, &janela.Ultimo, BaseDiasCompra
+		return proximo, nil
+	}
+
+	compras, err := s.compras.ListarPorCliente(ctx, clienteID)
+	if err != nil {
+		return nil, err
+	}
+	if intervalo, ok := intervaloMediano(compras); ok {
+		data := compras[0].DataCompra.UTC().AddDate(0, 0, intervalo)
+		if hoje := alerta.Dia(time.Now().In(s.gerador.Location())); data.Before(hoje) {
+			data = hoje // atrasado: o pedido é esperado já
+		}
+		proximo.Data, proximo.Base = &data, BaseHistorico
+	}
+	return proximo, nil
+}
+
+// Repetir registra uma compra igual ao pedido recorrente ou à última compra
+// do cliente, na data informada ou hoje. Sem origem, usa o pedido salvo e,
+// se não houver, a última compra.
+func (s *PedidoService) Repetir(ctx context.Context, clienteID, origem string, data time.Time) (*model.Compra, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	if data.IsZero() {
+		data = alerta.Dia(time.Now().In(s.gerador.Location()))
+	}
+
+	var pedido *model.PedidoRecorrente
+	var err error
+	switch origem {
+	case "":
+		if pedido, err = s.pedidos.BuscarPorCliente(ctx, clienteID); err != nil {
+			return nil, err
+		}
+		if pedido == nil {
+			origem = OrigemUltimaCompra
+		}
+	case OrigemPedidoRecorrente:
+		if pedido, err = s.PedidoRecorrente(ctx, clienteID); err != nil {
+			return nil, err
+		}
+	case OrigemUltimaCompra:
+	default:
+		return nil, falha.Validacao("Origem inválida",
+			falha.Campo{Nome: "origem", Mensagem: "use " + OrigemPedidoRecorrente + " ou " + OrigemUltimaCompra})
+	}
+
+	var itens []model.CompraItem
+	if origem == OrigemUltimaCompra {
+		compras, err := s.compras.ListarPorCliente(ctx, clienteID)
+		if err != nil {
+			return nil, err
+		}
+		if len(compras) == 0 {
+			return nil, falha.Validacao("O cliente ainda não tem compras para repetir",
+				falha.Campo{Nome: "origem", Mensagem: "sem compras"})
+		}
+		for _, item := range compras[0].Itens {
+			itens = append(itens, model.CompraItem{ItemID: item.ItemID, Quantidade: item.Quantidade, Preco: item.Preco})
+		}
+	} else {
+		for _, item := range pedido.Itens {
+			itens = append(itens, model.CompraItem{ItemID: item.ItemID, Quantidade: item.Quantidade, Preco: item.Preco})
+		}
+	}
+	if len(itens) == 0 {
+		return nil, falha.Validacao("Não há itens para repetir",
+			falha.Campo{Nome: "origem", Mensagem: "pedido sem itens"})
+	}
+
+	return s.registro.Criar(ctx, clienteID, data, itens)
+}
+
+func (s *PedidoService) salvar(ctx context.Context, pedido *model.PedidoRecorrente) (*model.PedidoRecorrente, error) {
+	pedido.AtualizadoEm = time.Now()
+	if err := s.pedidos.Salvar(ctx, pedido); err != nil {
+		return nil, err
+	}
+	return s.pedidos.BuscarPorCliente(ctx, pedido.ClienteID)
+}
+
+// inferir monta o pedido com os itens presentes em pelo menos metade das
+// últimas compras, cada um com a quantidade e o valor medianos das compras
+// em que aparece.
+func (s *PedidoService) inferir(ctx context.Context, clienteID string) (*model.PedidoRecorrente, error) {
+	compras, err := s.compras.ListarPorCliente(ctx, clienteID)
+	if err != nil {
+		return nil, err
+	}
+	if len(compras) > comprasInferencia {
+		compras = compras[:comprasInferencia]
+	}
+
+	type amostras struct {
+		item        model.Item
+		quantidades []float64
+		precos      []float64
+	}
+	porItem := make(map[string]*amostras)
+	var ordem []string
+	for _, compra := range compras {
+		// Linhas do mesmo item na compra são somadas
+		naCompra := make(map[string]*model.CompraItem)
+		for i := range compra.Itens {
+			linha := &compra.Itens[i]
+			if soma, ok := naCompra[linha.ItemID]; ok {
+				soma.Quantidade += linha.Quantidade
+				soma.Preco += linha.Preco
+				continue
+			}
+			copia := *linha
+			naCompra[linha.ItemID] = &copia
+		}
+		for id, linha := range naCompra {
+			a, ok := porItem[id]
+			if !ok {
+				a = &amostras{item: linha.Item}
+				porItem[id] = a
+				ordem = append(ordem, id)
+			}
+			a.quantidades = append(a.quantidades, linha.Quantidade)
+			a.precos = append(a.precos, linha.Preco)
+		}
+	}
+
+	pedido := &model.PedidoRecorrente{ClienteID: clienteID, Inferido: true, AtualizadoEm: time.Now()}
+	for _, id := range ordem {
+		a := porItem[id]
+		if 2*len(a.quantidades) < len(compras) {
+			continue
+		}
+		pedido.Itens = append(pedido.Itens, model.ItemPedidoRecorrente{
+			ClienteID:  clienteID,
+			ItemID:     id,
+			Item:       a.item,
+			Quantidade: mediana(a.quantidades),
+			Preco:      mediana(a.precos),
+		})
+	}
+	slices.SortFunc(pedido.Itens, func(a, b model.ItemPedidoRecorrente) int { return strings.Compare(a.Item.Nome, b.Item.Nome) })
+	return pedido, nil
+}
+
+// intervaloMediano devolve, em dias, o intervalo mediano entre as últimas
+// compras, que vêm da mais recente para a mais antiga. Compras no mesmo dia
+// contam como uma só.
+func intervaloMediano(compras []model.Compra) (int, bool) {
+	var intervalos []float64
+	for i := 1; i < len(compras) && len(intervalos) < comprasInferencia; i++ {
+		dias := compras[i-1].DataCompra.Sub(compras[i].DataCompra).Hours() / 24
+		if dias >= 1 {
+			intervalos = append(intervalos, dias)
+		}
+	}
+	if len(intervalos) == 0 {
+		return 0, false
+	}
+	return int(mediana(intervalos) + 0.5), true
+}
+
+func mediana(valores []float64) float64 {
+	ordenados := slices.Clone(valores)
+	slices.Sort(ordenados)
+	n := len(ordenados)
+	if n%2 == 1 {
+		return ordenados[n/2]
+	}
+	return (ordenados[n/2-1] + ordenados[n/2]) / 2
+}
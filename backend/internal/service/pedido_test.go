@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+)
+
+type pedidosMemoria struct {
+	repository.PedidoRecorrenteRepository
+	pedidos map[string]model.PedidoRecorrente
+}
+
+func (r *pedidosMemoria) BuscarPorCliente(_ context.Context, clienteID string) (*model.PedidoRecorrente, error) {
+	pedido, ok := r.pedidos[clienteID]
+	if !ok {
+		return nil, nil
+	}
+	return &pedido, nil
+}
+
+func (r *pedidosMemoria) Salvar(_ context.Context, pedido *model.PedidoRecorrente) error {
+	r.pedidos[pedido.ClienteID] = *pedido
+	return nil
+}
+
+// geradorSemJanela é um gerador para clientes sem dias de compra.
+type geradorSemJanela struct{ GeradorAlertas }
+
+func (geradorSemJanela) ProximaJanela(context.Context, string) (*alerta.Janela, error) {
+	return nil, nil
+}
+
+func (geradorSemJanela) Location() *time.Location {
+	return time.UTC
+}
+
+// cenarioPedidos monta o PedidoService do cliente c1 com as compras, da
+// mais recente para a mais antiga como as devolve o repositório.
+type cenarioPedidos struct {
+	servico *PedidoService
+	compras *comprasMemoria
+	pedidos *pedidosMemoria
+}
+
+func novoCenarioPedidos(compras ...model.Compra) cenarioPedidos {
+	clientes := novosClientesMemoria(model.Cliente{ID: "c1", Nome: "Mercado"})
+	repoCompras := &comprasMemoria{compras: compras, clientes: clientes}
+	pedidos := &pedidosMemoria{pedidos: map[string]model.PedidoRecorrente{}}
+	registro := NewCompraService(repoCompras, novoHub())
+	return cenarioPedidos{
+		servico: NewPedidoService(clientes, repoCompras, pedidos, registro, geradorSemJanela{}),
+		compras: repoCompras,
+		pedidos: pedidos,
+	}
+}
+
+// linha é um item de compra; o ID do item é o nome.
+func linha(nome string, quantidade, preco float64) model.CompraItem {
+	return model.CompraItem{ItemID: nome, Item: model.Item{ID: nome, Nome: nome}, Quantidade: quantidade, Preco: preco}
+}
+
+func compraEm(data string, itens ...model.CompraItem) model.Compra {
+	d, err := time.Parse(time.DateOnly, data)
+	if err != nil {
+		panic(err)
+	}
+	return model.Compra{ClienteID: "c1", DataCompra: d, Itens: itens}
+}
+
+// item resume um item do pedido: o nome, a quantidade e o valor.
+type item struct {
+	nome              string
+	quantidade, preco float64
+}
+
+func itensPedido(pedido *model.PedidoRecorrente) []item {
+	var itens []item
+	for _, i := range pedido.Itens {
+		itens = append(itens, item{i.ItemID, i.Quantidade, i.Preco})
+	}
+	return itens
+}
+
+func TestInferirPedidoRecorrente(t *testing.T) {
+	// 10 compras semanais: o sal está nas duas mais antigas e em 3 das 8
+	// mais recentes, as únicas consideradas
+	var dezCompras []model.Compra
+	for i := range 10 {
+		c := compraEm(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -7*i).Format(time.DateOnly), linha("Arroz", 1, 10))
+		if i >= 8 || i < 3 {
+			c.Itens = append(c.Itens, linha("Sal", 1, 2))
+		}
+		dezCompras = append(dezCompras, c)
+	}
+
+	casos := []struct {
+		nome     string
+		compras  []model.Compra
+		esperado []item
+	}{
+		{
+			// Feijão em exatamente metade das compras; café em menos
+			nome: "itens em pelo menos metade das compras, com as medianas",
+			compras: []model.Compra{
+				compraEm("2026-10-19", linha("Arroz", 2, 20), linha("Feijão", 1, 8)),
+				compraEm("2026-10-12", linha("Arroz", 5, 50), linha("Café", 1, 15)),
+				compraEm("2026-10-05", linha("Arroz", 3, 30), linha("Feijão", 3, 24)),
+				compraEm("2026-09-28", linha("Arroz", 2, 20)),
+			},
+			esperado: []item{{"Arroz", 2.5, 25}, {"Feijão", 2, 16}},
+		},
+		{
+			nome: "mediana de uma quantidade ímpar de compras",
+			compras: []model.Compra{
+				compraEm("2026-10-19", linha("Arroz", 1, 10)),
+				compraEm("2026-10-12", linha("Arroz", 4, 44)),
+				compraEm("2026-10-05", linha("Arroz", 2, 21)),
+			},
+			esperado: []item{{"Arroz", 2, 21}},
+		},
+		{
+			// Na primeira compra, duas linhas de arroz: 1+2 unidades, 10+20
+			nome: "soma as linhas do mesmo item na compra",
+			compras: []model.Compra{
+				compraEm("2026-10-19", linha("Arroz", 1, 10), linha("Arroz", 2, 20)),
+				compraEm("2026-10-12", linha("Arroz", 3, 30)),
+			},
+			esperado: []item{{"Arroz", 3, 30}},
+		},
+		{
+			nome:     "só as últimas comprasInferencia compras",
+			compras:  dezCompras,
+			esperado: []item{{"Arroz", 1, 10}},
+		},
+		{
+			nome:    "cliente sem compras",
+			compras: nil,
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			pedido, err := novoCenarioPedidos(c.compras...).servico.PedidoRecorrente(context.Background(), "c1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pedido.Inferido || pedido.ClienteID != "c1" {
+				t.Errorf("pedido = %+v, esperado inferido do c1", pedido)
+			}
+			if obtido := itensPedido(pedido); !reflect.DeepEqual(obtido, c.esperado) {
+				t.Errorf("itens = %v, esperado %v", obtido, c.esperado)
+			}
+		})
+	}
+}
+
+// O pedido salvo tem precedência sobre o inferido.
+func TestPedidoRecorrenteSalvo(t *testing.T) {
+	c := novoCenarioPedidos(compraEm("2026-10-19", linha("Arroz", 1, 10)))
+	c.pedidos.pedidos["c1"] = model.PedidoRecorrente{ClienteID: "c1", Itens: []model.ItemPedidoRecorrente{{ItemID: "Feijão", Quantidade: 2, Preco: 16}}}
+
+	pedido, err := c.servico.PedidoRecorrente(context.Background(), "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pedido.Inferido || !reflect.DeepEqual(itensPedido(pedido), []item{{"Feijão", 2, 16}}) {
+		t.Errorf("pedido = %+v, esperado o salvo", pedido)
+	}
+}
+
+func TestIntervaloMediano(t *testing.T) {
+	casos := []struct {
+		nome      string
+		datas     []string
+		intervalo int
+		ok        bool
+	}{
+		{nome: "sem compras"},
+		{nome: "uma compra", datas: []string{"2026-10-19"}},
+		{nome: "semanal", datas: []string{"2026-10-19", "2026-10-12", "2026-10-05"}, intervalo: 7, ok: true},
+		{nome: "compras no mesmo dia contam como uma", datas: []string{"2026-10-19", "2026-10-19", "2026-10-12", "2026-10-12", "2026-10-05"}, intervalo: 7, ok: true},
+		{nome: "todas no mesmo dia", datas: []string{"2026-10-19", "2026-10-19"}},
+		{nome: "mediana arredondada", datas: []string{"2026-10-19", "2026-10-12", "2026-10-02"}, intervalo: 9, ok: true},
+		{nome: "mediana resiste a um intervalo fora do padrão", datas: []string{"2026-10-19", "2026-10-12", "2026-10-05", "2026-08-01"}, intervalo: 7, ok: true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			var compras []model.Compra
+			for _, d := range c.datas {
+				compras = append(compras, compraEm(d))
+			}
+			intervalo, ok := intervaloMediano(compras)
+			if intervalo != c.intervalo || ok != c.ok {
+				t.Errorf("intervalo = %d, %v; esperado %d, %v", intervalo, ok, c.intervalo, c.ok)
+			}
+		})
+	}
+}
+
+func TestRepetir(t *testing.T) {
+	ultima := compraEm("2026-10-12", linha("Arroz", 2, 20), linha("Café", 1, 15))
+	anterior := compraEm("2026-10-05", linha("Arroz", 2, 20), linha("Feijão", 1, 8))
+	salvo := model.PedidoRecorrente{ClienteID: "c1", Itens: []model.ItemPedidoRecorrente{{ItemID: "Feijão", Quantidade: 3, Preco: 24}}}
+	vazio := model.PedidoRecorrente{ClienteID: "c1"}
+
+	casos := []struct {
+		nome      string
+		compras   []model.Compra
+		salvo     *model.PedidoRecorrente
+		origem    string
+		esperado  []item
+		erroCampo string
+	}{
+		{
+			nome:     "sem origem usa o pedido salvo",
+			compras:  []model.Compra{ultima, anterior},
+			salvo:    &salvo,
+			esperado: []item{{"Feijão", 3, 24}},
+		},
+		{
+			nome:     "sem origem nem pedido salvo usa a última compra",
+			compras:  []model.Compra{ultima, anterior},
+			esperado: []item{{"Arroz", 2, 20}, {"Café", 1, 15}},
+		},
+		{
+			nome:     "última compra mesmo com pedido salvo",
+			compras:  []model.Compra{ultima, anterior},
+			salvo:    &salvo,
+			origem:   OrigemUltimaCompra,
+			esperado: []item{{"Arroz", 2, 20}, {"Café", 1, 15}},
+		},
+		{
+			// Arroz nas duas compras; café e feijão em metade
+			nome:     "pedido recorrente sem pedido salvo usa o inferido",
+			compras:  []model.Compra{ultima, anterior},
+			origem:   OrigemPedidoRecorrente,
+			esperado: []item{{"Arroz", 2, 20}, {"Café", 1, 15}, {"Feijão", 1, 8}},
+		},
+		{
+			nome:      "pedido salvo sem itens",
+			compras:   []model.Compra{ultima},
+			salvo:     &vazio,
+			erroCampo: "origem",
+		},
+		{
+			nome:      "pedido inferido sem compras",
+			origem:    OrigemPedidoRecorrente,
+			erroCampo: "origem",
+		},
+		{
+			nome:      "última compra sem compras",
+			erroCampo: "origem",
+		},
+		{
+			nome:      "origem desconhecida",
+			compras:   []model.Compra{ultima},
+			origem:    "outra",
+			erroCampo: "origem",
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cenario := novoCenarioPedidos(c.compras...)
+			if c.salvo != nil {
+				cenario.pedidos.pedidos["c1"] = *c.salvo
+			}
+			data := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+			compra, err := cenario.servico.Repetir(context.Background(), "c1", c.origem, data)
+			if c.erroCampo != "" {
+				e := falha.De(err)
+				if e.Codigo != falha.CodigoValidacao || len(e.Campos) != 1 || e.Campos[0].Nome != c.erroCampo {
+					t.Fatalf("erro = %+v, esperada validação de %s", e, c.erroCampo)
+				}
+				if len(cenario.compras.compras) != len(c.compras) {
+					t.Errorf("compra registrada apesar do erro")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !compra.DataCompra.Equal(data) || compra.ClienteID != "c1" {
+				t.Errorf("compra = %+v", compra)
+			}
+			var obtido []item
+			for _, i := range compra.Itens {
+				obtido = append(obtido, item{i.ItemID, i.Quantidade, i.Preco})
+			}
+			if !reflect.DeepEqual(obtido, c.esperado) {
+				t.Errorf("itens = %v, esperado %v", obtido, c.esperado)
+			}
+			if len(cenario.compras.compras) != len(c.compras)+1 {
+				t.Errorf("%d compras, esperado a repetida registrada", len(cenario.compras.compras))
+			}
+		})
+	}
+}
+
+// Sem data, a compra repetida fica no dia corrente do fuso dos alertas.
+func TestRepetirSemDataUsaHoje(t *testing.T) {
+	c := novoCenarioPedidos(compraEm("2026-10-12", linha("Arroz", 1, 10)))
+
+	compra, err := c.servico.Repetir(context.Background(), "c1", "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hoje := alerta.Dia(time.Now()); !compra.DataCompra.Equal(hoje) {
+		t.Errorf("compra em %v, esperado %v", compra.DataCompra, hoje)
+	}
+}
+
+func TestRepetirClienteInexistente(t *testing.T) {
+	c := novoCenarioPedidos()
+	if _, err := c.servico.Repetir(context.Background(), "c2", "", time.Time{}); !errors.Is(err, repository.ErrClienteNaoEncontrado) {
+		t.Errorf("erro = %v, esperado cliente não encontrado", err)
+	}
+}
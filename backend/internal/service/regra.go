@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+)
+
+type (
+	RegraService struct {
+		regras  repository.RegraRepository
+		gerador GeradorAlertas
+	}
+
+	// DadosRegra são os campos editáveis de uma regra. Ativa nula mantém o
+	// valor atual, ou ativa a regra na criação.
+	DadosRegra struct {
+		Nome      string
+		Expressao string
+		Motivo    string
+		Ativa     *bool
+	}
+)
+
+func NewRegraService(regras repository.RegraRepository, gerador GeradorAlertas) *RegraService {
+	return &RegraService{regras: regras, gerador: gerador}
+}
+
+func (s *RegraService) Listar(ctx context.Context) ([]model.RegraAlerta, error) {
+	return s.regras.Listar(ctx)
+}
+
+func (s *RegraService) BuscarPorID(ctx context.Context, id string) (*model.RegraAlerta, error) {
+	return s.regras.BuscarPorID(ctx, id)
+}
+
+func (s *RegraService) Criar(ctx context.Context, dados DadosRegra) (*model.RegraAlerta, error) {
+	if _, err := compilar(dados.Expressao); err != nil {
+		return nil, err
+	}
+
+	regra := model.RegraAlerta{
+		Nome:      dados.Nome,
+		Expressao: dados.Expressao,
+		Motivo:    dados.Motivo,
+		Ativa:     dados.Ativa == nil || *dados.Ativa,
+	}
+	if err := s.regras.Criar(ctx, &regra); err != nil {
+		return nil, err
+	}
+	return &regra, nil
+}
+
+func (s *RegraService) Atualizar(ctx context.Context, id string, dados DadosRegra) (*model.RegraAlerta, error) {
+	if _, err := compilar(dados.Expressao); err != nil {
+		return nil, err
+	}
+
+	regra, err := s.regras.BuscarPorID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	regra.Nome = dados.Nome
+	regra.Expressao = dados.Expressao
+	regra.Motivo = dados.Motivo
+	if dados.Ativa != nil {
+		regra.Ativa = *dados.Ativa
+	}
+
+	if err := s.regras.Atualizar(ctx, regra); err != nil {
+		return nil, err
+	}
+	return regra, nil
+}
+
+func (s *RegraService) Deletar(ctx context.Context, id string) error {
+	return s.regras.Deletar(ctx, id)
+}
+
+// Simular devolve os clientes que hoje seriam alertados pela expressão, sem
+// salvar a regra.
+func (s *RegraService) Simular(ctx context.Context, expressao string) ([]*alerta.Fatos, error) {
+	expr, err := compilar(expressao)
+	if err != nil {
+		return nil, err
+	}
+	return s.gerador.Simular(ctx, expr)
+}
+
+func compilar(expressao string) (*alerta.Expressao, error) {
+	expr, err := alerta.CompilarExpressao(expressao)
+	if err != nil {
+		return nil, falha.Validacao("Expressão inválida: "+err.Error(),
+			falha.Campo{Nome: "expressao", Mensagem: err.Error()})
+	}
+	return expr, nil
+}
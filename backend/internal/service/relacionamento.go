@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+)
+
+// RelacionamentoService cuida dos contatos do cliente e das interações
+// registradas com ele. Todas as operações são feitas dentro de um cliente,
+// que precisa existir.
+type RelacionamentoService struct {
+	clientes   repository.ClienteRepository
+	contatos   repository.ContatoRepository
+	interacoes repository.InteracaoRepository
+}
+
+func NewRelacionamentoService(clientes repository.ClienteRepository, contatos repository.ContatoRepository, interacoes repository.InteracaoRepository) *RelacionamentoService {
+	return &RelacionamentoService{clientes: clientes, contatos: contatos, interacoes: interacoes}
+}
+
+func (s *RelacionamentoService) ListarContatos(ctx context.Context, clienteID string) ([]model.Contato, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	return s.contatos.ListarPorCliente(ctx, clienteID)
+}
+
+func (s *RelacionamentoService) CriarContato(ctx context.Context, clienteID string, contato model.Contato) (*model.Contato, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+
+	contato.ID = ""
+	contato.ClienteID = clienteID
+	if err := s.contatos.Criar(ctx, &contato); err != nil {
+		return nil, err
+	}
+	return &contato, nil
+}
+
+func (s *RelacionamentoService) AtualizarContato(ctx context.Context, clienteID, id string, dados model.Contato) (*model.Contato, error) {
+	contato, err := s.contatos.BuscarPorID(ctx, clienteID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contato.Nome = dados.Nome
+	contato.Cargo = dados.Cargo
+	contato.Telefone = dados.Telefone
+	contato.Email = dados.Email
+	contato.Principal = dados.Principal
+
+	if err := s.contatos.Atualizar(ctx, contato); err != nil {
+		return nil, err
+	}
+	return contato, nil
+}
+
+func (s *RelacionamentoService) DeletarContato(ctx context.Context, clienteID, id string) error {
+	return s.contatos.Deletar(ctx, clienteID, id)
+}
+
+func (s *RelacionamentoService) ListarInteracoes(ctx context.Context, clienteID string) ([]model.Interacao, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	return s.interacoes.ListarPorCliente(ctx, clienteID)
+}
+
+// RegistrarInteracao grava a interação no cliente. Sem data, a interação
+// é registrada como acontecida agora.
+func (s *RelacionamentoService) RegistrarInteracao(ctx context.Context, clienteID string, interacao model.Interacao) (*model.Interacao, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+	contato, err := s.contatoDaInteracao(ctx, clienteID, interacao.ContatoID)
+	if err != nil {
+		return nil, err
+	}
+
+	agora := time.Now()
+	interacao.ID = ""
+	interacao.ClienteID = clienteID
+	interacao.Contato = nil
+	interacao.CriadaEm = agora
+	if interacao.Data.IsZero() {
+		interacao.Data = agora
+	}
+
+	if err := s.interacoes.Criar(ctx, &interacao); err != nil {
+		return nil, err
+	}
+	interacao.Contato = contato
+	return &interacao, nil
+}
+
+func (s *RelacionamentoService) AtualizarInteracao(ctx context.Context, clienteID, id string, dados model.Interacao) (*model.Interacao, error) {
+	interacao, err := s.interacoes.BuscarPorID(ctx, clienteID, id)
+	if err != nil {
+		return nil, err
+	}
+	contato, err := s.contatoDaInteracao(ctx, clienteID, dados.ContatoID)
+	if err != nil {
+		return nil, err
+	}
+
+	interacao.ContatoID = dados.ContatoID
+	interacao.Contato = nil
+	interacao.Tipo = dados.Tipo
+	interacao.Descricao = dados.Descricao
+	interacao.Resultado = dados.Resultado
+	interacao.Autor = dados.Autor
+	if !dados.Data.IsZero() {
+		interacao.Data = dados.Data
+	}
+	interacao.RetornoEm = dados.RetornoEm
+	interacao.PausarAlertasAte = dados.PausarAlertasAte
+
+	if err := s.interacoes.Atualizar(ctx, interacao); err != nil {
+		return nil, err
+	}
+	interacao.Contato = contato
+	return interacao, nil
+}
+
+func (s *RelacionamentoService) DeletarInteracao(ctx context.Context, clienteID, id string) error {
+	return s.interacoes.Deletar(ctx, clienteID, id)
+}
+
+// contatoDaInteracao confere que o contato informado é do próprio cliente.
+func (s *RelacionamentoService) contatoDaInteracao(ctx context.Context, clienteID string, contatoID *string) (*model.Contato, error) {
+	if contatoID == nil {
+		return nil, nil
+	}
+	contato, err := s.contatos.BuscarPorID(ctx, clienteID, *contatoID)
+	if falha.CodigoDe(err) == falha.CodigoNaoEncontrado {
+		return nil, falha.Novo(falha.CodigoReferenciaInvalida, "Contato não encontrado",
+			falha.Campo{Nome: "contato_id", Mensagem: "Contato não encontrado neste cliente"})
+	}
+	return contato, err
+}
@@ -0,0 +1,54 @@
+// Package service concentra as regras de negócio. Os serviços dependem só
+// das interfaces de repository, do gerador de alertas e do publicador do hub,
+// e os handlers apenas traduzem HTTP para chamadas a eles. Os erros que o
+// usuário deve ver são *falha.Erro.
+package service
+
+import (
+	"context"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+type (
+	// GeradorAlertas é a parte do alerta.Engine usada pelos serviços.
+	GeradorAlertas interface {
+		Gerar(ctx context.Context, tipos ...string) ([]alerta.Alerta, error)
+		Simular(ctx context.Context, expr *alerta.Expressao) ([]*alerta.Fatos, error)
+		ProximaJanela(ctx context.Context, clienteID string) (*alerta.Janela, error)
+		Location() *time.Location
+	}
+
+	Servicos struct {
+		Clientes       *ClienteService
+		Compras        *CompraService
+		Relacionamento *RelacionamentoService
+		Tarefas        *TarefaService
+		Calendario     *CalendarioService
+		Pedidos        *PedidoService
+		Sugestoes      *SugestaoService
+		Alertas        *AlertaService
+		Regras         *RegraService
+		Dashboard      *DashboardService
+	}
+)
+
+func New(repos repository.Repositorios, hub ws.Publicador, gerador GeradorAlertas, sugestoes ModeloSugestoes) Servicos {
+	tarefas := NewTarefaService(repos.Tarefas, repos.RegrasTarefa, hub, gerador.Location())
+	compras := NewCompraService(repos.Compras, hub)
+	return Servicos{
+		Clientes:       NewClienteService(repos.Clientes, repos.Compras, repos.Contatos, repos.Interacoes, hub, gerador.Location()),
+		Compras:        compras,
+		Pedidos:        NewPedidoService(repos.Clientes, repos.Compras, repos.Pedidos, compras, gerador),
+		Sugestoes:      NewSugestaoService(repos.Clientes, sugestoes),
+		Relacionamento: NewRelacionamentoService(repos.Clientes, repos.Contatos, repos.Interacoes),
+		Alertas:        NewAlertaService(gerador, repos.Alertas, tarefas, hub),
+		Tarefas:        tarefas,
+		Calendario:     NewCalendarioService(repos.Clientes, repos.Feriados, repos.Fechamentos, gerador.Location()),
+		Regras:         NewRegraService(repos.Regras, gerador),
+		Dashboard:      NewDashboardService(repos.Dashboard),
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"smart-retention/internal/repository"
+	"smart-retention/internal/sugestao"
+)
+
+type (
+	// ModeloSugestoes é o modelo de coocorrência de itens; ver
+	// sugestao.Modelo.
+	ModeloSugestoes interface {
+		Sugerir(clienteID string, limite int) []sugestao.Sugestao
+		AtualizadoEm() time.Time
+	}
+
+	SugestaoService struct {
+		clientes repository.ClienteRepository
+		modelo   ModeloSugestoes
+	}
+
+	// Sugestoes traz os itens sugeridos ao cliente e quando o modelo foi
+	// calculado; AtualizadoEm é nil enquanto o modelo não foi calculado.
+	Sugestoes struct {
+		Itens        []sugestao.Sugestao
+		AtualizadoEm *time.Time
+	}
+)
+
+func NewSugestaoService(clientes repository.ClienteRepository, modelo ModeloSugestoes) *SugestaoService {
+	return &SugestaoService{clientes: clientes, modelo: modelo}
+}
+
+// Sugerir devolve até limite itens que o cliente não compra, mas que
+// clientes com compras parecidas compram.
+func (s *SugestaoService) Sugerir(ctx context.Context, clienteID string, limite int) (*Sugestoes, error) {
+	if _, err := s.clientes.BuscarPorID(ctx, clienteID); err != nil {
+		return nil, err
+	}
+
+	sugestoes := &Sugestoes{Itens: s.modelo.Sugerir(clienteID, limite)}
+	if atualizado := s.modelo.AtualizadoEm(); !atualizado.IsZero() {
+		sugestoes.AtualizadoEm = &atualizado
+	}
+	return sugestoes, nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/falha"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+// titulosTarefa são os títulos das tarefas criadas para cada tipo de alerta.
+var titulosTarefa = map[string]string{
+	alerta.TipoDiaPrevisto:      "Falar com o cliente que não comprou no dia previsto",
+	alerta.TipoCompraIncompleta: "Oferecer os itens que faltaram na compra de hoje",
+	alerta.TipoInatividade:      "Reativar cliente inativo",
+	alerta.TipoItemFaltando:     "Oferecer os itens que o cliente deixou de comprar",
+	alerta.TipoPersonalizada:    "Acompanhar alerta",
+}
+
+var situacoesTarefa = []string{model.TarefaAberta, model.TarefaEmAndamento, model.TarefaConcluida, model.TarefaCancelada}
+
+type (
+	TarefaService struct {
+		tarefas repository.TarefaRepository
+		regras  repository.RegraTarefaRepository
+		hub     ws.Publicador
+		local   *time.Location
+	}
+
+	// DadosTarefa são os campos de criação e de atualização de uma tarefa.
+	// ClienteID, TipoAlerta e RegraID só valem na criação: com TipoAlerta,
+	// a tarefa é ligada ao alerta em aberto do cliente e, sem título, recebe
+	// o título padrão do tipo. Status vazio é "aberta".
+	DadosTarefa struct {
+		ClienteID   string
+		TipoAlerta  string
+		RegraID     string
+		Titulo      string
+		Descricao   string
+		Responsavel string
+		Vencimento  time.Time
+		Status      string
+	}
+)
+
+func NewTarefaService(tarefas repository.TarefaRepository, regras repository.RegraTarefaRepository, hub ws.Publicador, local *time.Location) *TarefaService {
+	return &TarefaService{tarefas: tarefas, regras: regras, hub: hub, local: local}
+}
+
+func (s *TarefaService) Listar(ctx context.Context, filtro repository.FiltroTarefas) ([]model.Tarefa, error) {
+	for _, status := range filtro.Status {
+		if !slices.Contains(situacoesTarefa, status) {
+			return nil, falha.RequisicaoInvalida("Parâmetro status inválido",
+				falha.Campo{Nome: "status", Mensagem: "deve ser um de: " + strings.Join(situacoesTarefa, " ")})
+		}
+	}
+	return s.tarefas.Listar(ctx, filtro)
+}
+
+func (s *TarefaService) BuscarPorID(ctx context.Context, id string) (*model.Tarefa, error) {
+	return s.tarefas.BuscarPorID(ctx, id)
+}
+
+func (s *TarefaService) Criar(ctx context.Context, dados DadosTarefa) (*model.Tarefa, error) {
+	if dados.Status == "" {
+		dados.Status = model.TarefaAberta
+	}
+	if err := validarStatus(dados.Status); err != nil {
+		return nil, err
+	}
+
+	tarefa := model.Tarefa{
+		ClienteID:   dados.ClienteID,
+		TipoAlerta:  dados.TipoAlerta,
+		Titulo:      dados.Titulo,
+		Descricao:   dados.Descricao,
+		Responsavel: dados.Responsavel,
+		Vencimento:  dados.Vencimento,
+		CriadaEm:    time.Now(),
+	}
+	mudarStatus(&tarefa, dados.Status)
+
+	if dados.TipoAlerta != "" {
+		if err := validarTipoAlerta(dados.TipoAlerta); err != nil {
+			return nil, err
+		}
+		if tarefa.Titulo == "" {
+			tarefa.Titulo = titulosTarefa[dados.TipoAlerta]
+		}
+		historico, err := s.tarefas.AlertaEmAberto(ctx, dados.ClienteID, dados.TipoAlerta, dados.RegraID)
+		if err != nil {
+			return nil, err
+		}
+		if historico != nil {
+			tarefa.AlertaHistoricoID = &historico.ID
+			if tarefa.Descricao == "" {
+				tarefa.Descricao = historico.Motivo
+			}
+		}
+	}
+	if tarefa.Titulo == "" {
+		return nil, falha.Validacao("Informe o título ou o tipo do alerta",
+			falha.Campo{Nome: "titulo", Mensagem: "obrigatório sem tipo_alerta"})
+	}
+
+	if err := s.tarefas.Criar(ctx, &tarefa); err != nil {
+		return nil, err
+	}
+	return s.tarefas.BuscarPorID(ctx, tarefa.ID)
+}
+
+func (s *TarefaService) Atualizar(ctx context.Context, id string, dados DadosTarefa) (*model.Tarefa, error) {
+	if err := validarStatus(dados.Status); err != nil {
+		return nil, err
+	}
+
+	tarefa, err := s.tarefas.BuscarPorID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tarefa.Titulo = dados.Titulo
+	tarefa.Descricao = dados.Descricao
+	tarefa.Responsavel = dados.Responsavel
+	tarefa.Vencimento = dados.Vencimento
+	mudarStatus(tarefa, dados.Status)
+
+	if err := s.tarefas.Atualizar(ctx, tarefa); err != nil {
+		return nil, err
+	}
+	return tarefa, nil
+}
+
+// mudarStatus registra quando a tarefa foi concluída; reabri-la apaga a
+// data de conclusão.
+func mudarStatus(tarefa *model.Tarefa, status string) {
+	switch {
+	case status == model.TarefaConcluida && tarefa.Status != model.TarefaConcluida:
+		agora := time.Now()
+		tarefa.ConcluidaEm = &agora
+	case status != model.TarefaConcluida:
+		tarefa.ConcluidaEm = nil
+	}
+	tarefa.Status = status
+}
+
+// CriarDeAlertas aplica as regras de tarefa ativas: cada alerta ativo de um
+// tipo com regra, que ainda não tenha tarefa, ganha uma. Usado pela
+// publicação periódica dos alertas, logo após o registro no histórico.
+func (s *TarefaService) CriarDeAlertas(ctx context.Context, alertas []alerta.Alerta) error {
+	regras, err := s.regras.Listar(ctx)
+	if err != nil {
+		return err
+	}
+	porTipo := make(map[string]model.RegraTarefa, len(regras))
+	var tipos []string
+	for _, r := range regras {
+		if r.Ativa {
+			porTipo[r.TipoAlerta] = r
+			tipos = append(tipos, r.TipoAlerta)
+		}
+	}
+	if len(tipos) == 0 || len(alertas) == 0 {
+		return nil
+	}
+
+	ativos := make(map[string]bool, len(alertas))
+	for _, a := range alertas {
+		ativos[a.Chave()] = true
+	}
+
+	historico, err := s.tarefas.AlertasSemTarefa(ctx, tipos)
+	if err != nil {
+		return err
+	}
+
+	hoje := alerta.Dia(time.Now().In(s.local))
+	for _, h := range historico {
+		if !ativos[alerta.Alerta{ClienteID: h.ClienteID, Tipo: h.Tipo, RegraID: h.RegraID}.Chave()] {
+			continue
+		}
+
+		regra := porTipo[h.Tipo]
+		responsavel := regra.Responsavel
+		if responsavel == "" {
+			responsavel = h.Representante
+		}
+		tarefa := model.Tarefa{
+			ClienteID:         h.ClienteID,
+			AlertaHistoricoID: &h.ID,
+			TipoAlerta:        h.Tipo,
+			Titulo:            titulosTarefa[h.Tipo],
+			Descricao:         h.Motivo,
+			Responsavel:       responsavel,
+			Status:            model.TarefaAberta,
+			Vencimento:        hoje.AddDate(0, 0, regra.PrazoDias),
+			CriadaEm:          time.Now(),
+		}
+		if err := s.tarefas.Criar(ctx, &tarefa); err != nil {
+			return err
+		}
+		s.publicar(ws.EventoTarefaCriada, &tarefa)
+	}
+	return nil
+}
+
+// LembrarVencidas publica um lembrete, uma vez por dia, para cada tarefa
+// pendente com o vencimento já passado. Usado pelo cron.
+func (s *TarefaService) LembrarVencidas(ctx context.Context) error {
+	hoje := alerta.Dia(time.Now().In(s.local))
+	vencidas, err := s.tarefas.Vencidas(ctx, hoje)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(vencidas))
+	for i := range vencidas {
+		t := &vencidas[i]
+		slog.InfoContext(ctx, "tarefa vencida",
+			"tarefa_id", t.ID,
+			"cliente", t.Cliente.Nome,
+			"responsavel", t.Responsavel,
+			"vencimento", t.Vencimento.Format(time.DateOnly),
+		)
+		s.publicar(ws.EventoTarefaVencida, t)
+		ids = append(ids, t.ID)
+	}
+	return s.tarefas.MarcarLembradas(ctx, ids, hoje)
+}
+
+func (s *TarefaService) publicar(nome string, t *model.Tarefa) {
+	s.hub.Publicar(ws.Evento{
+		Nome: nome,
+		Dados: map[string]any{
+			"id":          t.ID,
+			"cliente_id":  t.ClienteID,
+			"titulo":      t.Titulo,
+			"responsavel": t.Responsavel,
+			"vencimento":  t.Vencimento,
+			"status":      t.Status,
+		},
+		ClienteID:     t.ClienteID,
+		TipoAlerta:    t.TipoAlerta,
+		Representante: t.Responsavel,
+	})
+}
+
+func (s *TarefaService) ListarRegras(ctx context.Context) ([]model.RegraTarefa, error) {
+	return s.regras.Listar(ctx)
+}
+
+// SalvarRegra cria ou substitui a regra de tarefa do tipo de alerta.
+func (s *TarefaService) SalvarRegra(ctx context.Context, regra model.RegraTarefa) (*model.RegraTarefa, error) {
+	if err := validarTipoAlerta(regra.TipoAlerta); err != nil {
+		return nil, err
+	}
+	if err := s.regras.Salvar(ctx, &regra); err != nil {
+		return nil, err
+	}
+	return &regra, nil
+}
+
+func (s *TarefaService) DeletarRegra(ctx context.Context, tipoAlerta string) error {
+	return s.regras.Deletar(ctx, tipoAlerta)
+}
+
+func validarStatus(status string) error {
+	if !slices.Contains(situacoesTarefa, status) {
+		return falha.Validacao("Situação de tarefa inválida",
+			falha.Campo{Nome: "status", Mensagem: "deve ser um de: " + strings.Join(situacoesTarefa, " ")})
+	}
+	return nil
+}
+
+func validarTipoAlerta(tipo string) error {
+	if _, ok := titulosTarefa[tipo]; !ok {
+		return falha.Validacao("Tipo de alerta inválido",
+			falha.Campo{Nome: "tipo_alerta", Mensagem: "tipo de alerta desconhecido"})
+	}
+	return nil
+}
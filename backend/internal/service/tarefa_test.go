@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/model"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/ws"
+)
+
+// tarefasAgenda seleciona as vencidas com a regra de tarefaGorm.Vencidas e
+// devolve os alertas sem tarefa fixos.
+type tarefasAgenda struct {
+	repository.TarefaRepository
+	tarefas   []model.Tarefa
+	semTarefa []model.AlertaHistorico
+	tipos     []string // pedidos a AlertasSemTarefa
+	criadas   []model.Tarefa
+	hoje      time.Time // pedido a Vencidas
+}
+
+func (r *tarefasAgenda) Vencidas(_ context.Context, hoje time.Time) ([]model.Tarefa, error) {
+	r.hoje = hoje
+	var vencidas []model.Tarefa
+	for _, t := range r.tarefas {
+		if t.Pendente() && t.Vencimento.Before(hoje) && (t.LembradaEm == nil || t.LembradaEm.Before(hoje)) {
+			vencidas = append(vencidas, t)
+		}
+	}
+	return vencidas, nil
+}
+
+func (r *tarefasAgenda) MarcarLembradas(_ context.Context, ids []string, dia time.Time) error {
+	for i := range r.tarefas {
+		if slices.Contains(ids, r.tarefas[i].ID) {
+			r.tarefas[i].LembradaEm = &dia
+		}
+	}
+	return nil
+}
+
+func (r *tarefasAgenda) AlertasSemTarefa(_ context.Context, tipos []string) ([]model.AlertaHistorico, error) {
+	r.tipos = tipos
+	return r.semTarefa, nil
+}
+
+func (r *tarefasAgenda) Criar(_ context.Context, tarefa *model.Tarefa) error {
+	tarefa.ID = "tarefa-" + tarefa.ClienteID
+	r.criadas = append(r.criadas, *tarefa)
+	return nil
+}
+
+func idsPublicados(hub *hubMemoria, nome string) []string {
+	var ids []string
+	for _, e := range hub.publicados {
+		if e.Nome == nome {
+			ids = append(ids, e.Dados.(map[string]any)["id"].(string))
+		}
+	}
+	return ids
+}
+
+func TestLembrarVencidasUmaVezPorDia(t *testing.T) {
+	local := time.FixedZone("BRT", -3*60*60)
+	hoje := alerta.Dia(time.Now().In(local))
+	ontem := hoje.AddDate(0, 0, -1)
+	tarefas := &tarefasAgenda{tarefas: []model.Tarefa{
+		{ID: "vencida", Status: model.TarefaAberta, Vencimento: ontem},
+		{ID: "lembrada-ontem", Status: model.TarefaEmAndamento, Vencimento: hoje.AddDate(0, 0, -3), LembradaEm: &ontem},
+		{ID: "vence-hoje", Status: model.TarefaAberta, Vencimento: hoje},
+		{ID: "concluida", Status: model.TarefaConcluida, Vencimento: ontem},
+		{ID: "cancelada", Status: model.TarefaCancelada, Vencimento: ontem},
+	}}
+	hub := novoHub()
+	s := NewTarefaService(tarefas, regrasTarefaFixas{}, hub, local)
+	ctx := context.Background()
+
+	if err := s.LembrarVencidas(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !tarefas.hoje.Equal(hoje) {
+		t.Errorf("vencidas antes de %v, esperado o dia corrente no fuso dos alertas, %v", tarefas.hoje, hoje)
+	}
+	if ids := idsPublicados(hub, ws.EventoTarefaVencida); !slices.Equal(ids, []string{"vencida", "lembrada-ontem"}) {
+		t.Errorf("lembretes = %v, esperado as duas pendentes vencidas", ids)
+	}
+	for _, tarefa := range tarefas.tarefas[:2] {
+		if tarefa.LembradaEm == nil || !tarefa.LembradaEm.Equal(hoje) {
+			t.Errorf("%s lembrada em %v, esperado %v", tarefa.ID, tarefa.LembradaEm, hoje)
+		}
+	}
+
+	// No mesmo dia, nada é lembrado de novo
+	if err := s.LembrarVencidas(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ids := idsPublicados(hub, ws.EventoTarefaVencida); len(ids) != 2 {
+		t.Errorf("lembretes = %v, esperado nenhum novo", ids)
+	}
+}
+
+func TestCriarDeAlertas(t *testing.T) {
+	local := time.FixedZone("BRT", -3*60*60)
+	hoje := alerta.Dia(time.Now().In(local))
+	regras := regrasTarefaFixas{regras: []model.RegraTarefa{
+		{TipoAlerta: alerta.TipoInatividade, Ativa: true, PrazoDias: 2},
+		{TipoAlerta: alerta.TipoItemFaltando, Ativa: true, PrazoDias: 5, Responsavel: "Carla"},
+		{TipoAlerta: alerta.TipoDiaPrevisto, Ativa: false, PrazoDias: 1},
+	}}
+	tarefas := &tarefasAgenda{semTarefa: []model.AlertaHistorico{
+		{ID: "h1", ClienteID: "c1", Tipo: alerta.TipoInatividade, Motivo: "Cliente não compra há mais de 30 dias.", Representante: "Bruno"},
+		{ID: "h2", ClienteID: "c2", Tipo: alerta.TipoItemFaltando, Motivo: "Cliente deixou de comprar itens recorrentes.", Representante: "Bruno"},
+		// Em aberto no histórico, mas não mais gerado
+		{ID: "h4", ClienteID: "c4", Tipo: alerta.TipoInatividade, Representante: "Bruno"},
+	}}
+	hub := novoHub()
+	s := NewTarefaService(tarefas, regras, hub, local)
+
+	err := s.CriarDeAlertas(context.Background(), []alerta.Alerta{
+		{ClienteID: "c1", Tipo: alerta.TipoInatividade},
+		{ClienteID: "c2", Tipo: alerta.TipoItemFaltando},
+		{ClienteID: "c3", Tipo: alerta.TipoDiaPrevisto},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(tarefas.tipos, []string{alerta.TipoInatividade, alerta.TipoItemFaltando}) {
+		t.Errorf("tipos buscados = %v, esperado só os das regras ativas", tarefas.tipos)
+	}
+	if len(tarefas.criadas) != 2 {
+		t.Fatalf("%d tarefas criadas, esperado 2: %+v", len(tarefas.criadas), tarefas.criadas)
+	}
+
+	inativo, faltando := tarefas.criadas[0], tarefas.criadas[1]
+	// Sem responsável na regra, a tarefa vai para o representante
+	if inativo.ClienteID != "c1" || inativo.Responsavel != "Bruno" || !inativo.Vencimento.Equal(hoje.AddDate(0, 0, 2)) {
+		t.Errorf("tarefa da inatividade = %+v", inativo)
+	}
+	if inativo.AlertaHistoricoID == nil || *inativo.AlertaHistoricoID != "h1" || inativo.Status != model.TarefaAberta ||
+		inativo.Titulo != titulosTarefa[alerta.TipoInatividade] || inativo.Descricao != "Cliente não compra há mais de 30 dias." {
+		t.Errorf("tarefa da inatividade = %+v", inativo)
+	}
+	if faltando.ClienteID != "c2" || faltando.Responsavel != "Carla" || !faltando.Vencimento.Equal(hoje.AddDate(0, 0, 5)) {
+		t.Errorf("tarefa do item faltando = %+v", faltando)
+	}
+
+	if ids := idsPublicados(hub, ws.EventoTarefaCriada); !slices.Equal(ids, []string{"tarefa-c1", "tarefa-c2"}) {
+		t.Errorf("eventos = %v", ids)
+	}
+}
+
+func TestCriarDeAlertasSemRegrasAtivas(t *testing.T) {
+	tarefas := &tarefasAgenda{}
+	regras := regrasTarefaFixas{regras: []model.RegraTarefa{{TipoAlerta: alerta.TipoInatividade, Ativa: false}}}
+	s := NewTarefaService(tarefas, regras, novoHub(), time.UTC)
+
+	if err := s.CriarDeAlertas(context.Background(), []alerta.Alerta{{ClienteID: "c1", Tipo: alerta.TipoInatividade}}); err != nil {
+		t.Fatal(err)
+	}
+	if tarefas.tipos != nil || len(tarefas.criadas) != 0 {
+		t.Errorf("buscou %v e criou %d tarefas, esperado nada", tarefas.tipos, len(tarefas.criadas))
+	}
+}
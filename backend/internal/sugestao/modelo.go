@@ -0,0 +1,196 @@
+// Package sugestao indica itens que o cliente não compra, mas que clientes
+// com compras parecidas compram. O modelo é uma tabela de coocorrência item a
+// item mantida em memória e recalculada periodicamente a partir das compras.
+package sugestao
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// janelaDias é o período de compras considerado no modelo.
+	janelaDias = 180
+	// minClientes é quantos clientes precisam comprar dois itens para que
+	// um sugira o outro; abaixo disso a coocorrência é acaso.
+	minClientes = 2
+)
+
+type (
+	// Sugestao é um item a oferecer ao cliente. Pontuacao só serve para
+	// ordenar; Motivo explica a sugestão pelo item do cliente que mais
+	// contribuiu para ela.
+	Sugestao struct {
+		ItemID    string  `json:"item_id"`
+		Nome      string  `json:"nome"`
+		Pontuacao float64 `json:"pontuacao"`
+		Motivo    string  `json:"motivo"`
+	}
+
+	// Modelo guarda a última tabela calculada. É seguro para uso
+	// concorrente; antes do primeiro Atualizar não sugere nada.
+	Modelo struct {
+		repo Repositorio
+
+		mu     sync.RWMutex
+		tabela *tabela
+	}
+
+	// tabela conta, por item e por par de itens, quantos clientes os
+	// compraram na janela.
+	tabela struct {
+		nomes    map[string]string
+		clientes map[string]int
+		juntos   map[string]map[string]int
+		// itens do cliente: comprados na janela ou cadastrados como recorrentes
+		doCliente    map[string]map[string]bool
+		atualizadoEm time.Time
+	}
+)
+
+func NewModelo(repo Repositorio) *Modelo {
+	return &Modelo{repo: repo}
+}
+
+// Atualizar recalcula a tabela com as compras dos últimos janelaDias dias e
+// a troca de uma vez pela anterior.
+func (m *Modelo) Atualizar(ctx context.Context) error {
+	compradas, err := m.repo.ItensComprados(ctx, time.Now().AddDate(0, 0, -janelaDias))
+	if err != nil {
+		return err
+	}
+	cadastradas, err := m.repo.ItensCadastrados(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := calcular(compradas, cadastradas)
+	t.atualizadoEm = time.Now()
+
+	m.mu.Lock()
+	m.tabela = t
+	m.mu.Unlock()
+
+	slog.InfoContext(ctx, "modelo de sugestões atualizado", "itens", len(t.clientes), "clientes", len(t.doCliente))
+	return nil
+}
+
+// AtualizadoEm devolve quando a tabela foi calculada, ou o instante zero se
+// ainda não foi.
+func (m *Modelo) AtualizadoEm() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.tabela == nil {
+		return time.Time{}
+	}
+	return m.tabela.atualizadoEm
+}
+
+// Sugerir devolve até limite itens que o cliente não compra, do mais para o
+// menos indicado. A pontuação de um item soma, para cada item do cliente, a
+// similaridade de cosseno entre os dois: clientes que compram ambos sobre a
+// raiz do produto dos que compram cada um.
+func (m *Modelo) Sugerir(clienteID string, limite int) []Sugestao {
+	m.mu.RLock()
+	t := m.tabela
+	m.mu.RUnlock()
+	if t == nil || limite <= 0 {
+		return nil
+	}
+
+	type candidato struct {
+		pontuacao float64
+		base      string // item do cliente que mais contribuiu
+		peso      float64
+	}
+	proprios := t.doCliente[clienteID]
+	candidatos := make(map[string]*candidato)
+	for item := range proprios {
+		for outro, n := range t.juntos[item] {
+			if proprios[outro] || n < minClientes {
+				continue
+			}
+			peso := float64(n) / math.Sqrt(float64(t.clientes[item]*t.clientes[outro]))
+			c, ok := candidatos[outro]
+			if !ok {
+				c = &candidato{}
+				candidatos[outro] = c
+			}
+			c.pontuacao += peso
+			if peso > c.peso || (peso == c.peso && t.nomes[item] < t.nomes[c.base]) {
+				c.base, c.peso = item, peso
+			}
+		}
+	}
+
+	sugestoes := make([]Sugestao, 0, len(candidatos))
+	for item, c := range candidatos {
+		sugestoes = append(sugestoes, Sugestao{
+			ItemID:    item,
+			Nome:      t.nomes[item],
+			Pontuacao: math.Round(c.pontuacao*1000) / 1000,
+			Motivo: fmt.Sprintf("Comprado por %d dos %d clientes que compram %s.",
+				t.juntos[c.base][item], t.clientes[c.base], t.nomes[c.base]),
+		})
+	}
+	slices.SortFunc(sugestoes, func(a, b Sugestao) int {
+		if a.Pontuacao != b.Pontuacao {
+			if a.Pontuacao > b.Pontuacao {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Nome, b.Nome)
+	})
+	if len(sugestoes) > limite {
+		sugestoes = sugestoes[:limite]
+	}
+	return sugestoes
+}
+
+// calcular monta a tabela: só as compras contam para a coocorrência, mas os
+// itens cadastrados também são do cliente e não são sugeridos a ele.
+func calcular(compradas, cadastradas []ItemCliente) *tabela {
+	t := &tabela{
+		nomes:     make(map[string]string),
+		clientes:  make(map[string]int),
+		juntos:    make(map[string]map[string]int),
+		doCliente: make(map[string]map[string]bool),
+	}
+
+	porCliente := make(map[string][]string)
+	for _, l := range compradas {
+		t.nomes[l.ItemID] = l.Nome
+		t.clientes[l.ItemID]++
+		porCliente[l.ClienteID] = append(porCliente[l.ClienteID], l.ItemID)
+	}
+	for _, itens := range porCliente {
+		for _, a := range itens {
+			for _, b := range itens {
+				if a == b {
+					continue
+				}
+				if t.juntos[a] == nil {
+					t.juntos[a] = make(map[string]int)
+				}
+				t.juntos[a][b]++
+			}
+		}
+	}
+
+	for _, linhas := range [][]ItemCliente{compradas, cadastradas} {
+		for _, l := range linhas {
+			if t.doCliente[l.ClienteID] == nil {
+				t.doCliente[l.ClienteID] = make(map[string]bool)
+			}
+			t.doCliente[l.ClienteID][l.ItemID] = true
+		}
+	}
+	return t
+}
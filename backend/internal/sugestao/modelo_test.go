@@ -0,0 +1,227 @@
+package sugestao
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// repositorioMemoria devolve as linhas fixas e guarda o início da janela
+// pedido.
+type repositorioMemoria struct {
+	compradas, cadastradas []ItemCliente
+	erro                   error
+	desde                  time.Time
+}
+
+func (r *repositorioMemoria) ItensComprados(_ context.Context, desde time.Time) ([]ItemCliente, error) {
+	r.desde = desde
+	return r.compradas, r.erro
+}
+
+func (r *repositorioMemoria) ItensCadastrados(context.Context) ([]ItemCliente, error) {
+	return r.cadastradas, r.erro
+}
+
+// itens devolve as linhas dos itens do cliente; o ID do item é o nome em
+// minúsculas.
+func itens(cliente string, nomes ...string) []ItemCliente {
+	linhas := make([]ItemCliente, 0, len(nomes))
+	for _, nome := range nomes {
+		linhas = append(linhas, ItemCliente{ClienteID: cliente, ItemID: strings.ToLower(nome), Nome: nome})
+	}
+	return linhas
+}
+
+func juntar(grupos ...[]ItemCliente) []ItemCliente {
+	var linhas []ItemCliente
+	for _, g := range grupos {
+		linhas = append(linhas, g...)
+	}
+	return linhas
+}
+
+func TestSugerir(t *testing.T) {
+	casos := []struct {
+		nome        string
+		compradas   []ItemCliente
+		cadastradas []ItemCliente
+		limite      int
+		esperado    []Sugestao
+	}{
+		{
+			// Café: 3 de 5 compradores de arroz, 4 compradores de café;
+			// feijão: 2 de 5, 3 compradores
+			nome: "pontua pela similaridade de cosseno",
+			compradas: juntar(
+				itens("c1", "Arroz", "Feijão"),
+				itens("c2", "Arroz", "Feijão", "Café"),
+				itens("c3", "Arroz", "Café"),
+				itens("c4", "Arroz", "Café"),
+				itens("c5", "Feijão", "Café"),
+				itens("alvo", "Arroz"),
+			),
+			limite: 10,
+			esperado: []Sugestao{
+				{ItemID: "café", Nome: "Café", Pontuacao: 0.671, Motivo: "Comprado por 3 dos 5 clientes que compram Arroz."},
+				{ItemID: "feijão", Nome: "Feijão", Pontuacao: 0.516, Motivo: "Comprado por 2 dos 5 clientes que compram Arroz."},
+			},
+		},
+		{
+			nome: "ignora pares comprados juntos por menos de minClientes",
+			compradas: juntar(
+				itens("c1", "Arroz", "Sal"),
+				itens("c2", "Arroz", "Feijão"),
+				itens("c3", "Arroz", "Feijão"),
+				itens("alvo", "Arroz"),
+			),
+			limite: 10,
+			esperado: []Sugestao{
+				{ItemID: "feijão", Nome: "Feijão", Pontuacao: 0.707, Motivo: "Comprado por 2 dos 4 clientes que compram Arroz."},
+			},
+		},
+		{
+			// O café cadastrado não conta como compra, mas é do cliente:
+			// não é sugerido e pesa na pontuação do sal
+			nome: "não sugere itens comprados nem cadastrados",
+			compradas: juntar(
+				itens("c1", "Arroz", "Café", "Sal"),
+				itens("c2", "Arroz", "Café", "Sal"),
+				itens("alvo", "Arroz"),
+			),
+			cadastradas: itens("alvo", "Café"),
+			limite:      10,
+			esperado: []Sugestao{
+				{ItemID: "sal", Nome: "Sal", Pontuacao: 1.816, Motivo: "Comprado por 2 dos 2 clientes que compram Café."},
+			},
+		},
+		{
+			// O sal soma a similaridade com o arroz e com o feijão; no
+			// empate, o motivo fica com o item de menor nome
+			nome: "soma a similaridade com cada item do cliente",
+			compradas: juntar(
+				itens("c1", "Arroz", "Feijão", "Sal"),
+				itens("c2", "Arroz", "Feijão", "Sal"),
+				itens("alvo", "Feijão", "Arroz"),
+			),
+			limite: 10,
+			esperado: []Sugestao{
+				{ItemID: "sal", Nome: "Sal", Pontuacao: 1.633, Motivo: "Comprado por 2 dos 3 clientes que compram Arroz."},
+			},
+		},
+		{
+			// Feijão: 0,75 pelo arroz e 0,5 pelo sal
+			nome: "motivo pelo item que mais contribuiu",
+			compradas: juntar(
+				itens("c1", "Arroz", "Feijão"),
+				itens("c2", "Arroz", "Feijão"),
+				itens("c3", "Arroz", "Feijão", "Sal"),
+				itens("c4", "Sal", "Feijão"),
+				itens("c5", "Sal"),
+				itens("alvo", "Sal", "Arroz"),
+			),
+			limite: 10,
+			esperado: []Sugestao{
+				{ItemID: "feijão", Nome: "Feijão", Pontuacao: 1.25, Motivo: "Comprado por 3 dos 4 clientes que compram Arroz."},
+			},
+		},
+		{
+			nome: "empate pela ordem do nome",
+			compradas: juntar(
+				itens("c1", "Arroz", "Feijão", "Café"),
+				itens("c2", "Arroz", "Feijão", "Café"),
+				itens("alvo", "Arroz"),
+			),
+			limite: 10,
+			esperado: []Sugestao{
+				{ItemID: "café", Nome: "Café", Pontuacao: 0.816, Motivo: "Comprado por 2 dos 3 clientes que compram Arroz."},
+				{ItemID: "feijão", Nome: "Feijão", Pontuacao: 0.816, Motivo: "Comprado por 2 dos 3 clientes que compram Arroz."},
+			},
+		},
+		{
+			nome: "corta no limite",
+			compradas: juntar(
+				itens("c1", "Arroz", "Feijão", "Café"),
+				itens("c2", "Arroz", "Feijão", "Café"),
+				itens("alvo", "Arroz"),
+			),
+			limite: 1,
+			esperado: []Sugestao{
+				{ItemID: "café", Nome: "Café", Pontuacao: 0.816, Motivo: "Comprado por 2 dos 3 clientes que compram Arroz."},
+			},
+		},
+		{
+			nome: "cliente sem itens",
+			compradas: juntar(
+				itens("c1", "Arroz", "Feijão"),
+				itens("c2", "Arroz", "Feijão"),
+			),
+			limite:   10,
+			esperado: []Sugestao{},
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			m := NewModelo(&repositorioMemoria{compradas: c.compradas, cadastradas: c.cadastradas})
+			if err := m.Atualizar(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			obtido := m.Sugerir("alvo", c.limite)
+			if !reflect.DeepEqual(obtido, c.esperado) {
+				t.Errorf("sugestões = %+v\nesperado %+v", obtido, c.esperado)
+			}
+		})
+	}
+}
+
+func TestModeloAntesDeAtualizar(t *testing.T) {
+	m := NewModelo(&repositorioMemoria{})
+	if s := m.Sugerir("alvo", 10); s != nil {
+		t.Errorf("sugestões = %+v, esperado nenhuma", s)
+	}
+	if !m.AtualizadoEm().IsZero() {
+		t.Errorf("atualizado em %v, esperado o instante zero", m.AtualizadoEm())
+	}
+}
+
+func TestAtualizarUsaAJanelaDeCompras(t *testing.T) {
+	repo := &repositorioMemoria{}
+	m := NewModelo(repo)
+	antes := time.Now()
+	if err := m.Atualizar(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if inicio := antes.AddDate(0, 0, -janelaDias); repo.desde.Before(inicio) || repo.desde.After(time.Now().AddDate(0, 0, -janelaDias)) {
+		t.Errorf("compras desde %v, esperado %d dias atrás", repo.desde, janelaDias)
+	}
+	if m.AtualizadoEm().Before(antes) {
+		t.Errorf("atualizado em %v, esperado depois de %v", m.AtualizadoEm(), antes)
+	}
+}
+
+// Um erro do repositório mantém a tabela anterior.
+func TestAtualizarComErroMantemATabela(t *testing.T) {
+	repo := &repositorioMemoria{compradas: juntar(
+		itens("c1", "Arroz", "Feijão"),
+		itens("c2", "Arroz", "Feijão"),
+		itens("alvo", "Arroz"),
+	)}
+	m := NewModelo(repo)
+	if err := m.Atualizar(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	atualizado := m.AtualizadoEm()
+
+	repo.erro = errors.New("conexão perdida")
+	if err := m.Atualizar(context.Background()); !errors.Is(err, repo.erro) {
+		t.Fatalf("erro = %v, esperado o do repositório", err)
+	}
+	if len(m.Sugerir("alvo", 10)) != 1 || !m.AtualizadoEm().Equal(atualizado) {
+		t.Errorf("tabela trocada depois do erro")
+	}
+}
@@ -0,0 +1,24 @@
+package sugestao
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Repositorio é o acesso às compras e aos itens cadastrados de que o
+	// modelo precisa, implementado sobre o banco no pacote repository.
+	Repositorio interface {
+		// ItensComprados devolve, sem repetição, os itens que cada cliente
+		// comprou a partir de desde.
+		ItensComprados(ctx context.Context, desde time.Time) ([]ItemCliente, error)
+		// ItensCadastrados devolve os itens recorrentes de cada cliente.
+		ItensCadastrados(ctx context.Context) ([]ItemCliente, error)
+	}
+
+	ItemCliente struct {
+		ClienteID string
+		ItemID    string
+		Nome      string
+	}
+)
@@ -0,0 +1,92 @@
+package ws
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	EventoAlertaCriado      = "alerta.criado"
+	EventoAlertaAlterado    = "alerta.alterado"
+	EventoAlertaResolvido   = "alerta.resolvido"
+	EventoCompraCriada      = "compra.criada"
+	EventoClienteAtualizado = "cliente.atualizado"
+	EventoTarefaCriada      = "tarefa.criada"
+	EventoTarefaVencida     = "tarefa.vencida"
+	EventoAlertasSnapshot   = "alertas.snapshot"
+	EventoAssinatura        = "assinatura.confirmada"
+	EventoCancelamento      = "assinatura.cancelada"
+	EventoErro              = "erro"
+)
+
+type (
+	// Evento é a mensagem enviada aos clientes. Seq é atribuído pelo hub aos
+	// eventos publicados; respostas diretas a um cliente não têm Seq. Os
+	// campos sem tag JSON servem apenas para decidir quais assinaturas
+	// recebem o evento.
+	Evento struct {
+		Seq   uint64 `json:"seq,omitempty"`
+		Nome  string `json:"evento"`
+		Dados any    `json:"dados"`
+
+		ClienteID     string `json:"-"`
+		TipoAlerta    string `json:"-"`
+		Representante string `json:"-"`
+	}
+
+	// Assinatura filtra os eventos recebidos por uma conexão. Filtros vazios
+	// aceitam tudo; os informados precisam ser todos satisfeitos.
+	Assinatura struct {
+		Eventos       []string `json:"eventos,omitempty"`
+		Tipos         []string `json:"tipos,omitempty"`    // tipos de alerta
+		Clientes      []string `json:"clientes,omitempty"` // IDs de cliente
+		Representante string   `json:"representante,omitempty"`
+	}
+
+	// mensagemCliente é o que o cliente envia pelo socket:
+	//
+	//	{"acao": "assinar", "tipos": ["inatividade"], "representante": "Ana"}
+	//	{"acao": "cancelar"}
+	mensagemCliente struct {
+		Acao string `json:"acao"`
+		Assinatura
+	}
+)
+
+// AssinaturaDaQuery monta a assinatura inicial a partir de parâmetros como
+// ?eventos=alerta.criado&tipos=inatividade,item_faltando&representante=Ana.
+func AssinaturaDaQuery(q url.Values) Assinatura {
+	return Assinatura{
+		Eventos:       lista(q.Get("eventos")),
+		Tipos:         lista(q.Get("tipos")),
+		Clientes:      lista(q.Get("clientes")),
+		Representante: q.Get("representante"),
+	}
+}
+
+func (a Assinatura) Aceita(e Evento) bool {
+	if len(a.Eventos) > 0 && !slices.Contains(a.Eventos, e.Nome) {
+		return false
+	}
+	if len(a.Tipos) > 0 && e.TipoAlerta != "" && !slices.Contains(a.Tipos, e.TipoAlerta) {
+		return false
+	}
+	if len(a.Clientes) > 0 && !slices.Contains(a.Clientes, e.ClienteID) {
+		return false
+	}
+	if a.Representante != "" && a.Representante != e.Representante {
+		return false
+	}
+	return true
+}
+
+func lista(v string) []string {
+	var itens []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			itens = append(itens, s)
+		}
+	}
+	return itens
+}
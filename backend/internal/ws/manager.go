@@ -1,44 +1,469 @@
 package ws
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-type Hub struct {
-	clients map[*websocket.Conn]bool
-	mu      sync.Mutex
-}
+const (
+	// Tempo máximo para escrever uma mensagem no socket.
+	writeWait = 10 * time.Second
+
+	// Tempo máximo sem receber pong do cliente.
+	pongWait = 60 * time.Second
+
+	// Intervalo dos pings; precisa ser menor que pongWait.
+	pingPeriod = (pongWait * 9) / 10
+
+	// Tamanho máximo das mensagens enviadas pelo cliente.
+	maxMessageSize = 4096
+
+	// Mensagens enfileiradas por conexão antes de considerá-la lenta.
+	sendBuffer = 32
+
+	// Eventos guardados para quem reconecta com ?desde=<seq>.
+	replayBuffer = 1000
+)
+
+type (
+	// Publicador é o que os handlers usam para emitir eventos. O Hub publica
+	// apenas para as conexões locais; o relay do pacote cluster implementa a
+	// mesma interface distribuindo os eventos entre as instâncias.
+	Publicador interface {
+		Publicar(e Evento)
+		Reter(chave string, e Evento)
+		Liberar(chave string, e Evento)
+		Retidos() map[string]Evento
+	}
+
+	Hub struct {
+		clients map[*Client]bool
+		// estado guarda os eventos retidos (os alertas ativos), enviados como
+		// snapshot a quem se conecta ou troca de assinatura.
+		estado map[string]Evento
+		// seq é o número do último evento publicado e historico os últimos
+		// replayBuffer eventos, em ordem.
+		seq       uint64
+		historico []Evento
+		mu        sync.RWMutex
+
+		// encerrado indica que Fechar foi chamado; novas conexões são
+		// fechadas logo após receberem o que estava pendente.
+		encerrado  bool
+		escritores sync.WaitGroup // goroutines de escrita dos WebSockets
+	}
+
+	// Client é um assinante registrado no hub, alimentado pelo canal send.
+	// Conexões WebSocket têm sua própria goroutine de escrita; nos demais
+	// transportes (SSE) quem registrou lê o canal via Mensagens.
+	Client struct {
+		hub    *Hub
+		conn   *websocket.Conn // nil fora do WebSocket
+		origem string
+		send   chan Mensagem
+		once   sync.Once
+
+		mu         sync.Mutex
+		assinatura Assinatura
+		ativa      bool
+	}
+
+	// Mensagem é um evento já serializado, com a sequência do evento (zero
+	// para respostas diretas).
+	Mensagem struct {
+		Seq   uint64
+		Corpo []byte
+	}
+)
 
 func NewHub() *Hub {
 	return &Hub{
-		clients: make(map[*websocket.Conn]bool),
+		clients: make(map[*Client]bool),
+		estado:  make(map[string]Evento),
+		// A sequência parte do relógio para que, após um restart, nenhum
+		// número já visto pelos navegadores seja reutilizado.
+		seq: uint64(time.Now().UnixMilli()),
 	}
 }
 
-func (h *Hub) AddClient(conn *websocket.Conn) {
+// AddClient registra a conexão WebSocket com a assinatura inicial e inicia a
+// goroutine de escrita. Veja registrar para o tratamento de desde.
+func (h *Hub) AddClient(conn *websocket.Conn, a Assinatura, desde uint64) *Client {
+	c := h.registrar(conn, conn.RemoteAddr().String(), a, desde)
+	h.escritores.Add(1)
+	go c.writePump()
+	return c
+}
+
+// AddStream registra um assinante sem conexão própria, como um stream SSE.
+// Quem chama deve ler Mensagens e chamar RemoveClient ao terminar.
+func (h *Hub) AddStream(origem string, a Assinatura, desde uint64) *Client {
+	return h.registrar(nil, origem, a, desde)
+}
+
+// registrar adiciona o assinante ao hub. Se desde for informado e os eventos
+// posteriores a ele ainda estiverem no buffer, eles são reenfileirados; caso
+// contrário o assinante recebe um snapshot do estado atual.
+func (h *Hub) registrar(conn *websocket.Conn, origem string, a Assinatura, desde uint64) *Client {
+	c := &Client{
+		hub:        h,
+		conn:       conn,
+		origem:     origem,
+		assinatura: a,
+		ativa:      true,
+	}
+
 	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.clients[conn] = true
+	perdidos, ok := h.desde(desde, a)
+	if !ok {
+		perdidos = []Evento{h.snapshot(a)}
+	}
+	c.send = make(chan Mensagem, sendBuffer+len(perdidos))
+	for _, e := range perdidos {
+		c.send <- Mensagem{Seq: e.Seq, Corpo: marshalEvento(e)}
+	}
+	encerrado := h.encerrado
+	if !encerrado {
+		h.clients[c] = true
+	}
+	h.mu.Unlock()
+
+	if encerrado {
+		c.once.Do(func() { close(c.send) })
+	}
+	return c
 }
 
-func (h *Hub) RemoveClient(conn *websocket.Conn) {
+// RemoveClient tira o cliente do hub e encerra sua goroutine de escrita, que
+// fecha a conexão. Pode ser chamado mais de uma vez.
+func (h *Hub) RemoveClient(c *Client) {
+	c.once.Do(func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+		close(c.send)
+	})
+}
+
+// Fechar desconecta todos os assinantes e passa a recusar novos. Os
+// WebSockets recebem o que já estava na fila seguido de um close frame
+// "going away", para que o navegador reconecte em outra instância; os
+// streams SSE terminam. Espera as goroutines de escrita até ctx expirar.
+func (h *Hub) Fechar(ctx context.Context) error {
+	h.mu.Lock()
+	h.encerrado = true
+	clientes := slices.Collect(maps.Keys(h.clients))
+	h.mu.Unlock()
+
+	for _, c := range clientes {
+		h.RemoveClient(c)
+	}
+
+	fim := make(chan struct{})
+	go func() {
+		h.escritores.Wait()
+		close(fim)
+	}()
+
+	select {
+	case <-fim:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) fechando() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.encerrado
+}
+
+// Count devolve o número de conexões ativas.
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Seq devolve o número do último evento publicado.
+func (h *Hub) Seq() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.seq
+}
+
+// DefinirSeq ajusta a sequência, usada quando ela passa a vir de fora (ex.:
+// do banco, no modo distribuído).
+func (h *Hub) DefinirSeq(seq uint64) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	delete(h.clients, conn)
-	conn.Close()
+	h.seq = seq
+	h.historico = nil
 }
 
-func (h *Hub) BroadcastJSON(data any) {
+// Restaurar guarda um evento no estado sem publicá-lo.
+func (h *Hub) Restaurar(chave string, e Evento) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	for conn := range h.clients {
-		err := conn.WriteJSON(data)
+	h.estado[chave] = e
+}
+
+// Retidos devolve uma cópia do estado retido.
+func (h *Hub) Retidos() map[string]Evento {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return maps.Clone(h.estado)
+}
+
+// Publicar envia o evento a todas as assinaturas que o aceitam.
+func (h *Hub) Publicar(e Evento) {
+	h.mu.Lock()
+	lentos := h.enviar(e)
+	h.mu.Unlock()
+
+	h.desconectar(lentos)
+}
+
+// Reter publica o evento e o guarda no estado sob a chave informada,
+// substituindo o anterior.
+func (h *Hub) Reter(chave string, e Evento) {
+	h.mu.Lock()
+	h.estado[chave] = e
+	lentos := h.enviar(e)
+	h.mu.Unlock()
+
+	h.desconectar(lentos)
+}
+
+// Liberar remove a chave do estado e publica o evento informado.
+func (h *Hub) Liberar(chave string, e Evento) {
+	h.mu.Lock()
+	delete(h.estado, chave)
+	lentos := h.enviar(e)
+	h.mu.Unlock()
+
+	h.desconectar(lentos)
+}
+
+// enviar numera o evento (se ainda não tiver Seq), guarda-o no histórico e o
+// enfileira sem bloquear, devolvendo os clientes com a fila cheia. Quem chama
+// precisa segurar h.mu para escrita.
+func (h *Hub) enviar(e Evento) []*Client {
+	if e.Seq == 0 {
+		h.seq++
+		e.Seq = h.seq
+	}
+	h.seq = max(h.seq, e.Seq)
+	h.historico = append(h.historico, e)
+	if len(h.historico) > replayBuffer {
+		h.historico = h.historico[len(h.historico)-replayBuffer:]
+	}
+
+	var (
+		msg    *Mensagem
+		lentos []*Client
+	)
+
+	for c := range h.clients {
+		if !c.aceita(e) {
+			continue
+		}
+		if msg == nil {
+			msg = &Mensagem{Seq: e.Seq, Corpo: marshalEvento(e)}
+		}
+		select {
+		case c.send <- *msg:
+		default:
+			lentos = append(lentos, c)
+		}
+	}
+
+	return lentos
+}
+
+func (h *Hub) desconectar(lentos []*Client) {
+	for _, c := range lentos {
+		slog.Warn("desconectando assinante lento", "origem", c.origem)
+		h.RemoveClient(c)
+	}
+}
+
+// snapshot reúne os dados dos eventos retidos aceitos pela assinatura, em
+// ordem de chave. Quem chama precisa segurar h.mu.
+func (h *Hub) snapshot(a Assinatura) Evento {
+	chaves := make([]string, 0, len(h.estado))
+	for chave, e := range h.estado {
+		if a.Aceita(e) {
+			chaves = append(chaves, chave)
+		}
+	}
+	sort.Strings(chaves)
+
+	dados := make([]any, 0, len(chaves))
+	for _, chave := range chaves {
+		dados = append(dados, h.estado[chave].Dados)
+	}
+
+	return Evento{Seq: h.seq, Nome: EventoAlertasSnapshot, Dados: dados}
+}
+
+// desde devolve os eventos aceitos pela assinatura publicados depois de seq.
+// ok é falso quando seq não foi informado ou já saiu do buffer, e então
+// apenas um snapshot garante um estado consistente. Quem chama precisa
+// segurar h.mu.
+func (h *Hub) desde(seq uint64, a Assinatura) ([]Evento, bool) {
+	if seq == 0 || seq > h.seq {
+		return nil, false
+	}
+	if len(h.historico) == 0 || h.historico[0].Seq > seq+1 {
+		return nil, seq == h.seq
+	}
+
+	var eventos []Evento
+	for _, e := range h.historico {
+		if e.Seq > seq && a.Aceita(e) {
+			eventos = append(eventos, e)
+		}
+	}
+	return eventos, true
+}
+
+// Enviar manda um evento apenas para este cliente, ignorando a assinatura.
+func (c *Client) Enviar(e Evento) {
+	h := c.hub
+
+	h.mu.RLock()
+	lento := false
+	if h.clients[c] {
+		select {
+		case c.send <- Mensagem{Corpo: marshalEvento(e)}:
+		default:
+			lento = true
+		}
+	}
+	h.mu.RUnlock()
+
+	if lento {
+		h.desconectar([]*Client{c})
+	}
+}
+
+// Mensagens é o canal de saída do assinante; é fechado quando ele é removido
+// do hub.
+func (c *Client) Mensagens() <-chan Mensagem {
+	return c.send
+}
+
+func (c *Client) aceita(e Evento) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ativa && c.assinatura.Aceita(e)
+}
+
+// ReadPump lê o socket até a conexão cair, tratando as mensagens de
+// assinatura e mantendo o prazo de leitura renovado a cada pong. Deve ser
+// chamado na goroutine do handler.
+func (c *Client) ReadPump() {
+	defer c.hub.RemoveClient(c)
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
-			log.Println("Erro ao enviar para WebSocket:", err)
-			h.RemoveClient(conn)
+			return
+		}
+		c.tratarMensagem(data)
+	}
+}
+
+func (c *Client) tratarMensagem(data []byte) {
+	var msg mensagemCliente
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.Enviar(Evento{Nome: EventoErro, Dados: map[string]string{"mensagem": "mensagem inválida"}})
+		return
+	}
+
+	switch msg.Acao {
+	case "assinar":
+		c.mu.Lock()
+		c.assinatura = msg.Assinatura
+		c.ativa = true
+		c.mu.Unlock()
+
+		c.hub.mu.RLock()
+		snapshot := c.hub.snapshot(msg.Assinatura)
+		c.hub.mu.RUnlock()
+
+		c.Enviar(Evento{Nome: EventoAssinatura, Dados: msg.Assinatura})
+		c.Enviar(snapshot)
+
+	case "cancelar":
+		c.mu.Lock()
+		c.ativa = false
+		c.mu.Unlock()
+
+		c.Enviar(Evento{Nome: EventoCancelamento})
+
+	default:
+		c.Enviar(Evento{Nome: EventoErro, Dados: map[string]string{"mensagem": "ação desconhecida: " + msg.Acao}})
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+		c.hub.RemoveClient(c)
+		c.hub.escritores.Done()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				fechamento := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+				if c.hub.fechando() {
+					fechamento = websocket.FormatCloseMessage(websocket.CloseGoingAway, "servidor encerrando")
+				}
+				c.conn.WriteMessage(websocket.CloseMessage, fechamento)
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg.Corpo); err != nil {
+				slog.Warn("erro ao enviar para WebSocket", "origem", c.origem, "erro", err)
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
+
+// marshalEvento serializa o evento; em caso de falha envia um evento de erro.
+func marshalEvento(e Evento) []byte {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("erro ao serializar evento", "evento", e.Nome, "erro", err)
+		return []byte(`{"evento":"erro"}`)
+	}
+	return msg
+}
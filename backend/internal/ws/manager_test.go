@@ -0,0 +1,351 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// mensagemRecebida é um evento como chega ao navegador.
+type mensagemRecebida struct {
+	Seq    uint64          `json:"seq"`
+	Evento string          `json:"evento"`
+	Dados  json.RawMessage `json:"dados"`
+}
+
+// cliente devolve o cliente_id dos dados de um evento de alerta.
+func (m mensagemRecebida) cliente() string {
+	var dados struct {
+		ClienteID string `json:"cliente_id"`
+	}
+	json.Unmarshal(m.Dados, &dados)
+	return dados.ClienteID
+}
+
+// servidorHub sobe o hub atrás de um servidor HTTP de teste, como o
+// handler de WebSocket, e o encerra ao fim do teste.
+func servidorHub(t *testing.T) (*Hub, *httptest.Server) {
+	t.Helper()
+	hub := NewHub()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		desde, _ := strconv.ParseUint(r.URL.Query().Get("desde"), 10, 64)
+		hub.AddClient(conn, AssinaturaDaQuery(r.URL.Query()), desde).ReadPump()
+	}))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := hub.Fechar(ctx); err != nil {
+			t.Errorf("escritores ainda rodando ao encerrar: %v", err)
+		}
+		srv.Close()
+	})
+	return hub, srv
+}
+
+func conectar(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/?"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func ler(conn *websocket.Conn) (mensagemRecebida, error) {
+	var msg mensagemRecebida
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	err := conn.ReadJSON(&msg)
+	return msg, err
+}
+
+func lerEvento(t *testing.T, conn *websocket.Conn) mensagemRecebida {
+	t.Helper()
+	msg, err := ler(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// esperar aguarda a condição por até cinco segundos.
+func esperar(t *testing.T, descricao string, cond func() bool) {
+	t.Helper()
+	for fim := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(fim) {
+			t.Fatalf("tempo esgotado esperando %s", descricao)
+		}
+	}
+}
+
+func criado(cliente string) Evento {
+	return Evento{Nome: EventoAlertaCriado, Dados: map[string]string{"cliente_id": cliente}, ClienteID: cliente}
+}
+
+// Conexões entrando e saindo durante a publicação não travam o hub nem
+// atrasam quem continua conectado, que recebe todos os eventos em ordem.
+func TestHubPublicaComConexoesCaindo(t *testing.T) {
+	const (
+		ficam   = 10
+		caem    = 10
+		eventos = 30 // abaixo de sendBuffer, para ninguém ser tido como lento
+	)
+	hub, srv := servidorHub(t)
+
+	conns := make([]*websocket.Conn, ficam+caem)
+	for i := range conns {
+		conns[i] = conectar(t, srv, "")
+		if msg := lerEvento(t, conns[i]); msg.Evento != EventoAlertasSnapshot {
+			t.Fatalf("primeira mensagem = %s, esperado o snapshot", msg.Evento)
+		}
+	}
+	esperar(t, "as conexões no hub", func() bool { return hub.Count() == ficam+caem })
+
+	var wg sync.WaitGroup
+	recebidos := make([][]string, ficam)
+	for i := range ficam {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range eventos {
+				msg, err := ler(conns[i])
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				recebidos[i] = append(recebidos[i], msg.cliente())
+			}
+		}()
+	}
+	for i := range caem {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Duration(i) * time.Millisecond)
+			conns[ficam+i].Close()
+		}()
+	}
+	for i := range eventos {
+		hub.Publicar(criado(strconv.Itoa(i)))
+	}
+	wg.Wait()
+
+	for i, clientes := range recebidos {
+		if len(clientes) != eventos {
+			t.Fatalf("conexão %d recebeu %d eventos, esperado %d", i, len(clientes), eventos)
+		}
+		for j, cliente := range clientes {
+			if cliente != strconv.Itoa(j) {
+				t.Fatalf("conexão %d: evento %d do cliente %s, esperado %d", i, j, cliente, j)
+			}
+		}
+	}
+	esperar(t, "a saída das conexões fechadas", func() bool { return hub.Count() == ficam })
+}
+
+// Uma conexão que não lê o socket enche a fila e é desconectada, sem
+// afetar quem continua lendo.
+func TestHubDesconectaClienteLento(t *testing.T) {
+	hub, srv := servidorHub(t)
+	ativa := conectar(t, srv, "")
+	lenta := conectar(t, srv, "") // nunca lê depois do snapshot
+	lerEvento(t, ativa)
+	lerEvento(t, lenta)
+	esperar(t, "as duas conexões", func() bool { return hub.Count() == 2 })
+
+	var recebidas atomic.Int64
+	go func() {
+		for {
+			if _, err := ler(ativa); err != nil {
+				return
+			}
+			recebidas.Add(1)
+		}
+	}()
+
+	// Eventos grandes enchem o buffer do socket e, depois, a fila
+	carga := strings.Repeat("x", 256<<10)
+	enviados := 0
+	for hub.Count() == 2 {
+		if enviados == 2000 {
+			t.Fatal("a conexão que não lê não foi desconectada")
+		}
+		hub.Publicar(Evento{Nome: EventoCompraCriada, Dados: carga})
+		enviados++
+		time.Sleep(time.Millisecond)
+	}
+	hub.Publicar(criado("c1"))
+	enviados++
+
+	esperar(t, "a entrega de tudo à conexão ativa", func() bool { return recebidas.Load() == int64(enviados) })
+	if n := hub.Count(); n != 1 {
+		t.Errorf("conexões no hub = %d, esperado 1", n)
+	}
+}
+
+// A assinatura da query filtra os eventos e o snapshot; trocá-la pelo
+// socket confirma a nova assinatura e reenvia o snapshot filtrado.
+func TestHubFiltraPelaAssinatura(t *testing.T) {
+	hub, srv := servidorHub(t)
+	hub.Reter("c1|inatividade|", criado("c1"))
+	hub.Reter("c2|inatividade|", criado("c2"))
+
+	conn := conectar(t, srv, "clientes=c1")
+	var snapshot struct {
+		Evento string
+		Dados  []map[string]string
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Evento != EventoAlertasSnapshot || len(snapshot.Dados) != 1 || snapshot.Dados[0]["cliente_id"] != "c1" {
+		t.Fatalf("snapshot = %+v, esperado só o alerta de c1", snapshot)
+	}
+
+	hub.Publicar(criado("c2"))
+	hub.Publicar(criado("c1"))
+	if msg := lerEvento(t, conn); msg.cliente() != "c1" {
+		t.Fatalf("recebeu o evento de %s, fora da assinatura", msg.cliente())
+	}
+
+	if err := conn.WriteJSON(map[string]any{"acao": "assinar", "clientes": []string{"c2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := lerEvento(t, conn); msg.Evento != EventoAssinatura {
+		t.Fatalf("recebeu %s, esperado %s", msg.Evento, EventoAssinatura)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Dados) != 1 || snapshot.Dados[0]["cliente_id"] != "c2" {
+		t.Errorf("snapshot depois de assinar = %+v, esperado só o alerta de c2", snapshot)
+	}
+
+	if err := conn.WriteJSON(map[string]string{"acao": "cancelar"}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := lerEvento(t, conn); msg.Evento != EventoCancelamento {
+		t.Fatalf("recebeu %s, esperado %s", msg.Evento, EventoCancelamento)
+	}
+	hub.Publicar(criado("c2"))
+	if err := conn.WriteJSON(map[string]string{"acao": "desconhecida"}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := lerEvento(t, conn); msg.Evento != EventoErro {
+		t.Errorf("depois de cancelar recebeu %s, esperado só o %s", msg.Evento, EventoErro)
+	}
+}
+
+// Quem reconecta com ?desde recebe só os eventos perdidos, filtrados pela
+// assinatura; fora do buffer, recebe o snapshot do estado.
+func TestHubReenviaEventosPerdidos(t *testing.T) {
+	hub, srv := servidorHub(t)
+	hub.Reter("c1|inatividade|", criado("c1"))
+
+	conn := conectar(t, srv, "clientes=c1,c2")
+	snapshot := lerEvento(t, conn)
+	if snapshot.Evento != EventoAlertasSnapshot || snapshot.Seq != hub.Seq() {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+	conn.Close()
+	esperar(t, "a saída da conexão", func() bool { return hub.Count() == 0 })
+
+	hub.Publicar(criado("c1"))
+	hub.Publicar(criado("c3")) // fora da assinatura
+	hub.Liberar("c1|inatividade|", Evento{Nome: EventoAlertaResolvido, ClienteID: "c1"})
+	hub.Publicar(criado("c2"))
+
+	conn = conectar(t, srv, "clientes=c1,c2&desde="+strconv.FormatUint(snapshot.Seq, 10))
+	var nomes []string
+	for range 3 {
+		msg := lerEvento(t, conn)
+		nomes = append(nomes, msg.Evento+" "+string(msg.Dados))
+	}
+	esperado := []string{
+		`alerta.criado {"cliente_id":"c1"}`,
+		"alerta.resolvido null",
+		`alerta.criado {"cliente_id":"c2"}`,
+	}
+	if strings.Join(nomes, "\n") != strings.Join(esperado, "\n") {
+		t.Errorf("eventos reenviados:\n%s\nesperado:\n%s", strings.Join(nomes, "\n"), strings.Join(esperado, "\n"))
+	}
+
+	// Com os eventos já fora do buffer, só o snapshot é consistente
+	for i := range replayBuffer {
+		hub.Publicar(criado(strconv.Itoa(i)))
+	}
+	conn = conectar(t, srv, "desde="+strconv.FormatUint(snapshot.Seq, 10))
+	if msg := lerEvento(t, conn); msg.Evento != EventoAlertasSnapshot || string(msg.Dados) != "[]" {
+		t.Errorf("reconexão fora do buffer recebeu %s %s, esperado o snapshot vazio", msg.Evento, msg.Dados)
+	}
+}
+
+// RemoveClient fecha a conexão com "normal closure".
+func TestRemoveClientFechaConexao(t *testing.T) {
+	hub, srv := servidorHub(t)
+	conn := conectar(t, srv, "")
+	lerEvento(t, conn)
+	esperar(t, "a conexão no hub", func() bool { return hub.Count() == 1 })
+
+	hub.mu.RLock()
+	var cliente *Client
+	for c := range hub.clients {
+		cliente = c
+	}
+	hub.mu.RUnlock()
+	hub.RemoveClient(cliente)
+	hub.RemoveClient(cliente) // não pode fechar o canal de novo
+
+	if _, err := ler(conn); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("erro ao ler depois de RemoveClient = %v, esperado close %d", err, websocket.CloseNormalClosure)
+	}
+	if n := hub.Count(); n != 0 {
+		t.Errorf("conexões no hub = %d, esperado 0", n)
+	}
+}
+
+// Ao encerrar, o hub envia o que estava na fila e fecha com "going away".
+func TestFecharEnviaGoingAway(t *testing.T) {
+	hub, srv := servidorHub(t)
+	conn := conectar(t, srv, "")
+	lerEvento(t, conn)
+	esperar(t, "a conexão no hub", func() bool { return hub.Count() == 1 })
+
+	hub.Publicar(criado("c1"))
+	if err := hub.Fechar(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := lerEvento(t, conn); msg.Evento != EventoAlertaCriado {
+		t.Fatalf("recebeu %s antes do fechamento, esperado %s", msg.Evento, EventoAlertaCriado)
+	}
+	_, err := ler(conn)
+	var fechamento *websocket.CloseError
+	if !errors.As(err, &fechamento) || fechamento.Code != websocket.CloseGoingAway {
+		t.Errorf("erro ao ler depois de Fechar = %v, esperado close %d", err, websocket.CloseGoingAway)
+	}
+
+	// Novas conexões recebem o snapshot e são fechadas em seguida
+	conn = conectar(t, srv, "")
+	lerEvento(t, conn)
+	if _, err := ler(conn); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("conexão depois de Fechar: %v, esperado close %d", err, websocket.CloseGoingAway)
+	}
+}
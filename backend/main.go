@@ -2,87 +2,479 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"smart-retention/internal/alerta"
+	"smart-retention/internal/config"
 	"smart-retention/internal/handler"
+	"smart-retention/internal/infra/cluster"
 	"smart-retention/internal/infra/db"
+	"smart-retention/internal/infra/logs"
+	"smart-retention/internal/infra/rastreio"
+	"smart-retention/internal/repository"
+	"smart-retention/internal/service"
+	"smart-retention/internal/sugestao"
 	"smart-retention/internal/ws"
+	"smart-retention/migrations"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"gorm.io/gorm"
 )
 
 func main() {
 	gin.SetMode(gin.ReleaseMode)
 
-	dbConn := db.Connect()
+	cfg, args, err := config.Carregar(os.Args[1:])
+	if err != nil {
+		fatal("erro ao carregar a configuração", err)
+	}
+
+	logs.Configurar(os.Stdout, cfg.Logs.Nivel)
+
+	encerrarRastreio, err := rastreio.Configurar(cfg.Rastreio)
+	if err != nil {
+		fatal("erro ao configurar o rastreio", err)
+	}
+
+	dbConn, err := db.Connect(cfg.Banco)
+	if err != nil {
+		fatal("erro ao conectar no banco", err)
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		migrar(dbConn, args[1:])
+		return
+	}
+
+	migrador, err := db.NewMigrador(dbConn, migrations.FS)
+	if err != nil {
+		fatal("erro ao carregar as migrações", err)
+	}
+	n, err := migrador.Up()
+	if err != nil {
+		fatal("erro ao migrar o banco", err)
+	}
+	slog.Info("banco migrado", "aplicadas", n)
 
-	db.AutoMigrate(dbConn)
+	// ctx é cancelado no SIGTERM (troca de task no ECS) ou no Ctrl+C e
+	// encerra os laços em segundo plano; o resto é desligado em ordem no fim.
+	ctx, parar := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer parar()
+
+	var tarefas sync.WaitGroup
+	emSegundoPlano := func(fn func(context.Context)) {
+		tarefas.Add(1)
+		go func() {
+			defer tarefas.Done()
+			fn(ctx)
+		}()
+	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(otelgin.Middleware(cfg.Rastreio.Servico), handler.Rastrear(), handler.Erros(), handler.Recuperar())
+	r.NoRoute(handler.RotaNaoEncontrada)
 
 	hub := ws.NewHub()
 	websocketHandler := &handler.WebSocketHandler{Hub: hub}
-	alertaHandler := handler.NewHandler(dbConn, hub)
 
-	go func() {
-		for {
-			time.Sleep(10 * time.Second)
+	// Os eventos passam pelo Postgres para chegar aos sockets de todas as
+	// instâncias, e só a instância líder roda os jobs agendados.
+	relay := cluster.NewRelay(dbConn, hub)
+	if err := relay.Iniciar(); err != nil {
+		fatal("erro ao iniciar o relay de eventos", err)
+	}
+	emSegundoPlano(relay.Escutar)
 
-			alertas, err := alertaHandler.BuildAllAlertas()
-			if err != nil {
-				log.Println("Erro ao gerar alertas:", err)
-				continue
-			}
+	lider := cluster.NewLider(dbConn, cluster.ChaveLider)
+	emSegundoPlano(lider.Manter)
+
+	repos := repository.NewGorm(dbConn)
+
+	// O modelo de sugestões fica em memória: cada instância calcula o seu
+	// ao subir e o recalcula pelo cron.
+	sugestoes := sugestao.NewModelo(repos.Sugestoes)
+	emSegundoPlano(func(ctx context.Context) {
+		executarJob(ctx, "atualizar_sugestoes", sugestoes.Atualizar)
+	})
+
+	motor := alerta.NewDefaultEngine(repos.MotorAlertas, cfg.Local, alerta.Opcoes{
+		DiasInatividade:  cfg.Alertas.DiasInatividade,
+		DiasItemFaltando: cfg.Alertas.DiasItemFaltando,
+		Sugestoes:        sugestoes,
+	})
+	registro := registrarMetricas(dbConn, hub, motor)
+	saude := handler.NewSaudeHandler(dbConn, migrador)
+
+	servicos := service.New(repos, relay, motor, sugestoes)
+	h := handler.NewHandler(servicos)
 
-			if len(alertas) > 0 {
-				hub.BroadcastJSON(alertas)
+	emSegundoPlano(func(ctx context.Context) {
+		ticker := time.NewTicker(cfg.Alertas.Intervalo)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
 			}
+
+			lider.SeLider(func() {
+				executarJob(ctx, "publicar_alertas", servicos.Alertas.Publicar)
+			})
 		}
-	}()
+	})
 
+	r.Use(handler.MedirRequisicoes(registro.duracaoHTTP))
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
+		AllowOrigins:     cfg.CORSOrigens,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
+		ExposeHeaders:    []string{handler.CabecalhoRequestID, "Deprecation", "Sunset", "Link"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	h := handler.NewHandler(dbConn, hub)
-
-	api := r.Group("/api")
-	{
-		api.GET("/clientes", h.ListarClientes)
-		api.POST("/clientes", h.CriarCliente)
-		api.POST("/compras", h.CriarCompra)
-		api.GET("/alertas/hoje", h.GerarAlertasHoje)
-		api.GET("/compras", h.ListarCompras)
-		api.GET("/dashboard", h.ListarDashboard)
-		api.GET("/alertas", h.ListarAlertas)
-		api.GET("/ws/alertas", websocketHandler.HandleAlertasWS)
-		api.GET("/clientes/:id/historico", h.HistoricoCliente)
-		api.GET("/clientes/:id", h.BuscarClientePeloID)
-		api.PUT("/clientes/:id", h.AtualizarCliente)
-		api.DELETE("/clientes/:id", h.DeletarCliente)
-		api.GET("/", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	r.GET("/healthz", saude.Healthz)
+	r.GET("/readyz", saude.Readyz)
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registro, promhttp.HandlerOpts{})))
+
+	// As rotas sem versão continuam como apelidos de /api/v1 até os
+	// clientes migrarem. Obsoleta as faz responder nos formatos de antes da
+	// v1, com os modelos crus e os erros em {"erro": ...}.
+	registrarRotas(r.Group("/api/v1"), h, websocketHandler)
+	registrarRotas(r.Group("/api", handler.Obsoleta("/api", "/api/v1", apiSemVersaoObsoletaEm, apiSemVersaoRemovidaEm)), h, websocketHandler)
+
+	// As agendas valem no fuso de APP_TIMEZONE, não no do servidor
+	c := cron.New(cron.WithLocation(cfg.Local))
+
+	c.AddFunc(cfg.Alertas.CronDiario, func() {
+		lider.SeLider(func() {
+			executarJob(ctx, "alertas_do_dia", func(ctx context.Context) error {
+				servicos.Alertas.DispararDiario(ctx)
+				return nil
+			})
 		})
-	}
+	})
 
-	c := cron.New()
+	c.AddFunc(cfg.Alertas.CronLimpeza, func() {
+		lider.SeLider(func() {
+			executarJob(ctx, "limpar_fila_eventos", func(context.Context) error {
+				return relay.Limpar()
+			})
+		})
+	})
 
-	c.AddFunc("0 8 * * *", func() {
-		log.Println("📢 Rodando verificação de alertas automáticos...")
-		h.DispararAlertaDiario()
+	c.AddFunc(cfg.Alertas.CronTarefas, func() {
+		lider.SeLider(func() {
+			executarJob(ctx, "lembrar_tarefas_vencidas", servicos.Tarefas.LembrarVencidas)
+		})
+	})
+
+	// Fora do SeLider: o modelo é de cada instância
+	c.AddFunc(cfg.Alertas.CronSugestoes, func() {
+		executarJob(ctx, "atualizar_sugestoes", sugestoes.Atualizar)
 	})
 
 	c.Start()
 
-	err := r.Run("0.0.0.0:8080")
+	srv := &http.Server{
+		Addr:              cfg.Endereco,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	erroServidor := make(chan error, 1)
+	go func() {
+		slog.Info("servidor ouvindo", "endereco", cfg.Endereco)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			erroServidor <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		slog.Info("sinal recebido, encerrando")
+	case err := <-erroServidor:
+		slog.Error("erro ao iniciar o servidor", "erro", err)
+		parar()
+	}
+
+	saude.Encerrar()
+	encerrar(cfg.Encerramento, srv, c, hub, &tarefas, dbConn, encerrarRastreio)
+}
+
+// encerrar desliga a aplicação em ordem, dentro do prazo: para de agendar
+// jobs, fecha os sockets do hub (que de outro modo prenderiam o Shutdown com
+// streams SSE abertos), espera as requisições em andamento, os jobs e os
+// laços em segundo plano (o líder libera o lock), fecha o pool e envia os
+// últimos spans.
+func encerrar(prazo time.Duration, srv *http.Server, c *cron.Cron, hub *ws.Hub, tarefas *sync.WaitGroup, dbConn *gorm.DB, encerrarRastreio func(context.Context) error) {
+	ctx, cancelar := context.WithTimeout(context.Background(), prazo)
+	defer cancelar()
+
+	jobs := c.Stop()
+
+	if err := hub.Fechar(ctx); err != nil {
+		slog.Error("erro ao fechar conexões do hub", "erro", err)
+	}
+
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("erro ao encerrar o servidor HTTP", "erro", err)
+	}
+
+	select {
+	case <-jobs.Done():
+	case <-ctx.Done():
+		slog.Warn("prazo esgotado esperando os jobs agendados")
+	}
+
+	fim := make(chan struct{})
+	go func() {
+		tarefas.Wait()
+		close(fim)
+	}()
+	select {
+	case <-fim:
+	case <-ctx.Done():
+		slog.Warn("prazo esgotado esperando as tarefas em segundo plano")
+	}
+
+	if sqlDB, err := dbConn.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("erro ao fechar o pool do banco", "erro", err)
+		}
+	}
+
+	if err := encerrarRastreio(ctx); err != nil {
+		slog.Warn("spans pendentes não exportados", "erro", err)
+	}
+
+	slog.Info("servidor encerrado")
+}
+
+// apiSemVersaoObsoletaEm é quando as rotas /api sem versão foram depreciadas
+// e apiSemVersaoRemovidaEm, quando deixam de existir.
+var (
+	apiSemVersaoObsoletaEm = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	apiSemVersaoRemovidaEm = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+)
+
+func registrarRotas(api *gin.RouterGroup, h *handler.Handler, websocketHandler *handler.WebSocketHandler) {
+	api.GET("/clientes", h.ListarClientes)
+	api.POST("/clientes", h.CriarCliente)
+	api.POST("/compras", h.CriarCompra)
+	api.GET("/alertas/hoje", h.GerarAlertasHoje)
+	api.GET("/compras", h.ListarCompras)
+	api.GET("/dashboard", h.ListarDashboard)
+	api.GET("/alertas", h.ListarAlertas)
+	api.GET("/alertas/incidentes", h.ListarIncidentes)
+	api.GET("/analytics/alertas", h.AnalyticsAlertas)
+	api.GET("/ws/alertas", websocketHandler.HandleAlertasWS)
+	api.GET("/eventos", websocketHandler.HandleEventosSSE)
+	api.GET("/clientes/:id/historico", h.HistoricoCliente)
+	api.GET("/clientes/:id", h.BuscarClientePeloID)
+	api.PUT("/clientes/:id", h.AtualizarCliente)
+	api.DELETE("/clientes/:id", h.DeletarCliente)
+	api.GET("/clientes/:id/contatos", h.ListarContatos)
+	api.POST("/clientes/:id/contatos", h.CriarContato)
+	api.PUT("/clientes/:id/contatos/:contato_id", h.AtualizarContato)
+	api.DELETE("/clientes/:id/contatos/:contato_id", h.DeletarContato)
+	api.GET("/clientes/:id/interacoes", h.ListarInteracoes)
+	api.POST("/clientes/:id/interacoes", h.RegistrarInteracao)
+	api.PUT("/clientes/:id/interacoes/:interacao_id", h.AtualizarInteracao)
+	api.DELETE("/clientes/:id/interacoes/:interacao_id", h.DeletarInteracao)
+	api.GET("/clientes/:id/fechamentos", h.ListarFechamentos)
+	api.POST("/clientes/:id/fechamentos", h.CriarFechamento)
+	api.DELETE("/clientes/:id/fechamentos/:fechamento_id", h.DeletarFechamento)
+	api.GET("/clientes/:id/pedido-recorrente", h.BuscarPedidoRecorrente)
+	api.PUT("/clientes/:id/pedido-recorrente", h.SalvarPedidoRecorrente)
+	api.DELETE("/clientes/:id/pedido-recorrente", h.DeletarPedidoRecorrente)
+	api.POST("/clientes/:id/pedido-recorrente/inferir", h.InferirPedidoRecorrente)
+	api.GET("/clientes/:id/proximo-pedido", h.ProximoPedido)
+	api.GET("/clientes/:id/sugestoes", h.SugestoesCliente)
+	api.POST("/clientes/:id/compras/repetir", h.RepetirCompra)
+	api.GET("/feriados", h.ListarFeriados)
+	api.POST("/feriados", h.CriarFeriado)
+	api.DELETE("/feriados/:id", h.DeletarFeriado)
+	api.GET("/tarefas", h.ListarTarefas)
+	api.POST("/tarefas", h.CriarTarefa)
+	api.GET("/tarefas/regras", h.ListarRegrasTarefa)
+	api.PUT("/tarefas/regras/:tipo_alerta", h.SalvarRegraTarefa)
+	api.DELETE("/tarefas/regras/:tipo_alerta", h.DeletarRegraTarefa)
+	api.GET("/tarefas/:id", h.BuscarTarefaPeloID)
+	api.PUT("/tarefas/:id", h.AtualizarTarefa)
+	api.GET("/regras", h.ListarRegras)
+	api.POST("/regras", h.CriarRegra)
+	api.POST("/regras/simular", h.SimularRegra)
+	api.GET("/regras/:id", h.BuscarRegraPeloID)
+	api.PUT("/regras/:id", h.AtualizarRegra)
+	api.DELETE("/regras/:id", h.DeletarRegra)
+	api.GET("/openapi.json", handler.Especificacao)
+	api.GET("/docs", handler.SwaggerUI)
+	api.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+}
+
+// executarJob roda um job agendado dentro de um span próprio e registra a
+// falha, se houver. Erros causados pelo desligamento são ignorados.
+func executarJob(ctx context.Context, nome string, fn func(context.Context) error) {
+	ctx, span := rastreio.Iniciar(ctx, "job "+nome)
+	defer span.End()
+
+	if err := fn(ctx); err != nil && ctx.Err() == nil {
+		rastreio.Erro(span, err)
+		slog.ErrorContext(ctx, "erro no job agendado", "job", nome, "erro", err)
+	}
+}
+
+// fatal registra o erro e encerra o processo.
+func fatal(msg string, err error) {
+	slog.Error(msg, "erro", err)
+	os.Exit(1)
+}
+
+type metricasApp struct {
+	*prometheus.Registry
+	duracaoHTTP *prometheus.HistogramVec
+}
+
+// registrarMetricas cria as métricas expostas em /metrics: latência HTTP por
+// rota, pool do banco, assinantes do hub e geração de alertas, além das do
+// runtime do Go e do processo.
+func registrarMetricas(dbConn *gorm.DB, hub *ws.Hub, motor *alerta.Engine) metricasApp {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	duracaoHTTP := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "smart_retention_http_request_duration_seconds",
+		Help: "Duração das requisições HTTP.",
+	}, []string{"method", "route", "status"})
+	reg.MustRegister(duracaoHTTP)
+
+	if sqlDB, err := dbConn.DB(); err == nil {
+		reg.MustRegister(
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "smart_retention_db_connections_open",
+				Help: "Conexões abertas no pool do banco.",
+			}, func() float64 { return float64(sqlDB.Stats().OpenConnections) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "smart_retention_db_connections_in_use",
+				Help: "Conexões do pool em uso.",
+			}, func() float64 { return float64(sqlDB.Stats().InUse) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "smart_retention_db_connections_idle",
+				Help: "Conexões ociosas no pool.",
+			}, func() float64 { return float64(sqlDB.Stats().Idle) }),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name: "smart_retention_db_wait_count_total",
+				Help: "Esperas por uma conexão livre no pool.",
+			}, func() float64 { return float64(sqlDB.Stats().WaitCount) }),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name: "smart_retention_db_wait_duration_seconds_total",
+				Help: "Tempo total esperando conexões do pool.",
+			}, func() float64 { return sqlDB.Stats().WaitDuration.Seconds() }),
+		)
+	}
+
+	reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "smart_retention_websocket_clients",
+		Help: "Assinantes conectados ao hub (WebSocket e SSE).",
+	}, func() float64 { return float64(hub.Count()) }))
+
+	duracaoAlertas := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "smart_retention_alert_generation_duration_seconds",
+		Help: "Duração da geração de alertas, por tipos pedidos.",
+	}, []string{"tipos"})
+	alertasPorTipo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "smart_retention_alerts",
+		Help: "Alertas ativos por tipo na última geração completa.",
+	}, []string{"tipo"})
+	reg.MustRegister(duracaoAlertas, alertasPorTipo)
+
+	motor.Observar(func(tipos []string, duracao time.Duration, alertas []alerta.Alerta, err error) {
+		escopo := "todos"
+		if len(tipos) > 0 {
+			escopo = strings.Join(tipos, ",")
+		}
+		duracaoAlertas.WithLabelValues(escopo).Observe(duracao.Seconds())
+
+		if err != nil || len(tipos) > 0 {
+			return
+		}
+		contagem := map[string]int{}
+		for _, a := range alertas {
+			contagem[a.Tipo]++
+		}
+		// Os tipos que sumiram não ficam com o último valor
+		alertasPorTipo.Reset()
+		for tipo, n := range contagem {
+			alertasPorTipo.WithLabelValues(tipo).Set(float64(n))
+		}
+	})
+
+	return metricasApp{Registry: reg, duracaoHTTP: duracaoHTTP}
+}
+
+// migrar executa o subcomando "migrate up|down|status".
+func migrar(dbConn *gorm.DB, args []string) {
+	m, err := db.NewMigrador(dbConn, migrations.FS)
 	if err != nil {
-		log.Fatalf("Erro ao iniciar o servidor: %v", err)
+		fatal("erro ao carregar as migrações", err)
+	}
+
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		n, err := m.Up()
+		if err != nil {
+			fatal("erro ao migrar o banco", err)
+		}
+		slog.Info("migrações aplicadas", "aplicadas", n)
+	case "down":
+		mig, err := m.Down()
+		if err != nil {
+			fatal("erro ao desfazer a migração", err)
+		}
+		if mig == nil {
+			slog.Info("nenhuma migração aplicada")
+			return
+		}
+		slog.Info("migração desfeita", "versao", mig.Versao, "nome", mig.Nome)
+	case "status":
+		status, err := m.Status()
+		if err != nil {
+			fatal("erro ao consultar as migrações", err)
+		}
+		for _, s := range status {
+			aplicada := "pendente"
+			if s.AplicadaEm != nil {
+				aplicada = s.AplicadaEm.Format(time.RFC3339)
+			}
+			fmt.Printf("%d_%s\t%s\n", s.Versao, s.Nome, aplicada)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Uso: %s migrate [up|down|status]\n", os.Args[0])
+		os.Exit(2)
 	}
 }
@@ -0,0 +1,62 @@
+-- Esquema que o AutoMigrate criava antes das migrações versionadas. Bancos
+-- criados pelo AutoMigrate são adotados sem rodar esta migração; as FKs em
+-- cascata vêm das migrações seguintes, como nesses bancos.
+
+-- +goose Up
+CREATE TABLE clientes (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    cnpj text NOT NULL,
+    nome text,
+    telefone text,
+    email text,
+    endereco text,
+    CONSTRAINT clientes_pkey PRIMARY KEY (id),
+    CONSTRAINT uni_clientes_cnpj UNIQUE (cnpj)
+);
+
+CREATE TABLE items (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    nome text,
+    CONSTRAINT items_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE cliente_itens (
+    cliente_id uuid NOT NULL,
+    item_id uuid NOT NULL,
+    CONSTRAINT cliente_itens_pkey PRIMARY KEY (cliente_id, item_id),
+    CONSTRAINT fk_cliente_itens_cliente FOREIGN KEY (cliente_id) REFERENCES clientes(id),
+    CONSTRAINT fk_cliente_itens_item FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE TABLE compras (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    cliente_id uuid,
+    data_compra timestamptz,
+    CONSTRAINT compras_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_compras_cliente FOREIGN KEY (cliente_id) REFERENCES clientes(id)
+);
+
+CREATE TABLE compra_items (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    compra_id uuid,
+    item_id uuid,
+    preco decimal,
+    CONSTRAINT compra_items_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_compras_itens FOREIGN KEY (compra_id) REFERENCES compras(id),
+    CONSTRAINT fk_compra_items_item FOREIGN KEY (item_id) REFERENCES items(id)
+);
+
+CREATE TABLE dia_compra_clientes (
+    cliente_id uuid NOT NULL,
+    dia_semana bigint NOT NULL,
+    CONSTRAINT dia_compra_clientes_pkey PRIMARY KEY (cliente_id, dia_semana),
+    CONSTRAINT fk_clientes_dias_compra FOREIGN KEY (cliente_id) REFERENCES clientes(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dia_compra_clientes;
+DROP TABLE IF EXISTS compra_items;
+DROP TABLE IF EXISTS compras;
+DROP TABLE IF EXISTS cliente_itens;
+DROP TABLE IF EXISTS items;
+DROP TABLE IF EXISTS clientes;
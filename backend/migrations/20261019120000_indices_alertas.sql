@@ -0,0 +1,10 @@
+-- Índices das consultas de alertas por cliente e por item. IF NOT EXISTS
+-- porque o AutoMigrate já os criava nos bancos de desenvolvimento.
+
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_compras_cliente_data ON compras (cliente_id, data_compra);
+CREATE INDEX IF NOT EXISTS idx_compra_items_item_compra ON compra_items (item_id, compra_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_compra_items_item_compra;
+DROP INDEX IF EXISTS idx_compras_cliente_data;
@@ -0,0 +1,14 @@
+-- Regras de alerta escritas pelos usuários.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS regra_alertas (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    nome text NOT NULL,
+    expressao text NOT NULL,
+    motivo text,
+    ativa boolean NOT NULL,
+    CONSTRAINT regra_alertas_pkey PRIMARY KEY (id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS regra_alertas;
@@ -0,0 +1,28 @@
+-- Histórico dos alertas gerados e representante de cada cliente.
+
+-- +goose Up
+ALTER TABLE clientes ADD COLUMN IF NOT EXISTS representante text;
+
+CREATE TABLE IF NOT EXISTS alerta_historicos (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    cliente_id text NOT NULL,
+    tipo text NOT NULL,
+    regra_id text,
+    motivo text,
+    severidade text,
+    prioridade decimal,
+    representante text,
+    gerado_em timestamptz NOT NULL,
+    visto_em timestamptz NOT NULL,
+    recuperado_em timestamptz,
+    compra_id uuid,
+    valor_recuperado decimal,
+    CONSTRAINT alerta_historicos_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerta_historicos_cliente_id ON alerta_historicos (cliente_id);
+CREATE INDEX IF NOT EXISTS idx_alerta_historicos_gerado_em ON alerta_historicos (gerado_em);
+
+-- +goose Down
+DROP TABLE IF EXISTS alerta_historicos;
+ALTER TABLE clientes DROP COLUMN IF EXISTS representante;
@@ -0,0 +1,21 @@
+-- Fila de eventos e estado retido do hub, compartilhados entre instâncias.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS evento_hubs (
+    id bigserial NOT NULL,
+    payload jsonb NOT NULL,
+    criado_em timestamptz NOT NULL,
+    CONSTRAINT evento_hubs_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_evento_hubs_criado_em ON evento_hubs (criado_em);
+
+CREATE TABLE IF NOT EXISTS estado_hubs (
+    chave text NOT NULL,
+    payload jsonb NOT NULL,
+    CONSTRAINT estado_hubs_pkey PRIMARY KEY (chave)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS estado_hubs;
+DROP TABLE IF EXISTS evento_hubs;
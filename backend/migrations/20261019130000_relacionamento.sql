@@ -0,0 +1,41 @@
+-- Contatos do cliente e linha do tempo de interações (notas, ligações,
+-- visitas), com a pausa opcional dos alertas.
+
+-- +goose Up
+CREATE TABLE contatos (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    cliente_id uuid NOT NULL,
+    nome text NOT NULL,
+    cargo text,
+    telefone text,
+    email text,
+    principal boolean NOT NULL DEFAULT false,
+    CONSTRAINT contatos_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_contatos_cliente FOREIGN KEY (cliente_id) REFERENCES clientes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_contatos_cliente_id ON contatos (cliente_id);
+
+CREATE TABLE interacoes (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    cliente_id uuid NOT NULL,
+    contato_id uuid,
+    tipo text NOT NULL,
+    descricao text NOT NULL,
+    resultado text,
+    autor text,
+    data timestamptz NOT NULL,
+    retorno_em timestamptz,
+    pausar_alertas_ate timestamptz,
+    criada_em timestamptz NOT NULL,
+    CONSTRAINT interacoes_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_interacoes_cliente FOREIGN KEY (cliente_id) REFERENCES clientes(id) ON DELETE CASCADE,
+    CONSTRAINT fk_interacoes_contato FOREIGN KEY (contato_id) REFERENCES contatos(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_interacoes_cliente_data ON interacoes (cliente_id, data);
+CREATE INDEX idx_interacoes_pausar_alertas_ate ON interacoes (pausar_alertas_ate) WHERE pausar_alertas_ate IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS interacoes;
+DROP TABLE IF EXISTS contatos;
@@ -0,0 +1,37 @@
+-- Tarefas de acompanhamento, criadas à mão ou a partir dos alertas, e as
+-- regras que as criam automaticamente por tipo de alerta.
+
+-- +goose Up
+CREATE TABLE tarefas (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    cliente_id uuid NOT NULL,
+    alerta_historico_id uuid,
+    tipo_alerta text,
+    titulo text NOT NULL,
+    descricao text,
+    responsavel text,
+    status text NOT NULL,
+    vencimento timestamptz NOT NULL,
+    criada_em timestamptz NOT NULL,
+    concluida_em timestamptz,
+    lembrada_em timestamptz,
+    CONSTRAINT tarefas_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_tarefas_cliente FOREIGN KEY (cliente_id) REFERENCES clientes(id) ON DELETE CASCADE,
+    CONSTRAINT fk_tarefas_alerta_historico FOREIGN KEY (alerta_historico_id) REFERENCES alerta_historicos(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_tarefas_cliente_id ON tarefas (cliente_id);
+CREATE INDEX idx_tarefas_alerta_historico_id ON tarefas (alerta_historico_id);
+CREATE INDEX idx_tarefas_responsavel_status ON tarefas (responsavel, status);
+
+CREATE TABLE regra_tarefas (
+    tipo_alerta text NOT NULL,
+    ativa boolean NOT NULL,
+    prazo_dias bigint NOT NULL,
+    responsavel text,
+    CONSTRAINT regra_tarefas_pkey PRIMARY KEY (tipo_alerta)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS regra_tarefas;
+DROP TABLE IF EXISTS tarefas;
@@ -0,0 +1,30 @@
+-- Feriados cadastrados (os nacionais são calculados pela aplicação) e
+-- períodos em que cada cliente estará fechado, consultados pelos alertas.
+
+-- +goose Up
+CREATE TABLE feriados (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    data timestamptz NOT NULL,
+    nome text NOT NULL,
+    CONSTRAINT feriados_pkey PRIMARY KEY (id),
+    CONSTRAINT uni_feriados_data UNIQUE (data)
+);
+
+CREATE TABLE fechamentos (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    cliente_id uuid NOT NULL,
+    inicio timestamptz NOT NULL,
+    fim timestamptz NOT NULL,
+    motivo text,
+    criado_em timestamptz NOT NULL,
+    CONSTRAINT fechamentos_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_fechamentos_cliente FOREIGN KEY (cliente_id) REFERENCES clientes(id) ON DELETE CASCADE,
+    CONSTRAINT chk_fechamentos_periodo CHECK (fim >= inicio)
+);
+
+CREATE INDEX idx_fechamentos_cliente_id ON fechamentos (cliente_id);
+CREATE INDEX idx_fechamentos_fim ON fechamentos (fim);
+
+-- +goose Down
+DROP TABLE IF EXISTS fechamentos;
+DROP TABLE IF EXISTS feriados;
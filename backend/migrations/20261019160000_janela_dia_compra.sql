@@ -0,0 +1,17 @@
+-- Frequência, horário esperado e tolerância em dias dos dias de compra.
+
+-- +goose Up
+ALTER TABLE dia_compra_clientes
+    ADD COLUMN frequencia text NOT NULL DEFAULT 'semanal',
+    ADD COLUMN referencia timestamptz,
+    ADD COLUMN semana_do_mes bigint NOT NULL DEFAULT 0,
+    ADD COLUMN hora_fim text,
+    ADD COLUMN tolerancia_dias bigint NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE dia_compra_clientes
+    DROP COLUMN tolerancia_dias,
+    DROP COLUMN hora_fim,
+    DROP COLUMN semana_do_mes,
+    DROP COLUMN referencia,
+    DROP COLUMN frequencia;
@@ -0,0 +1,28 @@
+-- Quantidade nos itens de compra e o pedido recorrente de cada cliente:
+-- itens, quantidade e valor esperados, inferidos do histórico ou editados.
+
+-- +goose Up
+ALTER TABLE compra_items ADD COLUMN quantidade decimal NOT NULL DEFAULT 1;
+
+CREATE TABLE pedidos_recorrentes (
+    cliente_id uuid NOT NULL,
+    inferido boolean NOT NULL,
+    atualizado_em timestamptz NOT NULL,
+    CONSTRAINT pedidos_recorrentes_pkey PRIMARY KEY (cliente_id),
+    CONSTRAINT fk_pedidos_recorrentes_cliente FOREIGN KEY (cliente_id) REFERENCES clientes(id) ON DELETE CASCADE
+);
+
+CREATE TABLE pedido_recorrente_itens (
+    cliente_id uuid NOT NULL,
+    item_id uuid NOT NULL,
+    quantidade decimal NOT NULL,
+    preco decimal NOT NULL,
+    CONSTRAINT pedido_recorrente_itens_pkey PRIMARY KEY (cliente_id, item_id),
+    CONSTRAINT fk_pedido_recorrente_itens_pedido FOREIGN KEY (cliente_id) REFERENCES pedidos_recorrentes(cliente_id) ON DELETE CASCADE,
+    CONSTRAINT fk_pedido_recorrente_itens_item FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS pedido_recorrente_itens;
+DROP TABLE IF EXISTS pedidos_recorrentes;
+ALTER TABLE compra_items DROP COLUMN quantidade;
@@ -0,0 +1,8 @@
+-- Clientes que fecham nos pontos facultativos nacionais; os demais abrem
+-- normalmente nesses dias.
+
+-- +goose Up
+ALTER TABLE clientes ADD COLUMN fecha_facultativos boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE clientes DROP COLUMN fecha_facultativos;
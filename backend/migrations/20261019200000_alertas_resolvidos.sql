@@ -0,0 +1,7 @@
+-- Alertas que deixaram de ser gerados sem que o cliente comprasse.
+
+-- +goose Up
+ALTER TABLE alerta_historicos ADD COLUMN resolvido_em timestamptz;
+
+-- +goose Down
+ALTER TABLE alerta_historicos DROP COLUMN resolvido_em;
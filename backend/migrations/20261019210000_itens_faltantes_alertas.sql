@@ -0,0 +1,8 @@
+-- Itens que faltavam em cada alerta de item faltando, para que só uma compra
+-- com um deles recupere o alerta.
+
+-- +goose Up
+ALTER TABLE alerta_historicos ADD COLUMN itens_faltantes jsonb;
+
+-- +goose Down
+ALTER TABLE alerta_historicos DROP COLUMN itens_faltantes;
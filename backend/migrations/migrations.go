@@ -0,0 +1,9 @@
+// Package migrations embute no binário os arquivos SQL de migração. Cada
+// arquivo se chama <versão>_<nome>.sql e tem as seções "-- +goose Up" e
+// "-- +goose Down".
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
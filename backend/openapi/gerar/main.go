@@ -0,0 +1,251 @@
+// Comando gerar escreve o cliente TypeScript do frontend a partir de
+// openapi.json: uma interface por schema e uma função por operação com
+// resposta JSON, sobre axios. Cobre só o subconjunto do OpenAPI usado pela
+// especificação da API.
+//
+//	go run ./gerar -saida ../../frontend/src/api/gerado.ts
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"smart-retention/openapi"
+)
+
+type (
+	especificacao struct {
+		Paths      map[string]map[string]operacao `json:"paths"`
+		Components struct {
+			Schemas map[string]*schema `json:"schemas"`
+		} `json:"components"`
+	}
+
+	operacao struct {
+		OperationID string      `json:"operationId"`
+		Summary     string      `json:"summary"`
+		Parameters  []parametro `json:"parameters"`
+		RequestBody *struct {
+			Content map[string]struct {
+				Schema *schema `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]struct {
+			Content map[string]struct {
+				Schema *schema `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	}
+
+	parametro struct {
+		Nome        string  `json:"name"`
+		Em          string  `json:"in"`
+		Obrigatorio bool    `json:"required"`
+		Schema      *schema `json:"schema"`
+	}
+
+	schema struct {
+		Ref                  string             `json:"$ref"`
+		Type                 string             `json:"type"`
+		Enum                 []string           `json:"enum"`
+		Nullable             bool               `json:"nullable"`
+		Description          string             `json:"description"`
+		Items                *schema            `json:"items"`
+		Properties           map[string]*schema `json:"properties"`
+		Required             []string           `json:"required"`
+		AdditionalProperties *schema            `json:"additionalProperties"`
+	}
+)
+
+// metodos fixa a ordem das operações de um mesmo caminho.
+var metodos = []string{"get", "post", "put", "patch", "delete"}
+
+func main() {
+	saida := flag.String("saida", "", "arquivo .ts gerado (padrão: stdout)")
+	prefixo := flag.String("prefixo", "/api", "prefixo das rotas já incluído em VITE_API_URL; rotas fora dele são ignoradas")
+	flag.Parse()
+
+	var spec especificacao
+	if err := json.Unmarshal(openapi.Especificacao, &spec); err != nil {
+		fmt.Fprintln(os.Stderr, "especificação inválida:", err)
+		os.Exit(1)
+	}
+
+	codigo := gerar(&spec, *prefixo)
+	if *saida == "" {
+		os.Stdout.Write(codigo)
+		return
+	}
+	if err := os.WriteFile(*saida, codigo, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func gerar(spec *especificacao, prefixo string) []byte {
+	var b bytes.Buffer
+	b.WriteString("// Código gerado por backend/openapi/gerar a partir de backend/openapi/openapi.json.\n")
+	b.WriteString("// NÃO EDITE: altere a especificação e rode `go generate ./openapi` em backend/.\n\n")
+	b.WriteString("import axios from 'axios'\n\n")
+	b.WriteString("export const api = axios.create({\n  baseURL: import.meta.env.VITE_API_URL,\n})\n")
+
+	for _, nome := range ordenadas(spec.Components.Schemas) {
+		s := spec.Components.Schemas[nome]
+		b.WriteString("\n")
+		comentario(&b, "", s.Description)
+		if s.Type == "object" && len(s.Properties) > 0 {
+			fmt.Fprintf(&b, "export interface %s %s\n", nome, objeto(s, ""))
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s\n", nome, tipo(s, ""))
+		}
+	}
+
+	for _, caminho := range ordenadas(spec.Paths) {
+		if !strings.HasPrefix(caminho, prefixo+"/") {
+			continue
+		}
+		for _, metodo := range metodos {
+			op, ok := spec.Paths[caminho][metodo]
+			if !ok {
+				continue
+			}
+			if retorno, ok := resposta(op); ok {
+				b.WriteString("\n")
+				funcao(&b, strings.TrimPrefix(caminho, prefixo), metodo, op, retorno)
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+// resposta devolve o tipo da resposta de sucesso, ou false se a operação
+// não responde JSON (WebSocket, SSE, HTML).
+func resposta(op operacao) (string, bool) {
+	for _, status := range ordenadas(op.Responses) {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		if status == "204" {
+			return "void", true
+		}
+		if c, ok := op.Responses[status].Content["application/json"]; ok {
+			return tipo(c.Schema, ""), true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+func funcao(b *bytes.Buffer, caminho, metodo string, op operacao, retorno string) {
+	var args, query []string
+	url := caminho
+	for _, p := range op.Parameters {
+		switch p.Em {
+		case "path":
+			args = append(args, p.Nome+": "+tipo(p.Schema, ""))
+			url = strings.ReplaceAll(url, "{"+p.Nome+"}", "${encodeURIComponent("+p.Nome+")}")
+		case "query":
+			opcional := "?"
+			if p.Obrigatorio {
+				opcional = ""
+			}
+			query = append(query, p.Nome+opcional+": "+tipo(p.Schema, ""))
+		}
+	}
+
+	var chamada []string
+	chamada = append(chamada, "`"+url+"`")
+	if op.RequestBody != nil {
+		args = append(args, "dados: "+tipo(op.RequestBody.Content["application/json"].Schema, ""))
+		chamada = append(chamada, "dados")
+	}
+	if len(query) > 0 {
+		args = append(args, "params: { "+strings.Join(query, "; ")+" } = {}")
+		chamada = append(chamada, "{ params }")
+	}
+
+	comentario(b, "", op.Summary)
+	fmt.Fprintf(b, "export async function %s(%s): Promise<%s> {\n", op.OperationID, strings.Join(args, ", "), retorno)
+	if retorno == "void" {
+		fmt.Fprintf(b, "  await api.%s(%s)\n", metodo, strings.Join(chamada, ", "))
+	} else {
+		fmt.Fprintf(b, "  const res = await api.%s<%s>(%s)\n", metodo, retorno, strings.Join(chamada, ", "))
+		b.WriteString("  return res.data\n")
+	}
+	b.WriteString("}\n")
+}
+
+func tipo(s *schema, recuo string) string {
+	if s == nil {
+		return "unknown"
+	}
+
+	var t string
+	switch {
+	case s.Ref != "":
+		t = s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	case len(s.Enum) > 0:
+		literais := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			literais[i] = "'" + v + "'"
+		}
+		t = strings.Join(literais, " | ")
+	case s.Type == "string":
+		t = "string"
+	case s.Type == "integer", s.Type == "number":
+		t = "number"
+	case s.Type == "boolean":
+		t = "boolean"
+	case s.Type == "array":
+		t = tipo(s.Items, recuo)
+		if strings.Contains(t, " | ") {
+			t = "(" + t + ")"
+		}
+		t += "[]"
+	case s.Type == "object" && len(s.Properties) > 0:
+		t = objeto(s, recuo)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		t = "Record<string, " + tipo(s.AdditionalProperties, recuo) + ">"
+	case s.Type == "object":
+		t = "Record<string, unknown>"
+	default:
+		t = "unknown"
+	}
+
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func objeto(s *schema, recuo string) string {
+	var b bytes.Buffer
+	b.WriteString("{\n")
+	for _, nome := range ordenadas(s.Properties) {
+		p := s.Properties[nome]
+		opcional := "?"
+		if slices.Contains(s.Required, nome) {
+			opcional = ""
+		}
+		comentario(&b, recuo+"  ", p.Description)
+		fmt.Fprintf(&b, "%s  %s%s: %s\n", recuo, nome, opcional, tipo(p, recuo+"  "))
+	}
+	b.WriteString(recuo + "}")
+	return b.String()
+}
+
+func comentario(b *bytes.Buffer, recuo, texto string) {
+	if texto != "" {
+		fmt.Fprintf(b, "%s/** %s */\n", recuo, texto)
+	}
+}
+
+func ordenadas[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
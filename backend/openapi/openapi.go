@@ -0,0 +1,15 @@
+// Package openapi embute no binário a especificação OpenAPI 3 da API e a
+// página do Swagger UI que a apresenta. A especificação é mantida à mão junto
+// com as rotas de main.go; o cliente TypeScript do frontend é gerado a partir
+// dela com go generate.
+package openapi
+
+import _ "embed"
+
+//go:generate go run ./gerar -saida ../../frontend/src/api/gerado.ts
+
+//go:embed openapi.json
+var Especificacao []byte
+
+//go:embed swagger.html
+var SwaggerUI []byte
@@ -7,6 +7,8 @@ import Dashboard from './pages/Dashboard'
 import AlertasDashboard from './pages/AlertasDashboard'
 import ClienteHistorico from "./pages/ClienteHistorico.tsx";
 import EditarCliente from "./pages/EditarCliente.tsx";
+import Tarefas from './pages/Tarefas'
+import Feriados from './pages/Feriados'
 
 
 function App() {
@@ -21,6 +23,8 @@ function App() {
         <Link to="/historico" className="text-blue-500 hover:underline">📜 Histórico de Compras</Link>
         <Link to="/dashboard" className="text-blue-500 hover:underline">📊 Dashboard</Link>
         <Link to="/alertas" className="text-blue-500 hover:underline">🔔 Alertas</Link>
+        <Link to="/tarefas" className="text-blue-500 hover:underline">✅ Tarefas</Link>
+        <Link to="/feriados" className="text-blue-500 hover:underline">📆 Feriados</Link>
       </nav>
 
       <Routes>
@@ -30,6 +34,8 @@ function App() {
         <Route path="/historico" element={<HistoricoCompras />} />
         <Route path="/dashboard" element={<Dashboard />} />
         <Route path="/alertas" element={<AlertasDashboard />} />
+        <Route path="/tarefas" element={<Tarefas />} />
+        <Route path="/feriados" element={<Feriados />} />
         <Route path="/clientes/:id/historico" element={<ClienteHistorico />} />
         <Route path="/clientes/:id" element={<EditarCliente />} />
       </Routes>
@@ -0,0 +1,16 @@
+import axios from 'axios'
+import type { Problema } from './gerado'
+
+// problemaDe devolve o corpo problem+json de uma resposta de erro da API, ou
+// null se o erro não veio da API (rede, timeout, código).
+export function problemaDe(err: unknown): Problema | null {
+  if (axios.isAxiosError<Problema>(err) && err.response?.data?.codigo) {
+    return err.response.data
+  }
+  return null
+}
+
+// errosPorCampo indexa os campos inválidos do problema pelo nome do campo.
+export function errosPorCampo(problema: Problema | null): Record<string, string> {
+  return Object.fromEntries((problema?.campos ?? []).map((c) => [c.campo, c.mensagem]))
+}
@@ -0,0 +1,743 @@
+// Código gerado por backend/openapi/gerar a partir de backend/openapi/openapi.json.
+// NÃO EDITE: altere a especificação e rode `go generate ./openapi` em backend/.
+
+import axios from 'axios'
+
+export const api = axios.create({
+  baseURL: import.meta.env.VITE_API_URL,
+})
+
+export interface Alerta {
+  cliente_id: string
+  dias_atraso?: number
+  itens_detalhados?: ItemDetalhado[]
+  itens_faltantes?: string[]
+  motivo: string
+  nome_cliente: string
+  prioridade: number
+  /** Só em alertas de regras personalizadas. */
+  regra_id?: string
+  representante?: string
+  severidade: Severidade
+  /** Só em alertas de item faltando. */
+  sugestoes?: Sugestao[]
+  /** dia_previsto, inatividade, item_faltando, compra_incompleta ou personalizada. */
+  tipo: string
+  /** Gasto nos últimos 90 dias. */
+  valor_cliente?: number
+}
+
+export interface AnalyticsAlertas {
+  ate: string
+  desde: string
+  por_representante: EfetividadeAlertas[] | null
+  por_tipo: EfetividadeAlertas[] | null
+}
+
+export interface CampoInvalido {
+  campo: string
+  mensagem: string
+}
+
+export interface Cliente {
+  cnpj: string
+  dias_compra: DiaCompra[]
+  email: string
+  endereco: string
+  /** O cliente não abre nos pontos facultativos nacionais, que passam a contar como dias fechados para ele. */
+  fecha_facultativos: boolean
+  id: string
+  itens: Item[]
+  nome: string
+  /** Vendedor responsável pela carteira. */
+  representante: string
+  telefone: string
+}
+
+/** Corpo da criação e da atualização; a atualização substitui todos os dados, inclusive itens e dias de compra. */
+export interface ClienteInput {
+  cnpj: string
+  dias_compra?: DiaCompra[]
+  email?: string
+  endereco: string
+  /** O cliente não abre nos pontos facultativos nacionais, que passam a contar como dias fechados para ele. */
+  fecha_facultativos?: boolean
+  itens?: ItemInput[]
+  nome: string
+  representante?: string
+  telefone: string
+}
+
+export interface ClienteSimulado {
+  cliente_id: string
+  nome_cliente: string
+}
+
+export interface Compra {
+  cliente_id: string
+  data: string
+  id: string
+  itens: CompraItem[]
+  nome_cliente: string
+  /** Soma dos preços dos itens. */
+  total: number
+}
+
+export interface CompraHistorico {
+  data: string
+  itens: CompraItem[]
+}
+
+export interface CompraInput {
+  cliente_id: string
+  /** AAAA-MM-DD. */
+  data: string
+  itens?: CompraItemInput[]
+}
+
+export interface CompraItem {
+  item_id: string
+  nome: string
+  /** Valor da linha, não o unitário. */
+  preco: number
+  quantidade: number
+}
+
+export interface CompraItemInput {
+  item_id: string
+  /** Valor da linha, não o unitário. */
+  preco: number
+  /** Padrão 1. */
+  quantidade?: number
+}
+
+export interface Contato {
+  cargo: string
+  email: string
+  id: string
+  nome: string
+  /** No máximo um contato por cliente é o principal. */
+  principal: boolean
+  telefone: string
+}
+
+export interface ContatoInput {
+  cargo?: string
+  email?: string
+  nome: string
+  /** Marcar um contato como principal desmarca os demais do cliente. */
+  principal?: boolean
+  telefone?: string
+}
+
+export interface Dashboard {
+  clientes_mais_ativos: Quantidade[] | null
+  compras_por_mes: {
+    mes: string
+    quantidade: number
+  }[] | null
+  itens_mais_comprados: Quantidade[] | null
+  total_clientes: number
+  total_compras: number
+}
+
+/** Sem frequência, a compra é semanal. */
+export interface DiaCompra {
+  /** 0 = domingo. */
+  dia_semana: number
+  frequencia?: Frequencia
+  /** Horário até o qual a compra é esperada, HH:MM, no fuso da aplicação. O alerta dia_previsto só sai depois dele no último dia tolerado; sem ele, no dia seguinte. */
+  hora_fim?: string
+  /** Quinzenal: AAAA-MM-DD de um dia de uma semana de compra. */
+  referencia?: string
+  /** Mensal: a semana do mês, de 1 a 4, ou -1 para a última. */
+  semana_do_mes?: number
+  /** Dias antes ou depois do dia em que a compra ainda conta como feita no dia. */
+  tolerancia_dias?: number
+}
+
+export interface EfetividadeAlertas {
+  grupo: string
+  mediana_dias_recuperacao: number | null
+  receita_recuperada: number
+  recuperados: number
+  taxa_recuperacao: number
+  total: number
+}
+
+export interface Evento {
+  /** Conteúdo do evento; depende do tipo. */
+  dados: unknown
+  evento: 'alerta.criado' | 'alerta.alterado' | 'alerta.resolvido' | 'compra.criada' | 'cliente.atualizado' | 'tarefa.criada' | 'tarefa.vencida' | 'alertas.snapshot' | 'assinatura.confirmada' | 'assinatura.cancelada' | 'erro'
+  /** Sequência global; ausente em respostas diretas à conexão. */
+  seq?: number
+}
+
+/** Uma compra ou uma interação; só o campo do tipo correspondente vem preenchido. */
+export interface EventoLinhaDoTempo {
+  compra?: CompraHistorico
+  data: string
+  interacao?: Interacao
+  tipo: 'compra' | 'interacao'
+}
+
+export interface Fechamento {
+  criado_em: string
+  fim: string
+  id: string
+  inicio: string
+  motivo: string
+}
+
+export interface FechamentoInput {
+  /** AAAA-MM-DD, inclusive; igual ou posterior a inicio. */
+  fim: string
+  /** AAAA-MM-DD. */
+  inicio: string
+  motivo?: string
+}
+
+export interface Feriado {
+  data: string
+  /** Ponto facultativo nacional (carnaval, Corpus Christi); só fecha os clientes com fecha_facultativos. */
+  facultativo: boolean
+  /** Só nos feriados cadastrados. */
+  id?: string
+  nacional: boolean
+  nome: string
+}
+
+export interface FeriadoInput {
+  /** AAAA-MM-DD. */
+  data: string
+  nome: string
+}
+
+export type Frequencia = 'semanal' | 'quinzenal' | 'mensal'
+
+export interface HistoricoCliente {
+  cliente: {
+    /** Fim da pausa de alertas em vigor. */
+    alertas_pausados_ate: string | null
+    cnpj: string
+    endereco: string
+    nome: string
+    representante: string
+    telefone: string
+  }
+  contatos: Contato[]
+  /** Da compra mais recente para a mais antiga. */
+  historico: CompraHistorico[]
+  /** Compras e interações intercaladas, da mais recente para a mais antiga. */
+  linha_do_tempo: EventoLinhaDoTempo[]
+}
+
+export interface Incidente {
+  alertas: Alerta[]
+  cliente_id: string
+  nome_cliente: string
+  prioridade: number
+  severidade: Severidade
+  valor_cliente: number
+}
+
+export interface Interacao {
+  /** Quem registrou. */
+  autor: string
+  contato_id: string | null
+  data: string
+  descricao: string
+  id: string
+  nome_contato?: string
+  /** Os alertas do cliente ficam suspensos até este dia, inclusive. */
+  pausar_alertas_ate: string | null
+  /** O que aconteceu ou ficou combinado. */
+  resultado: string
+  /** Data para voltar a falar com o cliente. */
+  retorno_em: string | null
+  tipo: TipoInteracao
+}
+
+export interface InteracaoInput {
+  autor?: string
+  /** Contato do próprio cliente. */
+  contato_id?: string | null
+  /** Quando a interação aconteceu; ausente, agora. */
+  data?: string
+  descricao: string
+  /** AAAA-MM-DD. Suspende todos os alertas do cliente até este dia, inclusive. */
+  pausar_alertas_ate?: string
+  resultado?: string
+  /** AAAA-MM-DD. */
+  retorno_em?: string
+  tipo: TipoInteracao
+}
+
+export interface Item {
+  id: string
+  nome: string
+}
+
+export interface ItemDetalhado {
+  nome: string
+  ultima_compra: string
+}
+
+/** Informe id para usar um item existente ou nome para cadastrar um novo. */
+export interface ItemInput {
+  id?: string
+  nome?: string
+}
+
+export interface ItemPedidoInput {
+  item_id: string
+  /** Valor esperado da linha. */
+  preco: number
+  quantidade: number
+}
+
+export interface PedidoRecorrente {
+  atualizado_em: string
+  /** Calculado das últimas compras, e não editado à mão. */
+  inferido: boolean
+  itens: CompraItem[]
+  total: number
+}
+
+export interface PedidoRecorrenteInput {
+  itens: ItemPedidoInput[]
+}
+
+/** Erro no formato application/problem+json (RFC 7807). */
+export interface Problema {
+  campos?: CampoInvalido[]
+  codigo: 'requisicao_invalida' | 'validacao' | 'nao_encontrado' | 'conflito' | 'referencia_invalida' | 'em_uso' | 'erro_interno'
+  /** Mensagem que pode ser mostrada ao usuário. */
+  detail: string
+  /** Caminho da requisição. */
+  instance: string
+  request_id?: string
+  status: number
+  title: string
+  /** Identifica o tipo do erro, no formato urn:smart-retention:erro:<codigo>. */
+  type: string
+}
+
+export interface Prontidao {
+  checagens?: Record<string, string>
+  status: 'ok' | 'indisponivel' | 'encerrando'
+}
+
+export interface ProximoPedido {
+  /** Último dia tolerado, quando a previsão vem dos dias de compra. */
+  ate: string | null
+  base: 'dias_compra' | 'historico' | null
+  /** Dia esperado do próximo pedido; nulo sem dias de compra nem histórico. */
+  data: string | null
+  pedido: PedidoRecorrente
+}
+
+export interface Quantidade {
+  nome: string
+  quantidade: number
+}
+
+export interface RegraAlerta {
+  ativa: boolean
+  expressao: string
+  id: string
+  motivo: string
+  nome: string
+}
+
+export interface RegraInput {
+  /** Ausente: ativa na criação, mantida na atualização. */
+  ativa?: boolean
+  /** Expressão na linguagem de regras, ex.: dias_sem_compra > 10 && valor_90d > 1000. */
+  expressao: string
+  motivo?: string
+  nome: string
+}
+
+export interface RegraTarefa {
+  ativa: boolean
+  /** Dias entre a criação e o vencimento da tarefa. */
+  prazo_dias: number
+  /** Vazio: o representante do cliente. */
+  responsavel: string
+  tipo_alerta: TipoAlerta
+}
+
+export interface RegraTarefaInput {
+  /** Ausente: ativa. */
+  ativa?: boolean
+  prazo_dias?: number
+  /** Vazio: o representante do cliente. */
+  responsavel?: string
+}
+
+export interface RepetirCompraInput {
+  /** Padrão: hoje. */
+  data?: string
+  /** Padrão: o pedido recorrente salvo ou, sem ele, a última compra. */
+  origem?: 'pedido_recorrente' | 'ultima_compra'
+}
+
+export type Severidade = 'baixa' | 'media' | 'alta' | 'critica'
+
+export interface SimulacaoInput {
+  expressao: string
+}
+
+export interface Status {
+  status: string
+}
+
+export type StatusTarefa = 'aberta' | 'em_andamento' | 'concluida' | 'cancelada'
+
+/** Item que o cliente não compra, mas que clientes com compras parecidas compram. */
+export interface Sugestao {
+  item_id: string
+  /** Explica a sugestão pelo item do cliente que mais contribuiu para ela. */
+  motivo: string
+  nome: string
+  /** Só serve para ordenar. */
+  pontuacao: number
+}
+
+export interface Sugestoes {
+  /** Quando o modelo foi calculado; nulo enquanto não foi. */
+  atualizado_em: string | null
+  sugestoes: Sugestao[]
+}
+
+export interface Tarefa {
+  /** Registro do alerta que originou a tarefa. */
+  alerta_id: string | null
+  cliente_id: string
+  concluida_em: string | null
+  criada_em: string
+  descricao: string
+  id: string
+  nome_cliente: string
+  responsavel: string
+  status: StatusTarefa
+  /** Vazio em tarefas criadas sem alerta. */
+  tipo_alerta: string
+  titulo: string
+  vencimento: string
+}
+
+export interface TarefaAtualizacao {
+  descricao?: string
+  responsavel?: string
+  status: StatusTarefa
+  titulo: string
+  /** AAAA-MM-DD. */
+  vencimento: string
+}
+
+/** Com tipo_alerta, a tarefa é ligada ao alerta em aberto do cliente. */
+export interface TarefaInput {
+  cliente_id: string
+  descricao?: string
+  /** Regra personalizada do alerta, quando tipo_alerta é personalizada. */
+  regra_id?: string
+  responsavel?: string
+  status?: StatusTarefa
+  tipo_alerta?: TipoAlerta
+  /** Obrigatório sem tipo_alerta; com ele, o padrão do tipo. */
+  titulo?: string
+  /** AAAA-MM-DD. */
+  vencimento: string
+}
+
+export type TipoAlerta = 'dia_previsto' | 'inatividade' | 'item_faltando' | 'compra_incompleta' | 'personalizada'
+
+export type TipoInteracao = 'nota' | 'ligacao' | 'visita' | 'email' | 'whatsapp'
+
+/** Verificação simples da API */
+export async function status(): Promise<Status> {
+  const res = await api.get<Status>(`/v1/`)
+  return res.data
+}
+
+/** Todos os alertas ativos, por prioridade */
+export async function listarAlertas(): Promise<Alerta[] | null> {
+  const res = await api.get<Alerta[] | null>(`/v1/alertas`)
+  return res.data
+}
+
+/** Alertas de dia previsto de hoje */
+export async function alertasHoje(): Promise<Alerta[] | null> {
+  const res = await api.get<Alerta[] | null>(`/v1/alertas/hoje`)
+  return res.data
+}
+
+/** Alertas agrupados por cliente, do mais prioritário para o menos */
+export async function listarIncidentes(): Promise<Incidente[]> {
+  const res = await api.get<Incidente[]>(`/v1/alertas/incidentes`)
+  return res.data
+}
+
+/** Efetividade dos alertas por tipo e por representante */
+export async function analyticsAlertas(params: { desde?: string; ate?: string } = {}): Promise<AnalyticsAlertas> {
+  const res = await api.get<AnalyticsAlertas>(`/v1/analytics/alertas`, { params })
+  return res.data
+}
+
+/** Lista os clientes com itens e dias de compra */
+export async function listarClientes(): Promise<Cliente[]> {
+  const res = await api.get<Cliente[]>(`/v1/clientes`)
+  return res.data
+}
+
+/** Cadastra um cliente */
+export async function criarCliente(dados: ClienteInput): Promise<Cliente> {
+  const res = await api.post<Cliente>(`/v1/clientes`, dados)
+  return res.data
+}
+
+/** Busca um cliente */
+export async function buscarCliente(id: string): Promise<Cliente> {
+  const res = await api.get<Cliente>(`/v1/clientes/${encodeURIComponent(id)}`)
+  return res.data
+}
+
+/** Atualiza um cliente */
+export async function atualizarCliente(id: string, dados: ClienteInput): Promise<Cliente> {
+  const res = await api.put<Cliente>(`/v1/clientes/${encodeURIComponent(id)}`, dados)
+  return res.data
+}
+
+/** Remove um cliente e suas compras */
+export async function deletarCliente(id: string): Promise<void> {
+  await api.delete(`/v1/clientes/${encodeURIComponent(id)}`)
+}
+
+/** Registra uma compra igual ao pedido recorrente ou à última compra */
+export async function repetirCompra(id: string, dados: RepetirCompraInput): Promise<Compra> {
+  const res = await api.post<Compra>(`/v1/clientes/${encodeURIComponent(id)}/compras/repetir`, dados)
+  return res.data
+}
+
+/** Lista os contatos do cliente, o principal primeiro */
+export async function listarContatos(id: string): Promise<Contato[]> {
+  const res = await api.get<Contato[]>(`/v1/clientes/${encodeURIComponent(id)}/contatos`)
+  return res.data
+}
+
+/** Cadastra um contato do cliente */
+export async function criarContato(id: string, dados: ContatoInput): Promise<Contato> {
+  const res = await api.post<Contato>(`/v1/clientes/${encodeURIComponent(id)}/contatos`, dados)
+  return res.data
+}
+
+/** Atualiza um contato do cliente */
+export async function atualizarContato(id: string, contato_id: string, dados: ContatoInput): Promise<Contato> {
+  const res = await api.put<Contato>(`/v1/clientes/${encodeURIComponent(id)}/contatos/${encodeURIComponent(contato_id)}`, dados)
+  return res.data
+}
+
+/** Remove um contato; as interações dele ficam sem contato */
+export async function deletarContato(id: string, contato_id: string): Promise<void> {
+  await api.delete(`/v1/clientes/${encodeURIComponent(id)}/contatos/${encodeURIComponent(contato_id)}`)
+}
+
+/** Lista os períodos em que o cliente estará fechado */
+export async function listarFechamentos(id: string): Promise<Fechamento[]> {
+  const res = await api.get<Fechamento[]>(`/v1/clientes/${encodeURIComponent(id)}/fechamentos`)
+  return res.data
+}
+
+/** Informa um período em que o cliente estará fechado */
+export async function criarFechamento(id: string, dados: FechamentoInput): Promise<Fechamento> {
+  const res = await api.post<Fechamento>(`/v1/clientes/${encodeURIComponent(id)}/fechamentos`, dados)
+  return res.data
+}
+
+/** Remove um fechamento do cliente */
+export async function deletarFechamento(id: string, fechamento_id: string): Promise<void> {
+  await api.delete(`/v1/clientes/${encodeURIComponent(id)}/fechamentos/${encodeURIComponent(fechamento_id)}`)
+}
+
+/** Dados do cliente, contatos, compras e interações, da mais recente para a mais antiga */
+export async function historicoCliente(id: string): Promise<HistoricoCliente> {
+  const res = await api.get<HistoricoCliente>(`/v1/clientes/${encodeURIComponent(id)}/historico`)
+  return res.data
+}
+
+/** Lista as interações do cliente, da mais recente para a mais antiga */
+export async function listarInteracoes(id: string): Promise<Interacao[]> {
+  const res = await api.get<Interacao[]>(`/v1/clientes/${encodeURIComponent(id)}/interacoes`)
+  return res.data
+}
+
+/** Registra uma nota, ligação ou visita */
+export async function registrarInteracao(id: string, dados: InteracaoInput): Promise<Interacao> {
+  const res = await api.post<Interacao>(`/v1/clientes/${encodeURIComponent(id)}/interacoes`, dados)
+  return res.data
+}
+
+/** Atualiza uma interação */
+export async function atualizarInteracao(id: string, interacao_id: string, dados: InteracaoInput): Promise<Interacao> {
+  const res = await api.put<Interacao>(`/v1/clientes/${encodeURIComponent(id)}/interacoes/${encodeURIComponent(interacao_id)}`, dados)
+  return res.data
+}
+
+/** Remove uma interação */
+export async function deletarInteracao(id: string, interacao_id: string): Promise<void> {
+  await api.delete(`/v1/clientes/${encodeURIComponent(id)}/interacoes/${encodeURIComponent(interacao_id)}`)
+}
+
+/** Busca o pedido recorrente do cliente */
+export async function buscarPedidoRecorrente(id: string): Promise<PedidoRecorrente> {
+  const res = await api.get<PedidoRecorrente>(`/v1/clientes/${encodeURIComponent(id)}/pedido-recorrente`)
+  return res.data
+}
+
+/** Substitui os itens do pedido recorrente */
+export async function salvarPedidoRecorrente(id: string, dados: PedidoRecorrenteInput): Promise<PedidoRecorrente> {
+  const res = await api.put<PedidoRecorrente>(`/v1/clientes/${encodeURIComponent(id)}/pedido-recorrente`, dados)
+  return res.data
+}
+
+/** Remove o pedido recorrente salvo */
+export async function deletarPedidoRecorrente(id: string): Promise<void> {
+  await api.delete(`/v1/clientes/${encodeURIComponent(id)}/pedido-recorrente`)
+}
+
+/** Recalcula e salva o pedido recorrente a partir das últimas compras */
+export async function inferirPedidoRecorrente(id: string): Promise<PedidoRecorrente> {
+  const res = await api.post<PedidoRecorrente>(`/v1/clientes/${encodeURIComponent(id)}/pedido-recorrente/inferir`)
+  return res.data
+}
+
+/** Prevê quando e o que o cliente deve comprar */
+export async function proximoPedido(id: string): Promise<ProximoPedido> {
+  const res = await api.get<ProximoPedido>(`/v1/clientes/${encodeURIComponent(id)}/proximo-pedido`)
+  return res.data
+}
+
+/** Sugere itens que clientes parecidos compram */
+export async function sugestoesCliente(id: string, params: { limite?: number } = {}): Promise<Sugestoes> {
+  const res = await api.get<Sugestoes>(`/v1/clientes/${encodeURIComponent(id)}/sugestoes`, { params })
+  return res.data
+}
+
+/** Lista as compras */
+export async function listarCompras(): Promise<Compra[]> {
+  const res = await api.get<Compra[]>(`/v1/compras`)
+  return res.data
+}
+
+/** Registra uma compra e fecha os alertas em aberto do cliente */
+export async function criarCompra(dados: CompraInput): Promise<Compra> {
+  const res = await api.post<Compra>(`/v1/compras`, dados)
+  return res.data
+}
+
+/** Resumo de clientes e compras */
+export async function dashboard(): Promise<Dashboard> {
+  const res = await api.get<Dashboard>(`/v1/dashboard`)
+  return res.data
+}
+
+/** Lista os feriados nacionais e cadastrados do ano */
+export async function listarFeriados(params: { ano?: number } = {}): Promise<Feriado[]> {
+  const res = await api.get<Feriado[]>(`/v1/feriados`, { params })
+  return res.data
+}
+
+/** Cadastra um feriado estadual, municipal ou outro dia sem comércio */
+export async function criarFeriado(dados: FeriadoInput): Promise<Feriado> {
+  const res = await api.post<Feriado>(`/v1/feriados`, dados)
+  return res.data
+}
+
+/** Remove um feriado cadastrado */
+export async function deletarFeriado(id: string): Promise<void> {
+  await api.delete(`/v1/feriados/${encodeURIComponent(id)}`)
+}
+
+/** Esta especificação */
+export async function especificacao(): Promise<Record<string, unknown>> {
+  const res = await api.get<Record<string, unknown>>(`/v1/openapi.json`)
+  return res.data
+}
+
+/** Lista as regras personalizadas */
+export async function listarRegras(): Promise<RegraAlerta[]> {
+  const res = await api.get<RegraAlerta[]>(`/v1/regras`)
+  return res.data
+}
+
+/** Cria uma regra personalizada */
+export async function criarRegra(dados: RegraInput): Promise<RegraAlerta> {
+  const res = await api.post<RegraAlerta>(`/v1/regras`, dados)
+  return res.data
+}
+
+/** Clientes que seriam alertados hoje pela expressão, sem salvar */
+export async function simularRegra(dados: SimulacaoInput): Promise<ClienteSimulado[]> {
+  const res = await api.post<ClienteSimulado[]>(`/v1/regras/simular`, dados)
+  return res.data
+}
+
+/** Busca uma regra */
+export async function buscarRegra(id: string): Promise<RegraAlerta> {
+  const res = await api.get<RegraAlerta>(`/v1/regras/${encodeURIComponent(id)}`)
+  return res.data
+}
+
+/** Atualiza uma regra */
+export async function atualizarRegra(id: string, dados: RegraInput): Promise<RegraAlerta> {
+  const res = await api.put<RegraAlerta>(`/v1/regras/${encodeURIComponent(id)}`, dados)
+  return res.data
+}
+
+/** Remove uma regra */
+export async function deletarRegra(id: string): Promise<void> {
+  await api.delete(`/v1/regras/${encodeURIComponent(id)}`)
+}
+
+/** Lista as tarefas, pelo vencimento */
+export async function listarTarefas(params: { responsavel?: string; status?: string; cliente_id?: string } = {}): Promise<Tarefa[]> {
+  const res = await api.get<Tarefa[]>(`/v1/tarefas`, { params })
+  return res.data
+}
+
+/** Cria uma tarefa, opcionalmente a partir de um alerta */
+export async function criarTarefa(dados: TarefaInput): Promise<Tarefa> {
+  const res = await api.post<Tarefa>(`/v1/tarefas`, dados)
+  return res.data
+}
+
+/** Lista as regras de criação automática de tarefas */
+export async function listarRegrasTarefa(): Promise<RegraTarefa[]> {
+  const res = await api.get<RegraTarefa[]>(`/v1/tarefas/regras`)
+  return res.data
+}
+
+/** Cria ou substitui a regra de tarefa do tipo de alerta */
+export async function salvarRegraTarefa(tipo_alerta: TipoAlerta, dados: RegraTarefaInput): Promise<RegraTarefa> {
+  const res = await api.put<RegraTarefa>(`/v1/tarefas/regras/${encodeURIComponent(tipo_alerta)}`, dados)
+  return res.data
+}
+
+/** Remove a regra de tarefa do tipo de alerta */
+export async function deletarRegraTarefa(tipo_alerta: TipoAlerta): Promise<void> {
+  await api.delete(`/v1/tarefas/regras/${encodeURIComponent(tipo_alerta)}`)
+}
+
+/** Busca uma tarefa */
+export async function buscarTarefa(id: string): Promise<Tarefa> {
+  const res = await api.get<Tarefa>(`/v1/tarefas/${encodeURIComponent(id)}`)
+  return res.data
+}
+
+/** Atualiza uma tarefa, inclusive a situação */
+export async function atualizarTarefa(id: string, dados: TarefaAtualizacao): Promise<Tarefa> {
+  const res = await api.put<Tarefa>(`/v1/tarefas/${encodeURIComponent(id)}`, dados)
+  return res.data
+}
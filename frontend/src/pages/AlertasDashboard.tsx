@@ -1,46 +1,83 @@
 import { useEffect, useState } from 'react'
-import axios from 'axios'
 import { Link } from 'react-router-dom'
-
-const api = axios.create({
-  baseURL: import.meta.env.VITE_API_URL,
-})
-
-interface Alerta {
-  cliente_id: string
-  nome_cliente: string
-  tipo: string
-  motivo: string
-  itens_faltantes?: string[]
-    itens_detalhados?: {
-        nome: string
-        ultima_compra: string
-    }[]
-}
+import { listarAlertas, type Alerta, type Evento } from '../api/gerado'
 
 export default function AlertasDashboard() {
   const [alertas, setAlertas] = useState<Alerta[]>([])
 
   useEffect(() => {
-    const socket = new WebSocket("ws://localhost:8080/ws/alertas")
+    let socket: WebSocket | undefined
+    let eventos: EventSource | undefined
+    let ultimoSeq = 0
+    let encerrado = false
+    let reconexao: ReturnType<typeof setTimeout>
+
+    const mesmoAlerta = (a: Alerta, b: Alerta) =>
+      a.cliente_id === b.cliente_id && a.tipo === b.tipo && a.regra_id === b.regra_id
+
+    const tratarEvento = (data: string) => {
+      const { seq, evento, dados } = JSON.parse(data) as Evento
+      if (seq) ultimoSeq = seq
+
+      switch (evento) {
+        case 'alertas.snapshot':
+          setAlertas(dados as Alerta[])
+          break
+        case 'alerta.criado':
+        case 'alerta.alterado':
+          setAlertas(atuais => [...atuais.filter(a => !mesmoAlerta(a, dados as Alerta)), dados as Alerta])
+          break
+        case 'alerta.resolvido':
+          setAlertas(atuais => atuais.filter(a => !mesmoAlerta(a, dados as Alerta)))
+          break
+      }
+    }
 
-    socket.onmessage = (event) => {
-      const novosAlertas = JSON.parse(event.data)
-      setAlertas(novosAlertas)
+    // Fallback para proxies que bloqueiam o upgrade de WebSocket; o
+    // EventSource reconecta sozinho enviando Last-Event-ID
+    const conectarSSE = () => {
+      const desde = ultimoSeq > 0 ? `?desde=${ultimoSeq}` : ''
+      eventos = new EventSource(`${import.meta.env.VITE_API_URL}/v1/eventos${desde}`)
+      eventos.onmessage = (event) => tratarEvento(event.data)
     }
 
-    socket.onerror = (err) => {
-      console.error("Erro no WebSocket:", err)
+    const conectar = () => {
+      // Ao reconectar, informa o último evento recebido para receber só o que perdeu
+      const desde = ultimoSeq > 0 ? `?desde=${ultimoSeq}` : ''
+      const ws = new WebSocket(`${import.meta.env.VITE_API_URL.replace(/^http/, 'ws')}/v1/ws/alertas${desde}`)
+      let abriu = false
+      socket = ws
+
+      ws.onopen = () => {
+        abriu = true
+      }
+
+      ws.onmessage = (event) => tratarEvento(event.data)
+
+      ws.onerror = (err) => {
+        console.error("Erro no WebSocket:", err)
+      }
+
+      ws.onclose = () => {
+        if (encerrado) return
+        if (abriu) reconexao = setTimeout(conectar, 3000)
+        else conectarSSE()
+      }
     }
 
+    conectar()
+
     const fetchFallback = () => {
-      api.get('/alertas').then(res => setAlertas(res.data))
+      listarAlertas().then(dados => setAlertas(dados ?? []))
     }
 
     const interval = setInterval(fetchFallback, 30000) // fallback a cada 30s
 
     return () => {
-      socket.close()
+      encerrado = true
+      clearTimeout(reconexao)
+      socket?.close()
+      eventos?.close()
       clearInterval(interval)
     }
   }, [])
@@ -117,6 +154,11 @@ export default function AlertasDashboard() {
                           </li>
                       ))}
                     </ul>
+                    {a.sugestoes && a.sugestoes.length > 0 && (
+                        <p className="text-sm mt-2 text-gray-700" title={a.sugestoes.map(s => s.motivo).join('\n')}>
+                          Oferecer também: {a.sugestoes.map(s => s.nome).join(', ')}
+                        </p>
+                    )}
                   </li>
               ))}
             </ul>
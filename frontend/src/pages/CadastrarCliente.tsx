@@ -1,10 +1,7 @@
 import { useState } from 'react'
 import { useNavigate } from 'react-router-dom'
-import axios from 'axios'
-
-const api = axios.create({
-  baseURL: import.meta.env.VITE_API_URL,
-})
+import { criarCliente } from '../api/gerado'
+import { errosPorCampo, problemaDe } from '../api/erros'
 
 const diasSemana = ['Dom', 'Seg', 'Ter', 'Qua', 'Qui', 'Sex', 'Sab']
 
@@ -16,6 +13,8 @@ export default function CadastrarCliente() {
     telefone: '',
     email: '',
     endereco: '',
+    representante: '',
+    fecha_facultativos: false,
     itens: [''],
     dias_compra: [] as number[],
   })
@@ -71,15 +70,19 @@ export default function CadastrarCliente() {
       telefone: form.telefone,
       email: form.email,
       endereco: form.endereco,
+      representante: form.representante,
+      fecha_facultativos: form.fecha_facultativos,
       itens: form.itens.filter(i => i.trim() !== '').map(i => ({ nome: i })),
       dias_compra: form.dias_compra.map(d => ({ dia_semana: d })),
     }
 
     try {
-      await api.post(`/clientes`, payload)
+      await criarCliente(payload)
       navigate('/')
     } catch (err) {
-      setErroServidor('Erro ao cadastrar cliente')
+      const problema = problemaDe(err)
+      setErrors(errosPorCampo(problema))
+      setErroServidor(problema?.detail ?? 'Erro ao cadastrar cliente')
       console.error(err)
     }
   }
@@ -137,6 +140,22 @@ export default function CadastrarCliente() {
           {errors.endereco && <p className="text-red-500 text-sm">{errors.endereco}</p>}
         </div>
 
+        <input
+            className="w-full p-2 border rounded"
+            placeholder="Representante (opcional)"
+            value={form.representante}
+            onChange={(e) => setForm({ ...form, representante: e.target.value })}
+        />
+
+        <label className="flex items-center gap-2">
+          <input
+              type="checkbox"
+              checked={form.fecha_facultativos}
+              onChange={(e) => setForm({ ...form, fecha_facultativos: e.target.checked })}
+          />
+          Fecha nos pontos facultativos (carnaval, Corpus Christi)
+        </label>
+
         <div>
           <label className="block mb-1 font-semibold">Itens que costuma comprar:</label>
           {form.itens.map((item, index) => (
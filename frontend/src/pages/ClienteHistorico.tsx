@@ -1,68 +1,400 @@
-import { useEffect, useState } from "react";
+import { useCallback, useEffect, useState } from "react";
 import {Link, useParams} from "react-router-dom";
-import axios from "axios";
+import {
+    criarContato,
+    criarFechamento,
+    deletarFechamento,
+    historicoCliente,
+    inferirPedidoRecorrente,
+    listarFechamentos,
+    proximoPedido,
+    registrarInteracao,
+    repetirCompra,
+    sugestoesCliente,
+    type Fechamento,
+    type HistoricoCliente,
+    type ProximoPedido,
+    type Sugestao,
+    type TipoInteracao,
+} from "../api/gerado";
+import { errosPorCampo, problemaDe } from "../api/erros";
 
-interface Cliente {
-    nome: string;
-    cnpj: string;
-    telefone: string;
-    endereco: string;
+const tiposInteracao: Record<TipoInteracao, string> = {
+    nota: 'Nota',
+    ligacao: 'Ligação',
+    visita: 'Visita',
+    email: 'E-mail',
+    whatsapp: 'WhatsApp',
 }
 
-interface ItemCompra {
-    nome: string;
-    preco: number;
+const interacaoVazia = {
+    tipo: 'ligacao' as TipoInteracao,
+    contato_id: '',
+    descricao: '',
+    resultado: '',
+    retorno_em: '',
+    pausar_alertas_ate: '',
 }
 
-interface Compra {
-    data: string;
-    itens: ItemCompra[];
-}
+const contatoVazio = { nome: '', cargo: '', telefone: '', principal: false }
+
+const fechamentoVazio = { inicio: '', fim: '', motivo: '' }
 
 export default function ClienteHistorico() {
     const { id } = useParams<{ id: string }>()
-    const [cliente, setCliente] = useState<Cliente | null>(null)
-    const [compras, setCompras] = useState<Compra[]>([])
+    const [historico, setHistorico] = useState<HistoricoCliente | null>(null)
     const [carregando, setCarregando] = useState(true)
+    const [interacao, setInteracao] = useState(interacaoVazia)
+    const [contato, setContato] = useState(contatoVazio)
+    const [fechamentos, setFechamentos] = useState<Fechamento[]>([])
+    const [fechamento, setFechamento] = useState(fechamentoVazio)
+    const [proximo, setProximo] = useState<ProximoPedido | null>(null)
+    const [sugestoes, setSugestoes] = useState<Sugestao[]>([])
+    const [erros, setErros] = useState<Record<string, string>>({})
+    const [erroServidor, setErroServidor] = useState<string | null>(null)
 
-    useEffect(() => {
-        axios.get(`${import.meta.env.VITE_API_URL}/clientes/${id}/historico`)
-            .then(res => {
-                setCliente(res.data.cliente)
-                setCompras(res.data.historico)
+    const carregar = useCallback(() => {
+        return Promise.all([historicoCliente(id!), listarFechamentos(id!), proximoPedido(id!), sugestoesCliente(id!)])
+            .then(([h, f, p, s]) => {
+                setHistorico(h)
+                setFechamentos(f)
+                setProximo(p)
+                setSugestoes(s.sugestoes)
             })
             .finally(() => setCarregando(false))
     }, [id])
 
+    useEffect(() => {
+        carregar()
+    }, [carregar])
+
     const formatarData = (iso: string) => {
         const data = new Date(iso)
         return data.toLocaleDateString('pt-BR', { timeZone: 'UTC' })
     }
 
+    const formatarDataHora = (iso: string) => {
+        return new Date(iso).toLocaleString('pt-BR', { dateStyle: 'short', timeStyle: 'short' })
+    }
+
+    const tratarErro = (err: unknown, padrao: string) => {
+        const problema = problemaDe(err)
+        setErros(errosPorCampo(problema))
+        setErroServidor(problema?.detail ?? padrao)
+        console.error(err)
+    }
+
+    const enviarInteracao = async (e: React.FormEvent) => {
+        e.preventDefault()
+        setErros({})
+        setErroServidor(null)
+        try {
+            await registrarInteracao(id!, {
+                tipo: interacao.tipo,
+                descricao: interacao.descricao,
+                resultado: interacao.resultado,
+                contato_id: interacao.contato_id || null,
+                retorno_em: interacao.retorno_em || undefined,
+                pausar_alertas_ate: interacao.pausar_alertas_ate || undefined,
+            })
+            setInteracao(interacaoVazia)
+            await carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao registrar interação')
+        }
+    }
+
+    const enviarContato = async (e: React.FormEvent) => {
+        e.preventDefault()
+        setErros({})
+        setErroServidor(null)
+        try {
+            await criarContato(id!, contato)
+            setContato(contatoVazio)
+            await carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao cadastrar contato')
+        }
+    }
+
+    const enviarFechamento = async (e: React.FormEvent) => {
+        e.preventDefault()
+        setErros({})
+        setErroServidor(null)
+        try {
+            await criarFechamento(id!, fechamento)
+            setFechamento(fechamentoVazio)
+            await carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao registrar fechamento')
+        }
+    }
+
+    const removerFechamento = async (fechamentoId: string) => {
+        try {
+            await deletarFechamento(id!, fechamentoId)
+            await carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao remover fechamento')
+        }
+    }
+
+    const repetir = async () => {
+        setErroServidor(null)
+        try {
+            await repetirCompra(id!, { origem: 'pedido_recorrente' })
+            await carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao repetir pedido')
+        }
+    }
+
+    const salvarPedidoInferido = async () => {
+        setErroServidor(null)
+        try {
+            await inferirPedidoRecorrente(id!)
+            await carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao salvar pedido recorrente')
+        }
+    }
+
+    const moeda = (valor: number) => valor.toLocaleString('pt-BR', { style: 'currency', currency: 'BRL' })
+
     if (carregando) return <p className="p-4">Carregando...</p>
+    if (!historico) return <p className="p-4">Cliente não encontrado.</p>
+
+    const { cliente, contatos, linha_do_tempo } = historico
 
     return (
         <div className="max-w-3xl mx-auto p-6">
             <Link to="/" className="text-blue-600 hover:underline text-sm mb-4 inline-block">
                 ← Voltar para a Home
             </Link>
-            <h1 className="text-2xl font-bold mb-4">Histórico de {cliente?.nome}</h1>
+            <h1 className="text-2xl font-bold mb-4">Histórico de {cliente.nome}</h1>
             <div className="mb-4 text-sm text-gray-700">
-                <p><strong>CNPJ:</strong> {cliente?.cnpj}</p>
-                <p><strong>Telefone:</strong> {cliente?.telefone}</p>
-                <p><strong>Endereço:</strong> {cliente?.endereco}</p>
+                <p><strong>CNPJ:</strong> {cliente.cnpj}</p>
+                <p><strong>Telefone:</strong> {cliente.telefone}</p>
+                <p><strong>Endereço:</strong> {cliente.endereco}</p>
             </div>
 
-            <h2 className="text-xl font-semibold mb-2">Compras</h2>
-            {compras.length === 0 ? (
-                <p className="text-gray-500">Nenhuma compra registrada.</p>
+            {cliente.alertas_pausados_ate && (
+                <p className="mb-4 p-3 rounded bg-yellow-100 text-yellow-800 text-sm">
+                    Alertas pausados até {formatarData(cliente.alertas_pausados_ate)}.
+                </p>
+            )}
+
+            {erroServidor && <p className="text-red-600 mb-4">{erroServidor}</p>}
+
+            <h2 className="text-xl font-semibold mb-2">Contatos</h2>
+            {contatos.length === 0 ? (
+                <p className="text-gray-500 mb-2">Nenhum contato cadastrado.</p>
+            ) : (
+                <ul className="mb-2 text-sm space-y-1">
+                    {contatos.map(c => (
+                        <li key={c.id}>
+                            <strong>{c.nome}</strong>
+                            {c.cargo && ` (${c.cargo})`}
+                            {c.telefone && ` – ${c.telefone}`}
+                            {c.principal && <span className="ml-2 text-xs text-blue-700">principal</span>}
+                        </li>
+                    ))}
+                </ul>
+            )}
+            <form onSubmit={enviarContato} className="flex flex-wrap gap-2 mb-6 text-sm">
+                <input
+                    className={`p-2 border rounded ${erros.nome ? "border-red-500" : ""}`}
+                    placeholder="Nome"
+                    value={contato.nome}
+                    onChange={e => setContato({ ...contato, nome: e.target.value })}
+                />
+                <input
+                    className="p-2 border rounded"
+                    placeholder="Cargo"
+                    value={contato.cargo}
+                    onChange={e => setContato({ ...contato, cargo: e.target.value })}
+                />
+                <input
+                    className="p-2 border rounded"
+                    placeholder="Telefone"
+                    value={contato.telefone}
+                    onChange={e => setContato({ ...contato, telefone: e.target.value })}
+                />
+                <label className="flex items-center gap-1">
+                    <input
+                        type="checkbox"
+                        checked={contato.principal}
+                        onChange={e => setContato({ ...contato, principal: e.target.checked })}
+                    />
+                    Principal
+                </label>
+                <button type="submit" className="px-3 py-2 bg-blue-600 text-white rounded">Adicionar contato</button>
+            </form>
+
+            {proximo && (
+                <>
+                    <h2 className="text-xl font-semibold mb-2">Pedido recorrente</h2>
+                    <p className="text-sm text-gray-700 mb-2">
+                        {proximo.data
+                            ? <>Próximo pedido esperado em <strong>{formatarData(proximo.data)}</strong>
+                                {proximo.ate && proximo.ate !== proximo.data && ` (até ${formatarData(proximo.ate)})`}
+                                {proximo.base === 'historico' && ' pelo intervalo entre as últimas compras'}.</>
+                            : 'Sem dias de compra nem compras suficientes para prever o próximo pedido.'}
+                    </p>
+                    {proximo.pedido.itens.length === 0 ? (
+                        <p className="text-gray-500 text-sm mb-6">Nenhum item recorrente nas últimas compras.</p>
+                    ) : (
+                        <div className="mb-6 text-sm">
+                            {proximo.pedido.inferido && (
+                                <p className="text-gray-500 mb-1">Inferido das últimas compras.</p>
+                            )}
+                            <ul className="list-disc list-inside mb-2">
+                                {proximo.pedido.itens.map(item => (
+                                    <li key={item.item_id}>
+                                        {item.quantidade} × {item.nome} – {moeda(item.preco)}
+                                    </li>
+                                ))}
+                            </ul>
+                            <p className="mb-2"><strong>Total:</strong> {moeda(proximo.pedido.total)}</p>
+                            <div className="flex gap-2">
+                                <button type="button" className="px-3 py-2 bg-blue-600 text-white rounded" onClick={repetir}>
+                                    Repetir pedido
+                                </button>
+                                {proximo.pedido.inferido && (
+                                    <button type="button" className="px-3 py-2 border rounded" onClick={salvarPedidoInferido}>
+                                        Salvar como pedido recorrente
+                                    </button>
+                                )}
+                            </div>
+                        </div>
+                    )}
+                </>
+            )}
+
+            {sugestoes.length > 0 && (
+                <>
+                    <h2 className="text-xl font-semibold mb-2">Sugestões</h2>
+                    <ul className="mb-6 text-sm space-y-1">
+                        {sugestoes.map(s => (
+                            <li key={s.item_id}>
+                                <strong>{s.nome}</strong> – <span className="text-gray-600">{s.motivo}</span>
+                            </li>
+                        ))}
+                    </ul>
+                </>
+            )}
+
+            <h2 className="text-xl font-semibold mb-2">Fechamentos</h2>
+            <p className="text-sm text-gray-500 mb-2">
+                Períodos em que o cliente avisou que estará fechado: o dia previsto passa para o primeiro dia aberto e não há alerta de inatividade.
+            </p>
+            {fechamentos.length > 0 && (
+                <ul className="mb-2 text-sm space-y-1">
+                    {fechamentos.map(f => (
+                        <li key={f.id}>
+                            {formatarData(f.inicio)} a {formatarData(f.fim)}
+                            {f.motivo && ` – ${f.motivo}`}
+                            <button
+                                type="button"
+                                className="ml-2 text-red-600 hover:underline"
+                                onClick={() => removerFechamento(f.id)}
+                            >
+                                remover
+                            </button>
+                        </li>
+                    ))}
+                </ul>
+            )}
+            <form onSubmit={enviarFechamento} className="flex flex-wrap gap-2 mb-6 text-sm">
+                <input
+                    type="date"
+                    className={`p-2 border rounded ${erros.inicio ? "border-red-500" : ""}`}
+                    value={fechamento.inicio}
+                    onChange={e => setFechamento({ ...fechamento, inicio: e.target.value })}
+                />
+                <input
+                    type="date"
+                    className={`p-2 border rounded ${erros.fim ? "border-red-500" : ""}`}
+                    value={fechamento.fim}
+                    onChange={e => setFechamento({ ...fechamento, fim: e.target.value })}
+                />
+                <input
+                    className="p-2 border rounded"
+                    placeholder="Motivo"
+                    value={fechamento.motivo}
+                    onChange={e => setFechamento({ ...fechamento, motivo: e.target.value })}
+                />
+                <button type="submit" className="px-3 py-2 bg-blue-600 text-white rounded">Adicionar fechamento</button>
+            </form>
+            {erros.fim && <p className="text-red-500 text-sm -mt-4 mb-4">{erros.fim}</p>}
+
+            <h2 className="text-xl font-semibold mb-2">Registrar interação</h2>
+            <form onSubmit={enviarInteracao} className="space-y-2 mb-6 text-sm border p-4 rounded bg-white shadow">
+                <div className="flex gap-2">
+                    <select
+                        className="p-2 border rounded"
+                        value={interacao.tipo}
+                        onChange={e => setInteracao({ ...interacao, tipo: e.target.value as TipoInteracao })}
+                    >
+                        {Object.entries(tiposInteracao).map(([valor, rotulo]) => (
+                            <option key={valor} value={valor}>{rotulo}</option>
+                        ))}
+                    </select>
+                    <select
+                        className="p-2 border rounded"
+                        value={interacao.contato_id}
+                        onChange={e => setInteracao({ ...interacao, contato_id: e.target.value })}
+                    >
+                        <option value="">Sem contato</option>
+                        {contatos.map(c => <option key={c.id} value={c.id}>{c.nome}</option>)}
+                    </select>
+                </div>
+                <textarea
+                    className={`w-full p-2 border rounded ${erros.descricao ? "border-red-500" : ""}`}
+                    placeholder="O que foi conversado"
+                    value={interacao.descricao}
+                    onChange={e => setInteracao({ ...interacao, descricao: e.target.value })}
+                />
+                {erros.descricao && <p className="text-red-500">{erros.descricao}</p>}
+                <input
+                    className="w-full p-2 border rounded"
+                    placeholder="Resultado"
+                    value={interacao.resultado}
+                    onChange={e => setInteracao({ ...interacao, resultado: e.target.value })}
+                />
+                <div className="flex gap-4">
+                    <label>
+                        Retornar em{' '}
+                        <input
+                            type="date"
+                            className="p-1 border rounded"
+                            value={interacao.retorno_em}
+                            onChange={e => setInteracao({ ...interacao, retorno_em: e.target.value })}
+                        />
+                    </label>
+                    <label>
+                        Pausar alertas até{' '}
+                        <input
+                            type="date"
+                            className="p-1 border rounded"
+                            value={interacao.pausar_alertas_ate}
+                            onChange={e => setInteracao({ ...interacao, pausar_alertas_ate: e.target.value })}
+                        />
+                    </label>
+                </div>
+                <button type="submit" className="px-3 py-2 bg-blue-600 text-white rounded">Registrar</button>
+            </form>
+
+            <h2 className="text-xl font-semibold mb-2">Linha do tempo</h2>
+            {linha_do_tempo.length === 0 ? (
+                <p className="text-gray-500">Nenhuma compra ou interação registrada.</p>
             ) : (
                 <ul className="space-y-6">
-                    {compras.map((compra, i) => (
+                    {linha_do_tempo.map((evento, i) => evento.compra ? (
                         <li key={i} className="border p-4 rounded bg-white shadow">
-                            <p className="text-sm text-gray-600">{formatarData(compra.data)}</p>
+                            <p className="text-sm text-gray-600">Compra em {formatarData(evento.data)}</p>
                             <ul className="list-disc ml-5 mt-2 text-sm">
-                                {compra.itens.map((item, j) => (
+                                {evento.compra.itens.map((item, j) => (
                                     <li key={j}>
                                         {item.nome} – R$ {item.preco.toLocaleString("pt-BR", {
                                         minimumFractionDigits: 2,
@@ -72,6 +404,19 @@ export default function ClienteHistorico() {
                                 ))}
                             </ul>
                         </li>
+                    ) : evento.interacao && (
+                        <li key={i} className="border-l-4 border-blue-400 p-4 rounded bg-blue-50 text-sm">
+                            <p className="text-gray-600">
+                                {tiposInteracao[evento.interacao.tipo]} em {formatarDataHora(evento.data)}
+                                {evento.interacao.nome_contato && ` com ${evento.interacao.nome_contato}`}
+                            </p>
+                            <p className="mt-1">{evento.interacao.descricao}</p>
+                            {evento.interacao.resultado && <p className="mt-1"><strong>Resultado:</strong> {evento.interacao.resultado}</p>}
+                            {evento.interacao.retorno_em && <p><strong>Retornar em:</strong> {formatarData(evento.interacao.retorno_em)}</p>}
+                            {evento.interacao.pausar_alertas_ate && (
+                                <p><strong>Alertas pausados até:</strong> {formatarData(evento.interacao.pausar_alertas_ate)}</p>
+                            )}
+                        </li>
                     ))}
                 </ul>
             )}
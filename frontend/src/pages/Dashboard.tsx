@@ -1,29 +1,17 @@
 import { useEffect, useState } from 'react'
-import axios from 'axios'
 import {
   LineChart, Line, XAxis, YAxis, Tooltip, ResponsiveContainer,
   BarChart, Bar, PieChart, Pie, Cell, Legend
 } from 'recharts'
-
-const api = axios.create({
-  baseURL: import.meta.env.VITE_API_URL,
-})
+import { dashboard, type Dashboard } from '../api/gerado'
 
 const COLORS = ['#8884d8', '#82ca9d', '#ffc658', '#ff7f50', '#ffbb28']
 
-interface Dashboard {
-  total_clientes: number
-  total_compras: number
-  compras_por_mes: { mes: string, quantidade: number }[]
-  itens_mais_comprados: { nome: string, quantidade: number }[]
-  clientes_mais_ativos: { nome: string, quantidade: number }[]
-}
-
-export default function Dashboard() {
+export default function DashboardPage() {
   const [data, setData] = useState<Dashboard | null>(null)
 
   useEffect(() => {
-    api.get('/dashboard').then((res) => setData(res.data))
+    dashboard().then(setData)
   }, [])
 
   if (!data) return <p className="text-center mt-10">Carregando dashboard...</p>
@@ -47,7 +35,7 @@ export default function Dashboard() {
         <div>
           <h3 className="text-lg font-semibold mb-2">📆 Compras por Mês</h3>
           <ResponsiveContainer width="100%" height={250}>
-            <LineChart data={data.compras_por_mes}>
+            <LineChart data={data.compras_por_mes ?? []}>
               <XAxis dataKey="mes" />
               <YAxis />
               <Tooltip />
@@ -59,7 +47,7 @@ export default function Dashboard() {
         <div>
           <h3 className="text-lg font-semibold mb-2">🔥 Clientes Mais Ativos</h3>
           <ResponsiveContainer width="100%" height={250}>
-            <BarChart data={data.clientes_mais_ativos}>
+            <BarChart data={data.clientes_mais_ativos ?? []}>
               <XAxis dataKey="nome" />
               <YAxis />
               <Tooltip />
@@ -74,7 +62,7 @@ export default function Dashboard() {
         <ResponsiveContainer width="100%" height={300}>
           <PieChart>
             <Pie
-              data={data.itens_mais_comprados}
+              data={data.itens_mais_comprados ?? []}
               dataKey="quantidade"
               nameKey="nome"
               cx="50%"
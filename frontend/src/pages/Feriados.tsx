@@ -0,0 +1,99 @@
+import { useCallback, useEffect, useState } from "react";
+import { criarFeriado, deletarFeriado, listarFeriados, type Feriado } from "../api/gerado";
+import { errosPorCampo, problemaDe } from "../api/erros";
+
+export default function Feriados() {
+    const [ano, setAno] = useState(new Date().getFullYear())
+    const [feriados, setFeriados] = useState<Feriado[]>([])
+    const [novo, setNovo] = useState({ data: '', nome: '' })
+    const [erros, setErros] = useState<Record<string, string>>({})
+    const [erroServidor, setErroServidor] = useState<string | null>(null)
+
+    const carregar = useCallback(() => {
+        listarFeriados({ ano }).then(setFeriados)
+    }, [ano])
+
+    useEffect(() => {
+        carregar()
+    }, [carregar])
+
+    const tratarErro = (err: unknown, padrao: string) => {
+        const problema = problemaDe(err)
+        setErros(errosPorCampo(problema))
+        setErroServidor(problema?.detail ?? padrao)
+        console.error(err)
+    }
+
+    const enviar = async (e: React.FormEvent) => {
+        e.preventDefault()
+        setErros({})
+        setErroServidor(null)
+        try {
+            await criarFeriado(novo)
+            setNovo({ data: '', nome: '' })
+            carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao cadastrar feriado')
+        }
+    }
+
+    const remover = async (id: string) => {
+        try {
+            await deletarFeriado(id)
+            carregar()
+        } catch (err) {
+            tratarErro(err, 'Erro ao remover feriado')
+        }
+    }
+
+    return (
+        <div className="max-w-3xl mx-auto">
+            <div className="flex justify-between items-center mb-4">
+                <h2 className="text-2xl font-semibold">📆 Feriados</h2>
+                <div className="flex items-center gap-2 text-sm">
+                    <button type="button" className="px-2 border rounded" onClick={() => setAno(ano - 1)}>‹</button>
+                    <span>{ano}</span>
+                    <button type="button" className="px-2 border rounded" onClick={() => setAno(ano + 1)}>›</button>
+                </div>
+            </div>
+            <p className="text-sm text-gray-500 mb-4">
+                Nos feriados nenhum cliente é cobrado: o dia previsto passa para o dia seguinte e o dia não conta como inatividade.
+            </p>
+
+            {erroServidor && <p className="text-red-600 mb-4">{erroServidor}</p>}
+
+            <form onSubmit={enviar} className="flex flex-wrap gap-2 mb-6 text-sm">
+                <input
+                    type="date"
+                    className={`p-2 border rounded ${erros.data ? "border-red-500" : ""}`}
+                    value={novo.data}
+                    onChange={e => setNovo({ ...novo, data: e.target.value })}
+                />
+                <input
+                    className={`p-2 border rounded ${erros.nome ? "border-red-500" : ""}`}
+                    placeholder="Nome (ex.: Aniversário da cidade)"
+                    value={novo.nome}
+                    onChange={e => setNovo({ ...novo, nome: e.target.value })}
+                />
+                <button type="submit" className="px-3 py-2 bg-blue-600 text-white rounded">Cadastrar feriado</button>
+            </form>
+
+            <ul className="space-y-1 text-sm">
+                {feriados.map(f => (
+                    <li key={f.id ?? f.data + f.nome} className="flex gap-2">
+                        <span className="w-24">{new Date(f.data).toLocaleDateString('pt-BR', { timeZone: 'UTC' })}</span>
+                        <span>{f.nome}</span>
+                        {f.nacional && (
+                            <span className="text-xs text-gray-500">{f.facultativo ? 'ponto facultativo (só clientes que fecham nele)' : 'nacional'}</span>
+                        )}
+                        {f.id && (
+                            <button type="button" className="text-red-600 hover:underline" onClick={() => remover(f.id!)}>
+                                remover
+                            </button>
+                        )}
+                    </li>
+                ))}
+            </ul>
+        </div>
+    )
+}
@@ -1,22 +1,11 @@
 import { useEffect, useState } from 'react'
-import axios from 'axios'
-
-const api = axios.create({
-  baseURL: import.meta.env.VITE_API_URL,
-})
-
-interface Compra {
-  id: string
-  data: string
-  nome_cliente: string
-  itens: { nome: string; preco?: number }[]
-}
+import { listarCompras, type Compra } from '../api/gerado'
 
 export default function HistoricoCompras() {
   const [compras, setCompras] = useState<Compra[]>([])
 
   useEffect(() => {
-    api.get('/compras').then((res) => setCompras(res.data))
+    listarCompras().then(setCompras)
   }, [])
 
   const formatarData = (iso: string) => {
@@ -37,9 +26,9 @@ export default function HistoricoCompras() {
               <p className="font-bold text-lg">🧑‍🍳 {compra.nome_cliente}</p>
               <p className="text-sm">
                 🛒 Itens:
-                {compra.itens.map((i, index) => (
+                {compra.itens.map((i, index, itens) => (
                   <span key={index}>
-                    {i.nome}{i.preco !== undefined ? ` (${i.preco.toFixed(2)})` : ''}{index < compra.itens.length - 1 ? ', ' : ''}
+                    {i.quantidade !== 1 && `${i.quantidade} × `}{i.nome} ({i.preco.toFixed(2)}){index < itens.length - 1 ? ', ' : ''}
                   </span>
                 ))}
               </p>
@@ -1,43 +1,21 @@
 import { useEffect, useState } from 'react'
-import axios from 'axios'
 import {Link} from "react-router-dom";
-
-const api = axios.create({
-  baseURL: import.meta.env.VITE_API_URL,
-})
-
-interface Cliente {
-  id: string
-  nome: string
-  cnpj: string
-  telefone: string
-  email: string
-  endereco: string
-  dias_compra: { dia_semana: number }[]
-  itens: { nome: string }[]
-}
-
-interface Alerta {
-  cliente_id: string
-  nome_cliente: string
-  motivo: string
-  itens_faltantes?: string[]
-}
+import { alertasHoje, listarClientes, type Alerta, type Cliente } from '../api/gerado'
 
 export default function Home() {
   const [clientes, setClientes] = useState<Cliente[]>([])
   const [alertas, setAlertas] = useState<Alerta[]>([])
 
   useEffect(() => {
-    api.get('/clientes').then((res) => {
-      const normalized = res.data.map((c: any) => ({
+    listarClientes().then((dados) => {
+      const normalized = dados.map((c) => ({
         ...c,
         itens: Array.isArray(c.itens) ? c.itens : [],
         dias_compra: Array.isArray(c.dias_compra) ? c.dias_compra : [],
       }))
       setClientes(normalized)
     })
-    api.get('/alertas/hoje').then((res) => setAlertas(Array.isArray(res.data) ? res.data : []))
+    alertasHoje().then((dados) => setAlertas(dados ?? []))
   }, [])
 
   return (
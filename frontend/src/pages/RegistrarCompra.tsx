@@ -1,21 +1,7 @@
 import React, { useEffect, useState } from 'react'
 import { useNavigate } from 'react-router-dom'
-import axios from 'axios'
-
-const api = axios.create({
-  baseURL: import.meta.env.VITE_API_URL,
-})
-
-interface Item {
-  id: string
-  nome: string
-}
-
-interface Cliente {
-  id: string
-  nome: string
-  itens: Item[]
-}
+import { criarCompra, listarClientes, type Cliente } from '../api/gerado'
+import { problemaDe } from '../api/erros'
 
 export default function RegistrarCompra() {
   const navigate = useNavigate()
@@ -23,12 +9,11 @@ export default function RegistrarCompra() {
   const [clienteSelecionado, setClienteSelecionado] = useState<Cliente | null>(null)
   const [itensSelecionados, setItensSelecionados] = useState<string[]>([])
   const [precos, setPrecos] = useState<Record<string, number>>({})
+  const [quantidades, setQuantidades] = useState<Record<string, number>>({})
   const [dataCompra, setDataCompra] = useState(() => new Date().toISOString().split('T')[0])
 
   useEffect(() => {
-    api.get('/clientes').then((res) => {
-      setClientes(res.data)
-    })
+    listarClientes().then(setClientes)
   }, [])
 
   const handleClienteChange = (id: string) => {
@@ -36,6 +21,7 @@ export default function RegistrarCompra() {
     setClienteSelecionado(cliente)
     setItensSelecionados([])
     setPrecos({})
+    setQuantidades({})
   }
 
   const toggleItem = (itemId: string) => {
@@ -48,6 +34,10 @@ export default function RegistrarCompra() {
     setPrecos(prev => ({ ...prev, [itemId]: preco }))
   }
 
+  const handleQuantidadeChange = (itemId: string, quantidade: number) => {
+    setQuantidades(prev => ({ ...prev, [itemId]: quantidade }))
+  }
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
 
@@ -60,6 +50,7 @@ export default function RegistrarCompra() {
       cliente_id: clienteSelecionado.id,
       itens: itensSelecionados.map(id => ({
         item_id: id,
+        quantidade: quantidades[id] || undefined,
         preco: precos[id] ?? 0,
       })),
       data: dataCompra,
@@ -67,11 +58,11 @@ export default function RegistrarCompra() {
 
     try {
       console.log('Payload enviado:', payload)
-      await api.post('/compras', payload)
+      await criarCompra(payload)
       navigate('/')
     } catch (err) {
       console.error(err)
-      alert("Erro ao registrar compra.")
+      alert(problemaDe(err)?.detail ?? "Erro ao registrar compra.")
     }
   }
 
@@ -111,7 +102,18 @@ export default function RegistrarCompra() {
                         {itensSelecionados.includes(item.id) && (
                             <input
                                 type="number"
-                                placeholder="Preço"
+                                placeholder="Qtd."
+                                min="0"
+                                step="any"
+                                value={quantidades[item.id] ?? ""}
+                                onChange={(e) => handleQuantidadeChange(item.id, parseFloat(e.target.value))}
+                                className="w-20 p-1 border rounded"
+                            />
+                        )}
+                        {itensSelecionados.includes(item.id) && (
+                            <input
+                                type="number"
+                                placeholder="Valor total"
                                 min="0"
                                 step="0.01"
                                 value={precos[item.id] ?? ""}
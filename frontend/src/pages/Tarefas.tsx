@@ -0,0 +1,106 @@
+import { useCallback, useEffect, useState } from "react";
+import { Link } from "react-router-dom";
+import { atualizarTarefa, listarTarefas, type StatusTarefa, type Tarefa } from "../api/gerado";
+import { problemaDe } from "../api/erros";
+
+const situacoes: Record<StatusTarefa, string> = {
+    aberta: 'Aberta',
+    em_andamento: 'Em andamento',
+    concluida: 'Concluída',
+    cancelada: 'Cancelada',
+}
+
+export default function Tarefas() {
+    const [tarefas, setTarefas] = useState<Tarefa[]>([])
+    const [responsavel, setResponsavel] = useState('')
+    const [status, setStatus] = useState('aberta,em_andamento')
+    const [erro, setErro] = useState<string | null>(null)
+
+    const carregar = useCallback(() => {
+        listarTarefas({ responsavel: responsavel || undefined, status: status || undefined })
+            .then(setTarefas)
+            .catch(err => setErro(problemaDe(err)?.detail ?? 'Erro ao carregar tarefas'))
+    }, [responsavel, status])
+
+    useEffect(() => {
+        carregar()
+    }, [carregar])
+
+    const mudarStatus = async (t: Tarefa, novo: StatusTarefa) => {
+        setErro(null)
+        try {
+            await atualizarTarefa(t.id, {
+                titulo: t.titulo,
+                descricao: t.descricao,
+                responsavel: t.responsavel,
+                vencimento: t.vencimento.slice(0, 10),
+                status: novo,
+            })
+            carregar()
+        } catch (err) {
+            setErro(problemaDe(err)?.detail ?? 'Erro ao atualizar tarefa')
+        }
+    }
+
+    const vencida = (t: Tarefa) =>
+        (t.status === 'aberta' || t.status === 'em_andamento') && t.vencimento.slice(0, 10) < new Date().toISOString().slice(0, 10)
+
+    return (
+        <div className="max-w-4xl mx-auto">
+            <h2 className="text-2xl font-semibold mb-4">✅ Tarefas</h2>
+
+            <div className="flex gap-2 mb-4 text-sm">
+                <input
+                    className="p-2 border rounded"
+                    placeholder="Responsável"
+                    value={responsavel}
+                    onChange={e => setResponsavel(e.target.value)}
+                />
+                <select className="p-2 border rounded" value={status} onChange={e => setStatus(e.target.value)}>
+                    <option value="aberta,em_andamento">Pendentes</option>
+                    <option value="concluida">Concluídas</option>
+                    <option value="cancelada">Canceladas</option>
+                    <option value="">Todas</option>
+                </select>
+            </div>
+
+            {erro && <p className="text-red-600 mb-4">{erro}</p>}
+
+            {tarefas.length === 0 ? (
+                <p className="text-sm text-gray-500">Nenhuma tarefa encontrada.</p>
+            ) : (
+                <ul className="space-y-2">
+                    {tarefas.map(t => (
+                        <li
+                            key={t.id}
+                            className={`border-l-4 p-4 rounded ${vencida(t) ? "bg-red-50 border-red-500" : "bg-white border-blue-400"}`}
+                        >
+                            <div className="flex justify-between items-start">
+                                <div>
+                                    <p className="font-semibold">{t.titulo}</p>
+                                    <p className="text-sm text-gray-600">
+                                        <Link to={`/clientes/${t.cliente_id}/historico`} className="text-blue-600 hover:underline">
+                                            {t.nome_cliente}
+                                        </Link>
+                                        {t.responsavel && ` · ${t.responsavel}`}
+                                        {` · vence em ${new Date(t.vencimento).toLocaleDateString('pt-BR', { timeZone: 'UTC' })}`}
+                                    </p>
+                                    {t.descricao && <p className="text-sm mt-1">{t.descricao}</p>}
+                                </div>
+                                <select
+                                    className="p-1 border rounded text-sm"
+                                    value={t.status}
+                                    onChange={e => mudarStatus(t, e.target.value as StatusTarefa)}
+                                >
+                                    {Object.entries(situacoes).map(([valor, rotulo]) => (
+                                        <option key={valor} value={valor}>{rotulo}</option>
+                                    ))}
+                                </select>
+                            </div>
+                        </li>
+                    ))}
+                </ul>
+            )}
+        </div>
+    )
+}